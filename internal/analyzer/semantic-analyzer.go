@@ -1,15 +1,21 @@
 package analyzer
 
 import (
+	"cmp"
 	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
 
 	"kstmc.com/gosha/internal/ast"
 	"kstmc.com/gosha/internal/object"
 	"kstmc.com/gosha/internal/parser"
+	"kstmc.com/gosha/internal/token"
 )
 
 func AnalyzeProgram(node *ast.Program, env *object.Environment) []string {
-	env = object.NewEnclosedEnvironment(env)
+	env = object.NewSameScopeEnvironment(env)
 	var errors []string
 	for _, stmt := range node.Statements {
 		errors = append(errors, AnalyzeStatement(stmt, parser.ANY, env)...)
@@ -38,8 +44,13 @@ func AnalyzeStatement(stmt ast.Statement, returnType ast.DataType, env *object.E
 		return analyzeSendChanStatement(stmt, env)
 	case *ast.ReturnStatement:
 		return analyzeReturnStatement(stmt, returnType, env)
-	case *ast.BreakStatement:
+	case *ast.BreakStatement, *ast.ContinueStatement, *ast.FallthroughStatement:
+		return nil
+	case *ast.PackageStatement, *ast.ImportStatement:
+		// Imported packages are checked when they are loaded.
 		return nil
+	case *ast.LabeledStatement:
+		return AnalyzeStatement(stmt.Statement, returnType, env)
 	case *ast.AssignStatement:
 		return analyzeAssignStatement(stmt, env)
 	case *ast.IfStatement:
@@ -47,12 +58,27 @@ func AnalyzeStatement(stmt ast.Statement, returnType ast.DataType, env *object.E
 	case *ast.GoStatement:
 		_, errors := AnalyzeExpression(stmt.Expr, env)
 		return errors
+	case *ast.DeferStatement:
+		_, errors := AnalyzeExpression(stmt.Call, env)
+		return errors
 	case *ast.VarStatement:
 		return analyzeVarStatement(stmt, env)
+	case *ast.ConstStatement:
+		return analyzeConstStatement(stmt, env)
 	case *ast.ForStatement:
 		return analyzeForStatement(stmt, returnType, env)
+	case *ast.ForRangeStatement:
+		return analyzeForRangeStatement(stmt, returnType, env)
 	case *ast.InitAssignStatement:
 		return analyzeInitAssignStatement(stmt, env)
+	case *ast.TypeStatement:
+		return analyzeTypeStatement(stmt, env)
+	case *ast.TypeSwitchStatement:
+		return analyzeTypeSwitchStatement(stmt, returnType, env)
+	case *ast.SwitchStatement:
+		return analyzeSwitchStatement(stmt, returnType, env)
+	case *ast.SelectStatement:
+		return analyzeSelectStatement(stmt, returnType, env)
 	default:
 		return []string{fmt.Sprintf("Analyzer error. Unsupported statement %T", stmt)}
 	}
@@ -74,7 +100,12 @@ func analyzeSendChanStatement(stmt *ast.SendChanStatement, env *object.Environme
 		return errors
 	}
 
-	if exprType.Name() == parser.ANY.Name() || chn.ChanType.Name() == exprType.Name() {
+	exprType, errors = constantType(chn.ChanType, exprType, stmt.Source, env)
+	if len(errors) != 0 {
+		return errors
+	}
+
+	if isAssignable(chn.ChanType, exprType, env) {
 		return nil
 	} else {
 		return []string{fmt.Sprintf("Analyzer error. Expression type and chan type mismatch. Chan type %T, expression type %T", chn.ChanType, exprType)}
@@ -82,334 +113,3365 @@ func analyzeSendChanStatement(stmt *ast.SendChanStatement, env *object.Environme
 }
 
 func analyzeForStatement(stmt *ast.ForStatement, returnType ast.DataType, env *object.Environment) []string {
-	exprType, errors := AnalyzeExpression(stmt.Condition, env)
+	env = object.NewEnclosedEnvironment(env)
+
+	if stmt.Init != nil {
+		if errors := AnalyzeStatement(stmt.Init, returnType, env); len(errors) != 0 {
+			return errors
+		}
+	}
+
+	if stmt.Condition != nil {
+		exprType, errors := AnalyzeExpression(stmt.Condition, env)
+		if len(errors) != 0 {
+			return errors
+		}
+
+		if exprType.Name() != parser.BOOLEAN.Name() {
+			msg := fmt.Sprintf("Analyzer error. Expected boolean type for condition, got=%s", exprType.Name())
+			return []string{msg}
+		}
+	}
+
+	if stmt.Post != nil {
+		if errors := AnalyzeStatement(stmt.Post, returnType, env); len(errors) != 0 {
+			return errors
+		}
+	}
+
+	return analyzeBlockStatement(stmt.Consequence, returnType, env)
+}
+
+func analyzeForRangeStatement(stmt *ast.ForRangeStatement, returnType ast.DataType, env *object.Environment) []string {
+	iterableType, errors := analyzeSingleValue(stmt.Iterable, env)
 	if len(errors) != 0 {
 		return errors
 	}
 
-	if exprType.Name() != parser.BOOLEAN.Name() {
-		msg := fmt.Sprintf("Analyzer error. Expected boolean type for condition, got=%s", exprType.Name())
+	var keyType, valueType ast.DataType
+	switch dType := ast.Underlying(iterableType).(type) {
+	case *ast.SliceDataType:
+		keyType, valueType = parser.INT, dType.Type
+	case *ast.MapDataType:
+		keyType, valueType = dType.KeyType, dType.ValueType
+	case *ast.StringDataType:
+		keyType, valueType = parser.INT, parser.RUNE
+	case *ast.ChanDataType:
+		keyType = dType.ValueType
+	case *ast.IntegerDataType:
+		keyType = parser.INT
+	case *ast.AnyDataType:
+		keyType, valueType = parser.ANY, parser.ANY
+	default:
+		msg := fmt.Sprintf("Analyzer error. cannot range over %s (type %s)", stmt.Iterable.String(), iterableType.Name())
 		return []string{msg}
 	}
 
-	errors = analyzeBlockStatement(stmt.Consequence, returnType, env)
-	return errors
+	if stmt.Value != nil && valueType == nil {
+		msg := fmt.Sprintf("Analyzer error. range over %s permits only one iteration variable", stmt.Iterable.String())
+		return []string{msg}
+	}
+
+	env = object.NewEnclosedEnvironment(env)
+	if stmt.Key != nil && stmt.Key.Value != "_" {
+		env.Set(stmt.Key.Value, NativeTypeToDefaultObj(keyType))
+	}
+
+	if stmt.Value != nil && stmt.Value.Value != "_" {
+		env.Set(stmt.Value.Value, NativeTypeToDefaultObj(valueType))
+	}
+
+	return analyzeBlockStatement(stmt.Body, returnType, env)
 }
 
 func analyzeAssignStatement(stmt *ast.AssignStatement, env *object.Environment) []string {
+	var types []ast.DataType
 	var errors []string
-	if !env.Contains(stmt.Name.Value) {
-		msg := fmt.Sprintf("Analyzer error. Unknown identifier %s", stmt.Name.Value)
-		errors = append(errors, msg)
+	if len(stmt.Targets) == 1 {
+		var dType ast.DataType
+		dType, errors = analyzeSingleValue(stmt.Value, env)
+		types = []ast.DataType{dType}
+	} else {
+		types, errors = analyzeAssignedValues(stmt.Value, len(stmt.Targets), env)
+	}
+
+	if len(errors) != 0 {
 		return errors
 	}
 
-	ident, _ := env.Get(stmt.Name.Value)
+	values := []ast.Expression{stmt.Value}
+	if tuple, ok := stmt.Value.(*ast.TupleExpression); ok {
+		values = tuple.Values
+	}
+
+	for i, target := range stmt.Targets {
+		if ident, ok := target.(*ast.Identifier); ok && ident.Value == "_" {
+			continue
+		}
+
+		targetType, targetErrors := analyzeAssignTarget(target, env)
+		if len(targetErrors) != 0 {
+			errors = append(errors, targetErrors...)
+			continue
+		}
+
+		valueType := types[i]
+		if len(values) == len(stmt.Targets) {
+			var constErrors []string
+			valueType, constErrors = constantType(targetType, valueType, values[i], env)
+			if len(constErrors) != 0 {
+				errors = append(errors, constErrors...)
+				continue
+			}
+		}
+
+		if !isAssignable(targetType, valueType, env) {
+			errors = append(errors, typeMismatchError(targetType, valueType, env))
+		}
+	}
+
+	return errors
+}
+
+// analyzeAssignTarget returns the type of the location target denotes. Only variables,
+// slice and map elements, struct fields and pointer indirections are assignable.
+func analyzeAssignTarget(target ast.Expression, env *object.Environment) (ast.DataType, []string) {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		obj, ok := env.Get(target.Value)
+		if !ok {
+			return nil, []string{fmt.Sprintf("Analyzer error. Unknown identifier %s", target.Value)}
+		}
+
+		if constant, ok := obj.(*object.Constant); ok {
+			return nil, []string{fmt.Sprintf("Analyzer error. cannot assign to %s (constant %s)", target.Value, constant.Inspect())}
+		}
+
+		return obj.Type(), nil
+	case *ast.IndexExpression:
+		// Strings are immutable.
+		if lType, _ := AnalyzeExpression(target.Left, env); lType == parser.STRING {
+			break
+		}
+
+		return AnalyzeExpression(target, env)
+	case *ast.SelectorExpression:
+		lType, errors := AnalyzeExpression(target.Left, env)
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		if pkgType, ok := lType.(*ast.PackageDataType); ok {
+			return analyzePackageVarTarget(target, pkgType, env)
+		}
+
+		if !ast.IsExported(target.Field.Value) && isForeignType(lType, env) {
+			msg := fmt.Sprintf("Analyzer error. %s undefined (cannot refer to unexported field %s)", target.String(), target.Field.Value)
+			return nil, []string{msg}
+		}
+
+		fieldType, ok := structFieldType(lType, target.Field.Value)
+		if !ok {
+			msg := fmt.Sprintf("Analyzer error. %s undefined (type %s has no field %s)", target.String(), lType.Name(), target.Field.Value)
+			return nil, []string{msg}
+		}
+
+		// Fields of map elements and of call results are not variables.
+		if !isAddressable(target, env) {
+			if index, ok := target.Left.(*ast.IndexExpression); ok {
+				if mapType, _ := AnalyzeExpression(index.Left, env); isMapType(mapType) {
+					return nil, []string{fmt.Sprintf("Analyzer error. cannot assign to struct field %s in map", target.String())}
+				}
+			}
+
+			break
+		}
+
+		return fieldType, nil
+	case *ast.PrefixExpression:
+		if target.Operator == "*" {
+			return AnalyzeExpression(target, env)
+		}
+	}
+
+	msg := fmt.Sprintf("Analyzer error. cannot assign to %s (neither addressable nor a map index expression)", target.String())
+	return nil, []string{msg}
+}
+
+// analyzePackageVarTarget returns the type of the variable of an imported package that
+// target denotes. Constants, types and the members of predeclared packages cannot be
+// assigned.
+func analyzePackageVarTarget(target *ast.SelectorExpression, pkgType *ast.PackageDataType, env *object.Environment) (ast.DataType, []string) {
+	dType, errors := analyzeSelectorExpression(target, env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	pkg := packageObject(target.Left, pkgType, env)
+	member, _ := pkg.Member(target.Field.Value)
+	switch member.(type) {
+	case *object.Constant, *object.DataTypeObject:
+	default:
+		if pkg.Env != nil {
+			return dType, nil
+		}
+	}
+
+	return nil, []string{fmt.Sprintf("Analyzer error. cannot assign to %s (neither addressable nor a map index expression)", target.String())}
+}
+
+func analyzeTypeStatement(stmt *ast.TypeStatement, env *object.Environment) []string {
+	env.Set(stmt.Name.Value, &object.DataTypeObject{DataType: stmt.Type})
 
-	exprType, errors := AnalyzeExpression(stmt.Value, env)
+	typeEnv, errors := typeParameterEnvironment(stmt.Type.TypeParams, env)
 	if len(errors) != 0 {
 		return errors
 	}
 
-	if !env.Contains(stmt.Name.Value) {
-		msg := fmt.Sprintf("Analyzer error. Unknown identifier %s", stmt.Name.Value)
-		errors = append(errors, msg)
+	return resolveConstraint(stmt.Type.Underlying, typeEnv)
+}
+
+// typeParameterEnvironment returns an environment in which the type parameters params
+// of a generic declaration are visible.
+func typeParameterEnvironment(params []*ast.TypeParameterDataType, env *object.Environment) (*object.Environment, []string) {
+	if len(params) == 0 {
+		return env, nil
+	}
+
+	env = object.NewEnclosedEnvironment(env)
+	for _, param := range params {
+		env.Set(param.TypeName, &object.DataTypeObject{DataType: param})
+	}
+
+	var errors []string
+	for _, param := range params {
+		errors = append(errors, resolveConstraint(param.Constraint, env)...)
+	}
+
+	return env, errors
+}
+
+// resolveDataType links references to named types with their declarations. Interfaces
+// that restrict their type set are only constraints, values cannot be of their type.
+func resolveDataType(dType ast.DataType, env *object.Environment) []string {
+	if errors := resolveConstraint(dType, env); len(errors) != 0 {
 		return errors
 	}
 
-	if ident.Type() != parser.ANY && exprType != parser.ANY && ident.Type().Name() != exprType.Name() {
-		msg := fmt.Sprintf("Analyzer error. type mismatch. expected %s, got %s", ident.Type().Name(), exprType.Name())
-		errors = append(errors, msg)
+	if iface, ok := ast.Underlying(dType).(*ast.InterfaceDataType); ok && (len(iface.Union) != 0 || iface.Comparable) {
+		msg := fmt.Sprintf("Analyzer error. cannot use type %s outside a type constraint: interface contains type constraints", dType.Name())
+		return []string{msg}
+	}
+
+	return nil
+}
+
+// resolveConstraint links references to named types like resolveDataType, but dType
+// may be a constraint interface, as in a type parameter list or the declaration of
+// such an interface.
+func resolveConstraint(dType ast.DataType, env *object.Environment) []string {
+	switch dType := dType.(type) {
+	case *ast.NamedDataType:
+		if dType.Underlying != nil {
+			return nil
+		}
+
+		obj, ok := env.Get(dType.TypeName)
+		if dType.Package != "" {
+			obj, ok = packageMember(dType.Package, dType.TypeName, env)
+		}
+
+		if !ok {
+			return []string{fmt.Sprintf("Analyzer error. unknown type %s", dType.Name())}
+		}
+
+		typeObj, ok := obj.(*object.DataTypeObject)
+		if !ok {
+			return []string{fmt.Sprintf("Analyzer error. %s is not a type", dType.Name())}
+		}
+
+		// A type parameter is referred to like an alias of it.
+		if param, ok := typeObj.DataType.(*ast.TypeParameterDataType); ok && len(dType.TypeArgs) == 0 {
+			dType.Underlying = param
+			dType.Alias = true
+			return nil
+		}
+
+		named, ok := typeObj.DataType.(*ast.NamedDataType)
+		if !ok {
+			return []string{fmt.Sprintf("Analyzer error. %s is not a type", dType.TypeName)}
+		}
+
+		// References to types of imported packages are qualified with the name the
+		// package declares, not the name it is imported as.
+		dType.Package = named.Package
+		dType.Path = named.Path
+		dType.Decl = named
+		if named.Decl != nil {
+			dType.Decl = named.Decl
+		}
+
+		if len(named.TypeParams) != 0 || len(dType.TypeArgs) != 0 {
+			return resolveInstance(dType, named, env)
+		}
+
+		dType.Underlying = named.Underlying
+		dType.Alias = named.Alias
+		return nil
+	case *ast.SliceDataType:
+		return resolveDataType(dType.Type, env)
+	case *ast.MapDataType:
+		return append(resolveDataType(dType.KeyType, env), resolveDataType(dType.ValueType, env)...)
+	case *ast.ChanDataType:
+		return resolveDataType(dType.ValueType, env)
+	case *ast.ReferenceDataType:
+		return resolveDataType(dType.ValueType, env)
+	case *ast.TupleDataType:
+		var errors []string
+		for _, dType := range dType.Types {
+			errors = append(errors, resolveDataType(dType, env)...)
+		}
+
+		return errors
+	case *ast.FunctionDataType:
+		var errors []string
+		for _, param := range dType.Parameters {
+			errors = append(errors, resolveDataType(param, env)...)
+		}
+
+		return append(errors, resolveDataType(dType.ReturnType, env)...)
+	case *ast.StructDataType:
+		var errors []string
+		for _, field := range dType.Fields {
+			errors = append(errors, resolveDataType(field.Type, env)...)
+		}
+
+		return errors
+	case *ast.InterfaceDataType:
+		var errors []string
+		for _, method := range dType.Methods {
+			errors = append(errors, resolveDataType(method.Type, env)...)
+		}
+
+		for _, term := range dType.Union {
+			errors = append(errors, resolveConstraint(term.Type, env)...)
+		}
+
+		for _, embedded := range dType.Embeds {
+			errors = append(errors, resolveConstraint(embedded, env)...)
+		}
+
+		if len(errors) != 0 {
+			return errors
+		}
+
+		return embedInterfaces(dType)
+	default:
+		return nil
 	}
+}
+
+// embedInterfaces merges the interfaces iface embeds into it. Other embedded types, e.g.
+// MyInt in interface{ MyInt }, restrict its type set.
+func embedInterfaces(iface *ast.InterfaceDataType) []string {
+	var errors []string
+	for _, embedded := range iface.Embeds {
+		switch other := ast.Underlying(embedded).(type) {
+		case *ast.InterfaceDataType:
+			for _, method := range other.Methods {
+				existing, ok := iface.Method(method.Name)
+				if !ok {
+					iface.Methods = append(iface.Methods, method)
+				} else if existing.Type.Name() != method.Type.Name() {
+					errors = append(errors, fmt.Sprintf("Analyzer error. duplicate method %s", method.Name))
+				}
+			}
 
+			iface.Union = append(iface.Union, other.Union...)
+			iface.Comparable = iface.Comparable || other.Comparable
+		case *ast.AnyDataType:
+		default:
+			iface.Union = append(iface.Union, &ast.TypeTerm{Type: embedded})
+		}
+	}
+
+	iface.Embeds = nil
 	return errors
 }
 
+// resolveInstance links the reference G[A, B] to the instance of the generic type
+// declared as decl.
+func resolveInstance(dType, decl *ast.NamedDataType, env *object.Environment) []string {
+	switch {
+	case len(decl.TypeParams) == 0:
+		return []string{fmt.Sprintf("Analyzer error. %s is not a generic type", dType.TypeName)}
+	case len(dType.TypeArgs) == 0:
+		return []string{fmt.Sprintf("Analyzer error. cannot use generic type %s without instantiation", dType.TypeName)}
+	case len(dType.TypeArgs) != len(decl.TypeParams):
+		msg := fmt.Sprintf("Analyzer error. wrong number of type arguments for %s. expected %d, got %d", dType.TypeName, len(decl.TypeParams), len(dType.TypeArgs))
+		return []string{msg}
+	}
+
+	var errors []string
+	for _, arg := range dType.TypeArgs {
+		errors = append(errors, resolveDataType(arg, env)...)
+	}
+
+	if len(errors) != 0 {
+		return errors
+	}
+
+	if errors := checkTypeArguments(decl.TypeParams, dType.TypeArgs, env); len(errors) != 0 {
+		return errors
+	}
+
+	dType.Underlying = ast.Instantiate(decl, dType.TypeArgs).Underlying
+	dType.Origin = decl
+	return nil
+}
+
+// analyzeInitAssignStatement declares the new variables on the left side of ':='.
+// Variables that already exist are assigned, but at least one of them must be new.
 func analyzeInitAssignStatement(stmt *ast.InitAssignStatement, env *object.Environment) []string {
-	dType, errors := AnalyzeExpression(stmt.Value, env)
-	env.Set(stmt.Name.Value, NativeTypeToDefaultObj(dType))
+	if name, ok := ast.RepeatedName(stmt.Names); ok {
+		return []string{fmt.Sprintf("Analyzer error. %s repeated on left side of :=", name)}
+	}
+
+	types, errors := analyzeAssignedValues(stmt.Value, len(stmt.Names), env)
+	if len(errors) != 0 {
+		return errors
+	}
+
+	values := []ast.Expression{stmt.Value}
+	if tuple, ok := stmt.Value.(*ast.TupleExpression); ok {
+		values = tuple.Values
+	}
+
+	declared := 0
+	for i, name := range stmt.Names {
+		if name.Value == "_" {
+			continue
+		}
+
+		obj, exists := env.GetLocal(name.Value)
+		targetType := types[i]
+		if exists {
+			targetType = obj.Type()
+		}
+
+		valueType := types[i]
+		if len(values) == len(stmt.Names) {
+			var constErrors []string
+			valueType, constErrors = constantType(targetType, valueType, values[i], env)
+			if len(constErrors) != 0 {
+				errors = append(errors, constErrors...)
+				continue
+			}
+		}
+
+		if exists {
+			if !isAssignable(targetType, valueType, env) {
+				errors = append(errors, typeMismatchError(targetType, valueType, env))
+			}
+
+			continue
+		}
+
+		env.Set(name.Value, NativeTypeToDefaultObj(types[i]))
+		declared++
+	}
+
+	if declared == 0 && len(errors) == 0 {
+		errors = append(errors, "Analyzer error. no new variables on left side of :=")
+	}
+
 	return errors
 }
 
+// analyzeAssignedValues returns the types of the values assigned to count variables.
+func analyzeAssignedValues(expr ast.Expression, count int, env *object.Environment) ([]ast.DataType, []string) {
+	// out, err := $(cmd) reports a failed command as an error value.
+	if _, ok := expr.(*ast.BashExpression); ok && count == 2 {
+		return []ast.DataType{parser.STRING, parser.ERROR_INTERFACE}, nil
+	}
+
+	if count == 2 {
+		dType, ok, errors := analyzeCommaOkExpression(expr, env)
+		if ok || len(errors) != 0 {
+			return []ast.DataType{dType, parser.BOOLEAN}, errors
+		}
+	}
+
+	dType, errors := AnalyzeExpression(expr, env)
+	if len(errors) == 0 {
+		errors = checkInstantiated(expr, dType)
+	}
+
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	types := []ast.DataType{dType}
+	if tupleType, ok := dType.(*ast.TupleDataType); ok {
+		types = tupleType.Types
+	}
+
+	if len(types) == count {
+		return types, nil
+	}
+
+	if _, ok := expr.(*ast.CallExpression); ok {
+		msg := fmt.Sprintf("Analyzer error. assignment mismatch: %d variables but %s returns %d values", count, expr.String(), len(types))
+		return nil, []string{msg}
+	}
+
+	msg := fmt.Sprintf("Analyzer error. assignment mismatch: %d variables but %d values", count, len(types))
+	return nil, []string{msg}
+}
+
+// analyzeCommaOkExpression checks expressions that may be used in the 'v, ok' form.
+// It reports false if expr does not support that form.
+func analyzeCommaOkExpression(expr ast.Expression, env *object.Environment) (ast.DataType, bool, []string) {
+	if expr, ok := expr.(*ast.TypeAssertionExpression); ok {
+		dType, errors := analyzeTypeAssertionExpression(expr, env)
+		return dType, true, errors
+	}
+
+	if expr, ok := expr.(*ast.ReadChanExpression); ok {
+		dType, errors := AnalyzeExpression(expr, env)
+		return dType, true, errors
+	}
+
+	if expr, ok := expr.(*ast.IndexExpression); ok {
+		lType, errors := AnalyzeExpression(expr.Left, env)
+		if len(errors) != 0 {
+			return nil, false, errors
+		}
+
+		if _, ok := ast.Underlying(lType).(*ast.MapDataType); ok {
+			dType, errors := AnalyzeExpression(expr, env)
+			return dType, true, errors
+		}
+	}
+
+	return nil, false, nil
+}
+
 func analyzeVarStatement(stmt *ast.VarStatement, env *object.Environment) []string {
+	if stmt.Name.DataType != nil {
+		if errors := resolveDataType(*stmt.Name.DataType, env); len(errors) != 0 {
+			return errors
+		}
+
+		if mapType, ok := (*stmt.Name.DataType).(*ast.MapDataType); ok {
+			if errors := analyzeMapDataType(mapType); len(errors) != 0 {
+				return errors
+			}
+		}
+	}
+
 	if stmt.Value == nil {
 		env.Set(stmt.Name.Value, NativeTypeToDefaultObj(*stmt.Name.DataType))
 		return nil
 	}
 
 	var identType ast.DataType
-	exprType, errors := AnalyzeExpression(stmt.Value, env)
+	exprType, errors := analyzeSingleValue(stmt.Value, env)
+	if len(errors) != 0 {
+		return errors
+	}
+
+	if stmt.Name.DataType != nil {
+		identType = *stmt.Name.DataType
+	} else {
+		identType = exprType
+	}
+
+	exprType, errors = constantType(identType, exprType, stmt.Value, env)
 	if len(errors) != 0 {
 		return errors
 	}
 
-	if stmt.Name.DataType != nil {
-		identType = *stmt.Name.DataType
-	} else {
-		identType = exprType
-	}
+	if !isAssignable(identType, exprType, env) {
+		errors = append(errors, typeMismatchError(identType, exprType, env))
+	}
+
+	env.Set(stmt.Name.Value, NativeTypeToDefaultObj(identType))
+
+	return errors
+}
+
+func analyzeReturnStatement(stmt *ast.ReturnStatement, returnType ast.DataType, env *object.Environment) []string {
+	var stmtReturnType ast.DataType = parser.NIL
+	var errors []string
+	if stmt.ReturnValue != nil {
+		stmtReturnType, errors = AnalyzeExpression(stmt.ReturnValue, env)
+		if len(errors) != 0 {
+			return errors
+		}
+	}
+
+	have := resultCount(stmtReturnType)
+	if _, ok := stmt.ReturnValue.(*ast.NilLiteral); ok {
+		have = 1
+	}
+
+	if want := resultCount(returnType); returnType != parser.ANY && have != want {
+		problem := "not enough"
+		if have > want {
+			problem = "too many"
+		}
+
+		msg := fmt.Sprintf("analyzer error. %s return values. have %s, want %s", problem, stmtReturnType.Name(), returnType.Name())
+		return []string{msg}
+	}
+
+	stmtReturnType, errors = constantType(returnType, stmtReturnType, stmt.ReturnValue, env)
+	if len(errors) != 0 {
+		return errors
+	}
+
+	if !isAssignable(returnType, stmtReturnType, env) {
+		msg := fmt.Sprintf("analyzer error. function returns %s, got=%s", returnType.Name(), stmtReturnType.Name())
+		errors = append(errors, msg)
+	}
+
+	return errors
+}
+
+// resultCount returns the number of values described by a result type.
+func resultCount(dType ast.DataType) int {
+	switch dType := dType.(type) {
+	case *ast.TupleDataType:
+		return len(dType.Types)
+	case *ast.NilDataType:
+		return 0
+	default:
+		return 1
+	}
+}
+
+func analyzeExpressionStatement(expr *ast.ExpressionStatement, env *object.Environment) []string {
+	dType, errors := AnalyzeExpression(expr.Expression, env)
+	if len(errors) != 0 || !isUntypedConstant(expr.Expression, env) {
+		return errors
+	}
+
+	return convertConstant(expr.Expression, dType, env)
+}
+
+func AnalyzeExpression(expr ast.Expression, env *object.Environment) (ast.DataType, []string) {
+	var errors []string
+	switch expr := expr.(type) {
+	case *ast.IntegerLiteral:
+		return parser.INT, errors
+	case *ast.RuneLiteral:
+		return parser.RUNE, errors
+	case *ast.FloatLiteral:
+		return parser.FLOAT64, errors
+	case *ast.Boolean:
+		return parser.BOOLEAN, errors
+	case *ast.BashExpression:
+		return parser.STRING, errors
+	case *ast.InterpolatedString:
+		return analyzeInterpolatedString(expr, env)
+	case *ast.Identifier:
+		obj, ok := env.Get(expr.Value)
+		if ok {
+			return obj.Type(), nil
+		}
+
+		fnObj, ok := object.Builtins[expr.Value]
+		if ok {
+			return fnObj.Type(), nil
+		}
+
+		pkg, ok := object.Packages[expr.Value]
+		if ok {
+			return pkg.Type(), nil
+		}
+
+		msg := fmt.Sprintf("analyzer error. unknown identifier %s", expr.Value)
+		errors = append(errors, msg)
+		return nil, errors
+	case *ast.CallExpression:
+		return analyzeCallExpression(expr, env)
+	case *ast.IndexExpression:
+		return analyzeIndexExpression(expr, env)
+	case *ast.SliceExpression:
+		return analyzeSliceExpression(expr, env)
+	case *ast.ReadChanExpression:
+		sourceType, errors := analyzeSingleValue(expr.Source, env)
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		chanType, ok := ast.Underlying(sourceType).(*ast.ChanDataType)
+		if !ok {
+			msg := fmt.Sprintf("analyzer error. invalid operation: cannot receive from non-channel %s (type %s)", expr.Source.String(), sourceType.Name())
+			return nil, []string{msg}
+		}
+
+		return chanType.ValueType, nil
+	case *ast.PrefixExpression:
+		dType, errors := analyzePrefixExpression(expr, env)
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		return dType, checkConstantExpression(expr, env)
+	case *ast.StringLiteral:
+		return parser.STRING, errors
+	case *ast.InfixExpression:
+		dType, errors := analyzeInfixExpression(expr, env)
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		return dType, checkConstantExpression(expr, env)
+	case *ast.BashVarExpression:
+		return parser.STRING, errors
+	case *ast.FunctionLiteral:
+		return analyzeFunctionLiteral(expr, env)
+	case *ast.SliceLiteral:
+		return analyzeSliceLiteral(expr, env)
+	case *ast.MapLiteral:
+		return analyzeMapLiteral(expr, env)
+	case *ast.StructLiteral:
+		return analyzeStructLiteral(expr, env)
+	case *ast.SelectorExpression:
+		return analyzeSelectorExpression(expr, env)
+	case *ast.TypeAssertionExpression:
+		return analyzeTypeAssertionExpression(expr, env)
+	case *ast.NilLiteral:
+		return parser.NIL, nil
+	case *ast.TupleExpression:
+		return analyzeTupleExpression(expr, env)
+	case *ast.DataTypeExpression:
+		errors := resolveDataType(expr.Type, env)
+		return expr.Type, errors
+	case *ast.InstantiationExpression:
+		instance, _, errors := analyzeInstantiation(expr.Function, expr.TypeArgs, env)
+		return instance, errors
+	default:
+		msg := fmt.Sprintf("analyzer error. unexpected expression type %T", expr)
+		errors = append(errors, msg)
+		return nil, errors
+	}
+}
+
+func analyzeTupleExpression(expr *ast.TupleExpression, env *object.Environment) (ast.DataType, []string) {
+	tupleType := &ast.TupleDataType{}
+	for _, value := range expr.Values {
+		valueType, errors := analyzeSingleValue(value, env)
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		tupleType.Types = append(tupleType.Types, valueType)
+	}
+
+	return tupleType, nil
+}
+
+// analyzeInterpolatedString checks the expressions embedded in f"...", which may be
+// of any type with a single value.
+func analyzeInterpolatedString(expr *ast.InterpolatedString, env *object.Environment) (ast.DataType, []string) {
+	var errors []string
+	for _, part := range expr.Parts {
+		dType, partErrors := analyzeSingleValue(part, env)
+		if len(partErrors) != 0 {
+			errors = append(errors, partErrors...)
+			continue
+		}
+
+		if _, ok := part.(*ast.CallExpression); ok && dType == parser.NIL {
+			errors = append(errors, fmt.Sprintf("analyzer error. %s (no value) used as value", part.String()))
+		}
+	}
+
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	return parser.STRING, nil
+}
+
+// analyzeSingleValue analyzes an expression used where exactly one value is expected.
+func analyzeSingleValue(expr ast.Expression, env *object.Environment) (ast.DataType, []string) {
+	dType, errors := AnalyzeExpression(expr, env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	if _, ok := dType.(*ast.TupleDataType); ok {
+		msg := fmt.Sprintf("Analyzer error. multiple-value %s (value of type %s) in single-value context", expr.String(), dType.Name())
+		return nil, []string{msg}
+	}
+
+	if errors := checkInstantiated(expr, dType); len(errors) != 0 {
+		return nil, errors
+	}
+
+	return dType, nil
+}
+
+// checkInstantiated checks that a generic function is instantiated before it is used
+// as a value.
+func checkInstantiated(expr ast.Expression, dType ast.DataType) []string {
+	if fnType, ok := dType.(*ast.FunctionDataType); ok && len(fnType.TypeParams) != 0 {
+		return []string{fmt.Sprintf("Analyzer error. cannot use generic function %s without instantiation", expr.String())}
+	}
+
+	return nil
+}
+
+func analyzeIndexExpression(expr *ast.IndexExpression, env *object.Environment) (ast.DataType, []string) {
+	lType, errors := AnalyzeExpression(expr.Left, env)
+	if len(errors) > 0 {
+		return nil, errors
+	}
+
+	if mapType, ok := ast.Underlying(lType).(*ast.MapDataType); ok {
+		return analyzeMapIndexExpression(expr, mapType, env)
+	}
+
+	// f[T] instantiates a generic function f with the type argument T.
+	if fnType, ok := lType.(*ast.FunctionDataType); ok && len(fnType.TypeParams) != 0 {
+		instance, _, errors := analyzeInstantiation(expr.Left, []ast.Expression{expr.Index}, env)
+		return instance, errors
+	}
+
+	var elemType ast.DataType
+	switch lType := ast.Underlying(lType).(type) {
+	case *ast.SliceDataType:
+		elemType = lType.Type
+	case *ast.StringDataType:
+		elemType = parser.BYTE
+	default:
+		return nil, []string{fmt.Sprintf("Analyzer error. expected slice type for index expression, got=%T", lType)}
+	}
+
+	indexType, errors := AnalyzeExpression(expr.Index, env)
+	if len(errors) > 0 {
+		return nil, errors
+	}
+
+	if !isIntegerType(indexType) {
+		return nil, []string{fmt.Sprintf("Analyzer error. expected integer type for index expression, got=%T", lType)}
+	}
+
+	return elemType, nil
+}
+
+// analyzeSliceExpression checks a[low:high]. Slicing a string gives a string and
+// slicing a slice gives a slice of the same type.
+func analyzeSliceExpression(expr *ast.SliceExpression, env *object.Environment) (ast.DataType, []string) {
+	lType, errors := AnalyzeExpression(expr.Left, env)
+	if len(errors) > 0 {
+		return nil, errors
+	}
+
+	switch ast.Underlying(lType).(type) {
+	case *ast.StringDataType, *ast.SliceDataType:
+	default:
+		return nil, []string{fmt.Sprintf("Analyzer error. cannot slice %s (type %s)", expr.Left.String(), lType.Name())}
+	}
+
+	for _, bound := range []ast.Expression{expr.Low, expr.High} {
+		if bound == nil {
+			continue
+		}
+
+		boundType, errors := AnalyzeExpression(bound, env)
+		if len(errors) > 0 {
+			return nil, errors
+		}
+
+		if !isIntegerType(boundType) {
+			return nil, []string{fmt.Sprintf("Analyzer error. invalid slice index %s (type %s)", bound.String(), boundType.Name())}
+		}
+	}
+
+	return lType, nil
+}
+
+func analyzeMapIndexExpression(expr *ast.IndexExpression, mapType *ast.MapDataType, env *object.Environment) (ast.DataType, []string) {
+	keyType, errors := AnalyzeExpression(expr.Index, env)
+	if len(errors) > 0 {
+		return nil, errors
+	}
+
+	keyType, errors = constantType(mapType.KeyType, keyType, expr.Index, env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	if !isAssignable(mapType.KeyType, keyType, env) {
+		return nil, []string{fmt.Sprintf("Analyzer error. expected %s type for map key, got=%s", mapType.KeyType.Name(), keyType.Name())}
+	}
+
+	return mapType.ValueType, nil
+}
+
+func analyzeMapLiteral(expr *ast.MapLiteral, env *object.Environment) (ast.DataType, []string) {
+	errors := resolveDataType(expr.Type, env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	errors = analyzeMapDataType(expr.Type)
+	for _, pair := range expr.Pairs {
+		keyType, tempErrors := AnalyzeExpression(pair.Key, env)
+		if len(tempErrors) != 0 {
+			errors = append(errors, tempErrors...)
+			continue
+		}
+
+		keyType, tempErrors = constantType(expr.Type.KeyType, keyType, pair.Key, env)
+		if len(tempErrors) != 0 {
+			errors = append(errors, tempErrors...)
+			continue
+		}
+
+		if !isAssignable(expr.Type.KeyType, keyType, env) {
+			msg := fmt.Sprintf("Analyzer error. type mismatch in map literal key. expected %s, got %s", expr.Type.KeyType.Name(), keyType.Name())
+			errors = append(errors, msg)
+		}
+
+		valueType, tempErrors := AnalyzeExpression(pair.Value, env)
+		if len(tempErrors) != 0 {
+			errors = append(errors, tempErrors...)
+			continue
+		}
+
+		valueType, tempErrors = constantType(expr.Type.ValueType, valueType, pair.Value, env)
+		if len(tempErrors) != 0 {
+			errors = append(errors, tempErrors...)
+			continue
+		}
+
+		if !isAssignable(expr.Type.ValueType, valueType, env) {
+			msg := fmt.Sprintf("Analyzer error. type mismatch in map literal value. expected %s, got %s", expr.Type.ValueType.Name(), valueType.Name())
+			errors = append(errors, msg)
+		}
+	}
+
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	return expr.Type, nil
+}
+
+func analyzeMapDataType(mapType *ast.MapDataType) []string {
+	if !isComparableType(mapType.KeyType) {
+		return []string{fmt.Sprintf("Analyzer error. invalid map key type %s", mapType.KeyType.Name())}
+	}
+
+	return nil
+}
+
+func analyzeSliceLiteral(expr *ast.SliceLiteral, env *object.Environment) (ast.DataType, []string) {
+	errors := resolveDataType(expr.Type, env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	for _, value := range expr.Values {
+		valueType, tempErrors := AnalyzeExpression(value, env)
+		if len(tempErrors) != 0 {
+			errors = append(errors, tempErrors...)
+			continue
+		}
+
+		valueType, tempErrors = constantType(expr.Type, valueType, value, env)
+		if len(tempErrors) != 0 {
+			errors = append(errors, tempErrors...)
+			continue
+		}
+
+		if !isAssignable(expr.Type, valueType, env) {
+			msg := fmt.Sprintf("Analyzer error. type mismatch in slice literal. expected %s, got %s", expr.Type.Name(), valueType.Name())
+			errors = append(errors, msg)
+		}
+	}
+
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	return &ast.SliceDataType{Type: expr.Type}, nil
+}
+
+func analyzeStructLiteral(expr *ast.StructLiteral, env *object.Environment) (ast.DataType, []string) {
+	errors := resolveDataType(expr.Type, env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	var structType *ast.StructDataType
+	switch underlying := ast.Underlying(expr.Type).(type) {
+	case *ast.StructDataType:
+		structType = underlying
+	case *ast.SliceDataType:
+		if _, errors := analyzeSliceLiteral(expr.SliceLiteral(underlying), env); len(errors) != 0 {
+			return nil, errors
+		}
+
+		return expr.Type, nil
+	case *ast.MapDataType:
+		lit, ok := expr.MapLiteral(underlying)
+		if !ok {
+			return nil, []string{fmt.Sprintf("Analyzer error. missing key in map literal of type %s", expr.Type.Name())}
+		}
+
+		if _, errors := analyzeMapLiteral(lit, env); len(errors) != 0 {
+			return nil, errors
+		}
+
+		return expr.Type, nil
+	default:
+		return nil, []string{fmt.Sprintf("Analyzer error. invalid composite literal type %s", expr.Type.Name())}
+	}
+
+	keyed := 0
+	for _, value := range expr.Values {
+		if _, ok := value.(*ast.KeyValueExpression); ok {
+			keyed++
+		}
+	}
+
+	if keyed != 0 && keyed != len(expr.Values) {
+		msg := fmt.Sprintf("Analyzer error. mixture of field:value and value elements in struct literal of type %s", expr.Type.Name())
+		return nil, []string{msg}
+	}
+
+	if keyed == 0 && len(expr.Values) != 0 && len(expr.Values) != len(structType.Fields) {
+		msg := fmt.Sprintf("Analyzer error. wrong number of values in struct literal of type %s. expected %d, got %d", expr.Type.Name(), len(structType.Fields), len(expr.Values))
+		return nil, []string{msg}
+	}
+
+	for i, value := range expr.Values {
+		var field *ast.StructField
+		if pair, ok := value.(*ast.KeyValueExpression); ok {
+			key, ok := pair.Key.(*ast.Identifier)
+			if !ok {
+				msg := fmt.Sprintf("Analyzer error. invalid field name %s in struct literal", pair.Key.String())
+				errors = append(errors, msg)
+				continue
+			}
+
+			field, ok = structType.Field(key.Value)
+			if !ok {
+				msg := fmt.Sprintf("Analyzer error. unknown field %s in struct literal of type %s", pair.Key.String(), expr.Type.Name())
+				errors = append(errors, msg)
+				continue
+			}
+
+			value = pair.Value
+		} else {
+			field = structType.Fields[i]
+		}
+
+		if !ast.IsExported(field.Name) && isForeignType(expr.Type, env) {
+			msg := fmt.Sprintf("Analyzer error. cannot refer to unexported field %s in struct literal of type %s", field.Name, expr.Type.Name())
+			errors = append(errors, msg)
+			continue
+		}
+
+		valueType, tempErrors := AnalyzeExpression(value, env)
+		if len(tempErrors) != 0 {
+			errors = append(errors, tempErrors...)
+			continue
+		}
+
+		valueType, tempErrors = constantType(field.Type, valueType, value, env)
+		if len(tempErrors) != 0 {
+			errors = append(errors, tempErrors...)
+			continue
+		}
+
+		if !isAssignable(field.Type, valueType, env) {
+			msg := fmt.Sprintf("Analyzer error. type mismatch for field %s. expected %s, got %s", field.Name, field.Type.Name(), valueType.Name())
+			errors = append(errors, msg)
+		}
+	}
+
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	return expr.Type, nil
+}
+
+func analyzeSelectorExpression(expr *ast.SelectorExpression, env *object.Environment) (ast.DataType, []string) {
+	lType, errors := AnalyzeExpression(expr.Left, env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	if pkgType, ok := lType.(*ast.PackageDataType); ok {
+		pkg := packageObject(expr.Left, pkgType, env)
+		member, ok := pkg.Member(expr.Field.Value)
+		switch {
+		case !ok && pkg.Env != nil && !ast.IsExported(expr.Field.Value):
+			return nil, []string{fmt.Sprintf("Analyzer error. cannot refer to unexported name %s", expr.String())}
+		case !ok:
+			return nil, []string{fmt.Sprintf("Analyzer error. undefined: %s", expr.String())}
+		}
+
+		return member.Type(), nil
+	}
+
+	if !ast.IsExported(expr.Field.Value) && isForeignType(lType, env) {
+		msg := fmt.Sprintf("Analyzer error. %s undefined (cannot refer to unexported field or method %s)", expr.String(), expr.Field.Value)
+		return nil, []string{msg}
+	}
+
+	if fieldType, ok := structFieldType(lType, expr.Field.Value); ok {
+		return fieldType, nil
+	}
+
+	if interfaceType, ok := ast.Underlying(lType).(*ast.InterfaceDataType); ok {
+		if method, ok := interfaceType.Method(expr.Field.Value); ok {
+			return method.Type, nil
+		}
+	}
+
+	// The methods of a type parameter are those of its constraint.
+	if typeParam, ok := ast.Underlying(lType).(*ast.TypeParameterDataType); ok {
+		if method, ok := constraintInterface(typeParam.Constraint).Method(expr.Field.Value); ok {
+			return method.Type, nil
+		}
+	}
+
+	isPointer := false
+	if refType, ok := lType.(*ast.ReferenceDataType); ok {
+		lType = refType.ValueType
+		isPointer = true
+	}
+
+	if method, ok := env.GetMethod(ast.TypeName(lType), expr.Field.Value); ok {
+		if fn, ok := method.(*object.Function); ok {
+			// x.M() of a method with a pointer receiver is (&x).M(), x must be addressable.
+			if _, ok := (*fn.Receiver.DataType).(*ast.ReferenceDataType); ok && !isPointer && !isAddressable(expr.Left, env) {
+				msg := fmt.Sprintf("Analyzer error. cannot call pointer method %s on %s", expr.Field.Value, lType.Name())
+				return nil, []string{msg}
+			}
+
+			return fn.MethodType(lType), nil
+		}
+
+		return method.Type(), nil
+	}
+
+	if method, ok := object.BuiltinMethods[lType.Name()][expr.Field.Value]; ok {
+		return method.Type, nil
+	}
+
+	msg := fmt.Sprintf("Analyzer error. %s undefined (type %s has no field or method %s)", expr.String(), lType.Name(), expr.Field.Value)
+	return nil, []string{msg}
+}
+
+// isForeignType reports whether dType, or the type a pointer of type dType points to,
+// is declared by another package than the one env belongs to.
+func isForeignType(dType ast.DataType, env *object.Environment) bool {
+	if refType, ok := dType.(*ast.ReferenceDataType); ok {
+		dType = refType.ValueType
+	}
+
+	named, ok := ast.Unalias(dType).(*ast.NamedDataType)
+	return ok && named.Path != "" && named.Path != env.PackagePath()
+}
+
+// packageObject returns the package that expr, an expression of type pkgType, refers
+// to: an imported package or a predeclared one.
+func packageObject(expr ast.Expression, pkgType *ast.PackageDataType, env *object.Environment) *object.Package {
+	if ident, ok := expr.(*ast.Identifier); ok {
+		if obj, ok := env.Get(ident.Value); ok {
+			if pkg, ok := obj.(*object.Package); ok {
+				return pkg
+			}
+		}
+	}
+
+	return object.Packages[pkgType.PackageName]
+}
+
+// packageMember returns the member name of the package imported as pkgName.
+func packageMember(pkgName, name string, env *object.Environment) (object.Object, bool) {
+	if obj, ok := env.Get(pkgName); ok {
+		pkg, ok := obj.(*object.Package)
+		if !ok {
+			return nil, false
+		}
+
+		return pkg.Member(name)
+	}
+
+	pkg, ok := object.Packages[pkgName]
+	if !ok {
+		return nil, false
+	}
+
+	return pkg.Member(name)
+}
+
+// structFieldType returns the type of a field of a struct or a pointer to a struct.
+func structFieldType(dType ast.DataType, name string) (ast.DataType, bool) {
+	if refType, ok := dType.(*ast.ReferenceDataType); ok {
+		dType = refType.ValueType
+	}
+
+	structType, ok := ast.Underlying(dType).(*ast.StructDataType)
+	if !ok {
+		return nil, false
+	}
+
+	field, ok := structType.Field(name)
+	if !ok {
+		return nil, false
+	}
+
+	return field.Type, true
+}
+
+func analyzeTypeAssertionExpression(expr *ast.TypeAssertionExpression, env *object.Environment) (ast.DataType, []string) {
+	lType, errors := AnalyzeExpression(expr.Left, env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	if !object.IsInterfaceType(lType) {
+		msg := fmt.Sprintf("Analyzer error. invalid operation: %s (non-interface type %s on left)", expr.String(), lType.Name())
+		return nil, []string{msg}
+	}
+
+	if expr.Type == nil {
+		msg := fmt.Sprintf("Analyzer error. use of %s outside type switch", expr.String())
+		return nil, []string{msg}
+	}
+
+	if errors := resolveDataType(expr.Type, env); len(errors) != 0 {
+		return nil, errors
+	}
+
+	if interfaceType, ok := ast.Underlying(lType).(*ast.InterfaceDataType); ok && !object.IsInterfaceType(expr.Type) {
+		if method, missing := object.MissingMethod(expr.Type, interfaceType, env); missing {
+			msg := fmt.Sprintf("Analyzer error. impossible type assertion: %s (%s does not implement %s: missing method %s)", expr.String(), expr.Type.Name(), lType.Name(), method)
+			return nil, []string{msg}
+		}
+	}
+
+	return expr.Type, nil
+}
+
+func analyzeTypeSwitchStatement(stmt *ast.TypeSwitchStatement, returnType ast.DataType, env *object.Environment) []string {
+	if stmt.Init != nil {
+		env = object.NewEnclosedEnvironment(env)
+		if errors := AnalyzeStatement(stmt.Init, returnType, env); len(errors) != 0 {
+			return errors
+		}
+	}
+
+	subjectType, errors := AnalyzeExpression(stmt.Subject, env)
+	if len(errors) != 0 {
+		return errors
+	}
+
+	if !object.IsInterfaceType(subjectType) {
+		msg := fmt.Sprintf("Analyzer error. %s (type %s) is not an interface", stmt.Subject.String(), subjectType.Name())
+		return []string{msg}
+	}
+
+	hasDefault := false
+	seen := make(map[string]bool)
+	for _, clause := range stmt.Cases {
+		if len(clause.Types) == 0 {
+			if hasDefault {
+				errors = append(errors, "Analyzer error. multiple defaults in switch")
+			}
+
+			hasDefault = true
+		}
+
+		for _, dType := range clause.Types {
+			errors = append(errors, resolveDataType(dType, env)...)
+			if seen[dType.Name()] {
+				errors = append(errors, fmt.Sprintf("Analyzer error. duplicate case %s in type switch", dType.Name()))
+			}
+
+			seen[dType.Name()] = true
+		}
+
+		if len(errors) != 0 {
+			continue
+		}
+
+		clauseEnv := object.NewEnclosedEnvironment(env)
+		if stmt.Binding != nil {
+			bindingType := subjectType
+			if len(clause.Types) == 1 && clause.Types[0] != parser.NIL {
+				bindingType = clause.Types[0]
+			}
+
+			clauseEnv.Set(stmt.Binding.Value, NativeTypeToDefaultObj(bindingType))
+		}
+
+		errors = append(errors, analyzeBlockStatement(clause.Body, returnType, clauseEnv)...)
+	}
+
+	return errors
+}
+
+func analyzeSwitchStatement(stmt *ast.SwitchStatement, returnType ast.DataType, env *object.Environment) []string {
+	if stmt.Init != nil {
+		env = object.NewEnclosedEnvironment(env)
+		if errors := AnalyzeStatement(stmt.Init, returnType, env); len(errors) != 0 {
+			return errors
+		}
+	}
+
+	var tagType ast.DataType = parser.BOOLEAN
+	if stmt.Tag != nil {
+		var errors []string
+		tagType, errors = analyzeSingleValue(stmt.Tag, env)
+		if len(errors) != 0 {
+			return errors
+		}
+	}
+
+	var errors []string
+	hasDefault := false
+	seen := make(map[string]bool)
+	for _, clause := range stmt.Cases {
+		if len(clause.Values) == 0 {
+			if hasDefault {
+				errors = append(errors, "Analyzer error. multiple defaults in switch")
+			}
+
+			hasDefault = true
+		}
+
+		for _, value := range clause.Values {
+			valueType, tempErrors := analyzeSingleValue(value, env)
+			if len(tempErrors) != 0 {
+				errors = append(errors, tempErrors...)
+				continue
+			}
+
+			valueType, tempErrors = constantType(tagType, valueType, value, env)
+			if len(tempErrors) != 0 {
+				errors = append(errors, tempErrors...)
+				continue
+			}
+
+			if !isAssignable(tagType, valueType, env) && !isAssignable(valueType, tagType, env) {
+				var msg string
+				if stmt.Tag == nil {
+					msg = fmt.Sprintf("Analyzer error. invalid case %s in switch (mismatched types %s and bool)", value.String(), valueType.Name())
+				} else {
+					msg = fmt.Sprintf("Analyzer error. invalid case %s in switch on %s (mismatched types %s and %s)", value.String(), stmt.Tag.String(), valueType.Name(), tagType.Name())
+				}
+
+				errors = append(errors, msg)
+				continue
+			}
+
+			if constant, ok := constantCaseValue(value); ok {
+				if seen[constant] {
+					errors = append(errors, fmt.Sprintf("Analyzer error. duplicate case %s in expression switch", constant))
+				}
+
+				seen[constant] = true
+			}
+		}
+
+		if len(errors) != 0 {
+			continue
+		}
+
+		errors = append(errors, analyzeBlockStatement(clause.Body, returnType, env)...)
+	}
+
+	return errors
+}
+
+func analyzeSelectStatement(stmt *ast.SelectStatement, returnType ast.DataType, env *object.Environment) []string {
+	var errors []string
+	hasDefault := false
+	for _, clause := range stmt.Cases {
+		clauseEnv := object.NewEnclosedEnvironment(env)
+		if clause.Comm == nil {
+			if hasDefault {
+				errors = append(errors, "Analyzer error. multiple defaults in select")
+			}
+
+			hasDefault = true
+		} else if commErrors := AnalyzeStatement(clause.Comm, returnType, clauseEnv); len(commErrors) != 0 {
+			errors = append(errors, commErrors...)
+			continue
+		}
+
+		errors = append(errors, analyzeBlockStatement(clause.Body, returnType, clauseEnv)...)
+	}
+
+	return errors
+}
+
+// constantCaseValue returns the source form of a case value that is an integer or
+// string constant, so duplicate cases can be detected.
+func constantCaseValue(expr ast.Expression) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.IntegerLiteral:
+		return strconv.FormatInt(expr.Value, 10), true
+	case *ast.RuneLiteral:
+		return strconv.FormatInt(int64(expr.Value), 10), true
+	case *ast.FloatLiteral:
+		return strconv.FormatFloat(expr.Value, 'g', -1, 64), true
+	case *ast.StringLiteral:
+		return strconv.Quote(expr.Value), true
+	case *ast.PrefixExpression:
+		if literal, ok := expr.Right.(*ast.IntegerLiteral); ok && expr.Operator == "-" {
+			return strconv.FormatInt(-literal.Value, 10), true
+		}
+	}
+
+	return "", false
+}
+
+// isAssignable reports whether a value of valueType may be stored in a location of targetType.
+func isAssignable(targetType, valueType ast.DataType, env *object.Environment) bool {
+	// A generic function must be instantiated before it is used as a value.
+	if fnType, ok := valueType.(*ast.FunctionDataType); ok && len(fnType.TypeParams) != 0 {
+		return false
+	}
+
+	if targetType == parser.ANY || valueType == parser.ANY || ast.Identical(targetType, valueType) {
+		return true
+	}
+
+	// A value of an unnamed type, e.g. a function literal, may be used where a named
+	// type with the same underlying type is expected, and vice versa.
+	if (!ast.IsNamed(targetType) || !ast.IsNamed(valueType)) && ast.Identical(ast.Underlying(targetType), ast.Underlying(valueType)) {
+		return true
+	}
+
+	if valueType == parser.NIL {
+		switch ast.Underlying(targetType).(type) {
+		case *ast.ReferenceDataType, *ast.SliceDataType, *ast.MapDataType, *ast.ChanDataType,
+			*ast.FunctionDataType, *ast.InterfaceDataType:
+			return true
+		default:
+			return false
+		}
+	}
+
+	if interfaceType, ok := ast.Underlying(targetType).(*ast.InterfaceDataType); ok {
+		_, missing := object.MissingMethod(valueType, interfaceType, env)
+		return !missing
+	}
+
+	targetTuple, ok := targetType.(*ast.TupleDataType)
+	valueTuple, ok2 := valueType.(*ast.TupleDataType)
+	if !ok || !ok2 || len(targetTuple.Types) != len(valueTuple.Types) {
+		return false
+	}
+
+	for i := range targetTuple.Types {
+		if !isAssignable(targetTuple.Types[i], valueTuple.Types[i], env) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func typeMismatchError(targetType, valueType ast.DataType, env *object.Environment) string {
+	if interfaceType, ok := ast.Underlying(targetType).(*ast.InterfaceDataType); ok && valueType != parser.NIL {
+		method, _ := object.MissingMethod(valueType, interfaceType, env)
+		return fmt.Sprintf("Analyzer error. %s does not implement %s (missing method %s)", valueType.Name(), targetType.Name(), method)
+	}
+
+	return fmt.Sprintf("Analyzer error. type mismatch. expected %s, got %s", targetType.Name(), valueType.Name())
+}
+
+func analyzeIfStatement(expr *ast.IfStatement, returnType ast.DataType, env *object.Environment) []string {
+	conditionType, errors := AnalyzeExpression(expr.Condition, env)
+	if len(errors) != 0 {
+		return errors
+	}
+
+	if conditionType != parser.BOOLEAN {
+		msg := fmt.Sprintf("Analyzer error. expected boolean type for if expression, got %s", conditionType.Name())
+		return []string{msg}
+	}
+
+	errors = append(errors, analyzeBlockStatement(expr.Consequence, returnType, env)...)
+	if expr.Alternative != nil {
+		errors = append(errors, analyzeBlockStatement(expr.Alternative, returnType, env)...)
+	}
+	return errors
+}
+
+// analyzeNewCall checks new(T), which returns a pointer to a new zero value of type T.
+func analyzeNewCall(expr *ast.CallExpression, env *object.Environment) (ast.DataType, []string) {
+	if len(expr.Arguments) != 1 {
+		msg := fmt.Sprintf("analyzer error. wrong number of arguments for new. expected 1, got %d", len(expr.Arguments))
+		return nil, []string{msg}
+	}
+
+	dType, errors := analyzeTypeArgument(expr.Arguments[0], env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	return &ast.ReferenceDataType{ValueType: dType}, nil
+}
+
+// analyzeTypeArgument returns the type passed as the argument of a builtin like new.
+func analyzeTypeArgument(arg ast.Expression, env *object.Environment) (ast.DataType, []string) {
+	var dType ast.DataType
+	switch arg := arg.(type) {
+	case *ast.DataTypeExpression:
+		dType = arg.Type
+	case *ast.Identifier:
+		if typeObj, ok := env.Get(arg.Value); ok {
+			if typeObj, ok := typeObj.(*object.DataTypeObject); ok {
+				dType = typeObj.DataType
+			}
+		}
+	}
+
+	if dType == nil {
+		return nil, []string{fmt.Sprintf("analyzer error. %s is not a type", arg.String())}
+	}
+
+	if errors := resolveDataType(dType, env); len(errors) != 0 {
+		return nil, errors
+	}
+
+	return dType, nil
+}
+
+// analyzeMakeCall checks make(T, size...), which returns a value of the slice, map or
+// channel type T. Slices take a length and an optional capacity, maps and channels
+// an optional size.
+func analyzeMakeCall(expr *ast.CallExpression, env *object.Environment) (ast.DataType, []string) {
+	if len(expr.Arguments) == 0 {
+		return nil, []string{"analyzer error. not enough arguments for make"}
+	}
+
+	dType, errors := analyzeTypeArgument(expr.Arguments[0], env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	minSizes, maxSizes := 0, 1
+	switch ast.Underlying(dType).(type) {
+	case *ast.SliceDataType:
+		minSizes, maxSizes = 1, 2
+	case *ast.MapDataType, *ast.ChanDataType:
+	default:
+		msg := fmt.Sprintf("analyzer error. invalid argument: cannot make %s; type must be slice, map, or channel", dType.Name())
+		return nil, []string{msg}
+	}
+
+	sizes := expr.Arguments[1:]
+	if len(sizes) < minSizes || len(sizes) > maxSizes {
+		msg := fmt.Sprintf("analyzer error. invalid operation: %s expects %d or %d arguments; found %d", expr.String(), minSizes+1, maxSizes+1, len(expr.Arguments))
+		return nil, []string{msg}
+	}
+
+	for _, size := range sizes {
+		sizeType, errors := analyzeSingleValue(size, env)
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		if !isIntegerType(sizeType) {
+			msg := fmt.Sprintf("analyzer error. cannot use %s (type %s) as size argument in make", size.String(), sizeType.Name())
+			return nil, []string{msg}
+		}
+	}
+
+	return dType, nil
+}
+
+// analyzeAppendCall checks append(s, x...), which returns a value of the slice type of s.
+func analyzeAppendCall(expr *ast.CallExpression, env *object.Environment) (ast.DataType, []string) {
+	if len(expr.Arguments) == 0 {
+		return nil, []string{"analyzer error. not enough arguments for append"}
+	}
+
+	dType, errors := analyzeSingleValue(expr.Arguments[0], env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	if dType == parser.ANY {
+		return parser.ANY, nil
+	}
+
+	sliceType, ok := ast.Underlying(dType).(*ast.SliceDataType)
+	if !ok {
+		msg := fmt.Sprintf("analyzer error. invalid argument: %s (type %s) is not a slice", expr.Arguments[0].String(), dType.Name())
+		return nil, []string{msg}
+	}
+
+	if expr.Ellipsis && len(expr.Arguments) != 2 {
+		msg := fmt.Sprintf("analyzer error. can only use ... with final argument in call to append, got %d arguments", len(expr.Arguments))
+		return nil, []string{msg}
+	}
+
+	for _, arg := range expr.Arguments[1:] {
+		argType, errors := analyzeSingleValue(arg, env)
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		// append(bytes, s...) appends the bytes of the string s.
+		if expr.Ellipsis {
+			if !isAssignable(&ast.SliceDataType{Type: sliceType.Type}, argType, env) &&
+				!(ast.Underlying(sliceType.Type) == parser.BYTE && isStringType(argType)) {
+				msg := fmt.Sprintf("analyzer error. cannot use %s (type %s) as type %s in append", arg.String(), argType.Name(), dType.Name())
+				return nil, []string{msg}
+			}
+
+			continue
+		}
+
+		argType, errors = constantType(sliceType.Type, argType, arg, env)
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		if !isAssignable(sliceType.Type, argType, env) {
+			msg := fmt.Sprintf("analyzer error. cannot use %s (type %s) as type %s in append", arg.String(), argType.Name(), sliceType.Type.Name())
+			return nil, []string{msg}
+		}
+	}
+
+	return dType, nil
+}
+
+// analyzeLenCall checks len(x) of a string, slice, map or channel.
+func analyzeLenCall(expr *ast.CallExpression, env *object.Environment) (ast.DataType, []string) {
+	if len(expr.Arguments) != 1 {
+		msg := fmt.Sprintf("analyzer error. wrong number of arguments for len. expected 1, got %d", len(expr.Arguments))
+		return nil, []string{msg}
+	}
+
+	dType, errors := analyzeSingleValue(expr.Arguments[0], env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	switch ast.Underlying(dType).(type) {
+	case *ast.StringDataType, *ast.SliceDataType, *ast.MapDataType, *ast.ChanDataType, *ast.AnyDataType:
+		return parser.INT, nil
+	}
+
+	if isStringType(dType) {
+		return parser.INT, nil
+	}
+
+	msg := fmt.Sprintf("analyzer error. invalid argument: %s (type %s) for len", expr.Arguments[0].String(), dType.Name())
+	return nil, []string{msg}
+}
+
+func analyzeCallExpression(expr *ast.CallExpression, env *object.Environment) (ast.DataType, []string) {
+	if typeExpr, ok := expr.Function.(*ast.DataTypeExpression); ok {
+		return analyzeConversion(expr, typeExpr.Type, env)
+	}
+
+	if ident, ok := expr.Function.(*ast.Identifier); ok && !env.Contains(ident.Value) {
+		switch ident.Value {
+		case "new":
+			return analyzeNewCall(expr, env)
+		case "make":
+			return analyzeMakeCall(expr, env)
+		case "append":
+			return analyzeAppendCall(expr, env)
+		case "len":
+			return analyzeLenCall(expr, env)
+		}
+	}
+
+	if ident, ok := expr.Function.(*ast.Identifier); ok {
+		if typeObj, ok := env.Get(ident.Value); ok {
+			if typeObj, ok := typeObj.(*object.DataTypeObject); ok {
+				return analyzeConversion(expr, typeObj.DataType, env)
+			}
+		}
+	}
+
+	// deploy.Port(x) converts x to the type Port of the package deploy.
+	if selector, ok := expr.Function.(*ast.SelectorExpression); ok {
+		if pkg, ok := selector.Left.(*ast.Identifier); ok {
+			if typeObj, ok := packageMember(pkg.Value, selector.Field.Value, env); ok {
+				if typeObj, ok := typeObj.(*object.DataTypeObject); ok {
+					return analyzeConversion(expr, typeObj.DataType, env)
+				}
+			}
+		}
+	}
+
+	dType, errors := AnalyzeExpression(expr.Function, env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	// Builtins with parameter types are checked like functions.
+	if fnType, ok := dType.(*ast.BuiltinDataType); ok && fnType.Parameters != nil {
+		dType = &ast.FunctionDataType{Parameters: fnType.Parameters, ReturnType: fnType.ReturnType}
+	}
+
+	switch fnType := ast.Underlying(dType).(type) {
+	case *ast.BuiltinDataType:
+		for _, arg := range expr.Arguments {
+			argType, tempErrors := AnalyzeExpression(arg, env)
+			if len(tempErrors) == 0 && isUntypedConstant(arg, env) {
+				tempErrors = convertConstant(arg, argType, env)
+			}
+
+			errors = append(errors, tempErrors...)
+		}
+
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		if fnType.ReturnType != nil {
+			return fnType.ReturnType, nil
+		}
+
+		return parser.ANY, nil
+	case *ast.FunctionDataType:
+		if len(fnType.TypeParams) != 0 {
+			instance, typeArgs, errors := inferTypeArguments(expr, fnType, env)
+			if len(errors) != 0 {
+				return nil, errors
+			}
+
+			expr.TypeArgs = typeArgs
+			fnType = instance
+		}
+
+		if expr.Ellipsis && !fnType.Variadic {
+			msg := fmt.Sprintf("analyzer error. cannot use ... in call to non-variadic %s", expr.Function.String())
+			return nil, []string{msg}
+		}
+
+		// f(g()) passes all results of g as arguments of f.
+		if len(expr.Arguments) == 1 && !expr.Ellipsis && (len(fnType.Parameters) > 1 || fnType.Variadic) {
+			argType, errors := AnalyzeExpression(expr.Arguments[0], env)
+			if len(errors) != 0 {
+				return nil, errors
+			}
+
+			if tupleType, ok := argType.(*ast.TupleDataType); ok {
+				if errors := checkArgumentCount(fnType, len(tupleType.Types), false); len(errors) != 0 {
+					return nil, errors
+				}
+
+				paramTypes := argumentTypes(fnType, len(tupleType.Types), false)
+				if !isAssignable(&ast.TupleDataType{Types: paramTypes}, argType, env) {
+					msg := fmt.Sprintf("analyzer error. Incorrect type passed into function. expected %s, got=%s", fnType.Name(), argType.Name())
+					return nil, []string{msg}
+				}
+
+				return fnType.ReturnType, nil
+			}
+		}
+
+		if errors := checkArgumentCount(fnType, len(expr.Arguments), expr.Ellipsis); len(errors) != 0 {
+			return nil, errors
+		}
+
+		paramTypes := argumentTypes(fnType, len(expr.Arguments), expr.Ellipsis)
+		for i, param := range expr.Arguments {
+			var arg ast.DataType
+			arg, tempErrors := analyzeSingleValue(param, env)
+			if len(tempErrors) != 0 {
+				return nil, append(errors, tempErrors...)
+			}
+
+			arg, tempErrors = constantType(paramTypes[i], arg, param, env)
+			if len(tempErrors) != 0 {
+				return nil, append(errors, tempErrors...)
+			}
+
+			if !isAssignable(paramTypes[i], arg, env) {
+				msg := fmt.Sprintf("analyzer error. Incorrect type passed into function. expected %s, got=%s", fnType.Name(), arg.Name())
+				errors = append(errors, msg)
+			}
+		}
+
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		return fnType.ReturnType, nil
+	default:
+		msg := fmt.Sprintf("Analyzer error. Unsupported call type %T", dType)
+		return nil, []string{msg}
+	}
+}
+
+// analyzeConversion checks the conversion T(x) of a single value to targetType.
+// Numeric values convert to any numeric type.
+func analyzeConversion(expr *ast.CallExpression, targetType ast.DataType, env *object.Environment) (ast.DataType, []string) {
+	if errors := resolveDataType(targetType, env); len(errors) != 0 {
+		return nil, errors
+	}
+
+	switch {
+	case len(expr.Arguments) == 0:
+		return nil, []string{fmt.Sprintf("analyzer error. missing argument in conversion to %s", targetType.Name())}
+	case len(expr.Arguments) > 1 || expr.Ellipsis:
+		return nil, []string{fmt.Sprintf("analyzer error. too many arguments in conversion to %s", targetType.Name())}
+	}
+
+	argType, errors := analyzeSingleValue(expr.Arguments[0], env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	if !isAssignable(targetType, argType, env) && !isConvertible(targetType, argType) {
+		msg := fmt.Sprintf("analyzer error. cannot convert %s (type %s) to type %s", expr.Arguments[0].String(), argType.Name(), targetType.Name())
+		return nil, []string{msg}
+	}
+
+	// Constants must be representable by the numeric type they convert to.
+	if isNumericType(targetType) && isConstant(expr.Arguments[0], env) {
+		return targetType, convertConstant(expr.Arguments[0], targetType, env)
+	}
+
+	return targetType, nil
+}
+
+// isConvertible reports whether values of valueType convert to targetType other than
+// by assignment: between types with the same underlying type, between numeric types, from integers to strings, and between
+// strings and byte or rune slices.
+func isConvertible(targetType, valueType ast.DataType) bool {
+	switch {
+	case ast.Identical(ast.Underlying(targetType), ast.Underlying(valueType)):
+		return true
+	case isNumericType(targetType) && isNumericType(valueType):
+		return true
+	case ast.Underlying(targetType) == parser.STRING && isIntegerType(valueType):
+		return true
+	case ast.Underlying(targetType) == parser.STRING:
+		return isByteOrRuneSlice(valueType)
+	case ast.Underlying(valueType) == parser.STRING:
+		return isByteOrRuneSlice(targetType)
+	default:
+		return false
+	}
+}
+
+func isByteOrRuneSlice(dType ast.DataType) bool {
+	sliceType, ok := ast.Underlying(dType).(*ast.SliceDataType)
+	if !ok {
+		return false
+	}
+
+	switch ast.Underlying(sliceType.Type).(type) {
+	case *ast.ByteDataType, *ast.RuneDataType:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkArgumentCount checks the number of arguments passed to a function. A variadic
+// function accepts any number of trailing arguments unless a slice is spread into it.
+func checkArgumentCount(fnType *ast.FunctionDataType, count int, spread bool) []string {
+	if fnType.Variadic && !spread {
+		if count < len(fnType.Parameters)-1 {
+			return []string{fmt.Sprintf("analyzer error. Incorrect parameter count. expected at least %d, got=%d", len(fnType.Parameters)-1, count)}
+		}
+
+		return nil
+	}
+
+	if count != len(fnType.Parameters) {
+		return []string{fmt.Sprintf("analyzer error. Incorrect parameter count. expected %d, got=%d", len(fnType.Parameters), count)}
+	}
+
+	return nil
+}
+
+// argumentTypes returns the types expected for count arguments, with the variadic
+// parameter expanded to its element type for each trailing argument.
+func argumentTypes(fnType *ast.FunctionDataType, count int, spread bool) []ast.DataType {
+	if !fnType.Variadic || spread {
+		return fnType.Parameters
+	}
+
+	fixed := len(fnType.Parameters) - 1
+	elemType := fnType.Parameters[fixed].(*ast.SliceDataType).Type
+
+	types := append([]ast.DataType{}, fnType.Parameters[:fixed]...)
+	for i := fixed; i < count; i++ {
+		types = append(types, elemType)
+	}
+
+	return types
+}
+
+// inferTypeArguments infers the type arguments of a call of a generic function from
+// the types of its arguments and returns the instantiated function type. Untyped
+// constants only determine type parameters that are not inferred otherwise.
+func inferTypeArguments(expr *ast.CallExpression, fnType *ast.FunctionDataType, env *object.Environment) (*ast.FunctionDataType, []ast.DataType, []string) {
+	var argTypes []ast.DataType
+	untyped := make([]bool, len(expr.Arguments))
+	if len(expr.Arguments) == 1 {
+		argType, errors := AnalyzeExpression(expr.Arguments[0], env)
+		if len(errors) != 0 {
+			return nil, nil, errors
+		}
+
+		if tupleType, ok := argType.(*ast.TupleDataType); ok {
+			argTypes = tupleType.Types
+			untyped = make([]bool, len(argTypes))
+		} else {
+			argTypes = []ast.DataType{argType}
+			untyped[0] = isUntypedConstant(expr.Arguments[0], env)
+		}
+	} else {
+		for i, arg := range expr.Arguments {
+			argType, errors := analyzeSingleValue(arg, env)
+			if len(errors) != 0 {
+				return nil, nil, errors
+			}
+
+			argTypes = append(argTypes, argType)
+			untyped[i] = isUntypedConstant(arg, env)
+		}
+	}
+
+	if errors := checkArgumentCount(fnType, len(argTypes), expr.Ellipsis); len(errors) != 0 {
+		return nil, nil, errors
+	}
+
+	bindings := make(map[*ast.TypeParameterDataType]ast.DataType)
+	paramTypes := argumentTypes(fnType, len(argTypes), expr.Ellipsis)
+	for _, constants := range []bool{false, true} {
+		for i, argType := range argTypes {
+			if untyped[i] == constants {
+				unify(paramTypes[i], argType, bindings)
+			}
+		}
+	}
+
+	typeArgs := make([]ast.DataType, len(fnType.TypeParams))
+	for i, param := range fnType.TypeParams {
+		typeArg, ok := bindings[param]
+		if !ok {
+			msg := fmt.Sprintf("analyzer error. cannot infer %s in call to %s", param.TypeName, expr.Function.String())
+			return nil, nil, []string{msg}
+		}
+
+		typeArgs[i] = typeArg
+	}
+
+	instance, errors := instantiateFunction(fnType, typeArgs, env)
+	return instance, typeArgs, errors
+}
+
+// unify matches the parameter type param with the argument type arg and records the
+// types the type parameters in param stand for.
+func unify(param, arg ast.DataType, bindings map[*ast.TypeParameterDataType]ast.DataType) {
+	param = ast.Unalias(param)
+	if typeParam, ok := param.(*ast.TypeParameterDataType); ok {
+		if _, bound := bindings[typeParam]; !bound && arg != parser.NIL {
+			bindings[typeParam] = arg
+		}
+
+		return
+	}
+
+	if named, ok := param.(*ast.NamedDataType); ok {
+		argNamed, ok := ast.Unalias(arg).(*ast.NamedDataType)
+		if ok && named.Origin != nil && argNamed.Origin == named.Origin {
+			for i := range named.TypeArgs {
+				unify(named.TypeArgs[i], argNamed.TypeArgs[i], bindings)
+			}
+		}
+
+		return
+	}
+
+	switch param := param.(type) {
+	case *ast.SliceDataType:
+		if arg, ok := ast.Underlying(arg).(*ast.SliceDataType); ok {
+			unify(param.Type, arg.Type, bindings)
+		}
+	case *ast.MapDataType:
+		if arg, ok := ast.Underlying(arg).(*ast.MapDataType); ok {
+			unify(param.KeyType, arg.KeyType, bindings)
+			unify(param.ValueType, arg.ValueType, bindings)
+		}
+	case *ast.ChanDataType:
+		if arg, ok := ast.Underlying(arg).(*ast.ChanDataType); ok {
+			unify(param.ValueType, arg.ValueType, bindings)
+		}
+	case *ast.ReferenceDataType:
+		if arg, ok := ast.Underlying(arg).(*ast.ReferenceDataType); ok {
+			unify(param.ValueType, arg.ValueType, bindings)
+		}
+	case *ast.TupleDataType:
+		if arg, ok := arg.(*ast.TupleDataType); ok && len(arg.Types) == len(param.Types) {
+			for i := range param.Types {
+				unify(param.Types[i], arg.Types[i], bindings)
+			}
+		}
+	case *ast.FunctionDataType:
+		if arg, ok := ast.Underlying(arg).(*ast.FunctionDataType); ok && len(arg.Parameters) == len(param.Parameters) {
+			for i := range param.Parameters {
+				unify(param.Parameters[i], arg.Parameters[i], bindings)
+			}
+
+			unify(param.ReturnType, arg.ReturnType, bindings)
+		}
+	}
+}
+
+// analyzeInstantiation checks the instantiation f[T, U] of a generic function with
+// explicit type arguments.
+func analyzeInstantiation(fnExpr ast.Expression, typeArgExprs []ast.Expression, env *object.Environment) (*ast.FunctionDataType, []ast.DataType, []string) {
+	dType, errors := AnalyzeExpression(fnExpr, env)
+	if len(errors) != 0 {
+		return nil, nil, errors
+	}
+
+	fnType, ok := dType.(*ast.FunctionDataType)
+	if !ok || len(fnType.TypeParams) == 0 {
+		return nil, nil, []string{fmt.Sprintf("analyzer error. %s is not a generic function", fnExpr.String())}
+	}
+
+	if len(typeArgExprs) != len(fnType.TypeParams) {
+		msg := fmt.Sprintf("analyzer error. wrong number of type arguments for %s. expected %d, got %d", fnExpr.String(), len(fnType.TypeParams), len(typeArgExprs))
+		return nil, nil, []string{msg}
+	}
+
+	typeArgs := make([]ast.DataType, len(typeArgExprs))
+	for i, arg := range typeArgExprs {
+		typeArgs[i] = parser.ExpressionDataType(arg)
+		if typeArgs[i] == nil {
+			return nil, nil, []string{fmt.Sprintf("analyzer error. %s is not a type", arg.String())}
+		}
+
+		if errors := resolveDataType(typeArgs[i], env); len(errors) != 0 {
+			return nil, nil, errors
+		}
+	}
+
+	instance, errors := instantiateFunction(fnType, typeArgs, env)
+	return instance, typeArgs, errors
+}
+
+// instantiateFunction checks the type arguments of a generic function against the
+// constraints of its type parameters and returns the function type with the type
+// arguments substituted.
+func instantiateFunction(fnType *ast.FunctionDataType, typeArgs []ast.DataType, env *object.Environment) (*ast.FunctionDataType, []string) {
+	if errors := checkTypeArguments(fnType.TypeParams, typeArgs, env); len(errors) != 0 {
+		return nil, errors
+	}
+
+	generic := &ast.FunctionDataType{Parameters: fnType.Parameters, ReturnType: fnType.ReturnType, Variadic: fnType.Variadic}
+	return ast.Substitute(generic, fnType.TypeParams, typeArgs).(*ast.FunctionDataType), nil
+}
+
+func checkTypeArguments(params []*ast.TypeParameterDataType, typeArgs []ast.DataType, env *object.Environment) []string {
+	for i, param := range params {
+		// Constraints may refer to the other type parameters, as in [S ~[]E, E any].
+		constraint := ast.Substitute(param.Constraint, params, typeArgs)
+		if !satisfies(typeArgs[i], constraint, env) {
+			return []string{fmt.Sprintf("analyzer error. %s does not satisfy %s", typeArgs[i].Name(), constraint.Name())}
+		}
+	}
+
+	return nil
+}
+
+// satisfies reports whether the type argument typeArg satisfies constraint: it must
+// be in the type set of the constraint and implement its methods.
+func satisfies(typeArg, constraint ast.DataType, env *object.Environment) bool {
+	iface := constraintInterface(constraint)
+	if typeParam, ok := ast.Unalias(typeArg).(*ast.TypeParameterDataType); ok {
+		// A type parameter satisfies constraints that hold for all types in its type set.
+		argIface := constraintInterface(typeParam.Constraint)
+		if iface.Comparable && !isComparableType(typeParam) {
+			return false
+		}
+
+		if len(iface.Union) != 0 {
+			if len(argIface.Union) == 0 {
+				return false
+			}
+
+			for _, term := range argIface.Union {
+				if !inTypeSet(term, iface.Union) {
+					return false
+				}
+			}
+		}
+
+		_, missing := object.MissingMethod(argIface, iface, env)
+		return !missing
+	}
+
+	if iface.Comparable && !isComparableType(typeArg) {
+		return false
+	}
+
+	if len(iface.Union) != 0 && !inTypeSet(&ast.TypeTerm{Type: typeArg}, iface.Union) {
+		return false
+	}
+
+	_, missing := object.MissingMethod(typeArg, iface, env)
+	return !missing
+}
+
+// constraintInterface returns a constraint as an interface. A type T used as a
+// constraint stands for the interface with the type set {T}.
+func constraintInterface(constraint ast.DataType) *ast.InterfaceDataType {
+	switch iface := ast.Underlying(constraint).(type) {
+	case *ast.InterfaceDataType:
+		return iface
+	case *ast.AnyDataType:
+		return &ast.InterfaceDataType{}
+	default:
+		return &ast.InterfaceDataType{Union: []*ast.TypeTerm{{Type: constraint}}}
+	}
+}
+
+// inTypeSet reports whether the types of term belong to the type set of union.
+func inTypeSet(term *ast.TypeTerm, union []*ast.TypeTerm) bool {
+	for _, other := range union {
+		if other.Tilde && ast.Identical(ast.Underlying(term.Type), ast.Underlying(other.Type)) {
+			return true
+		}
+
+		if !other.Tilde && !term.Tilde && ast.Identical(term.Type, other.Type) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typeSetAll reports whether pred holds for all types in the type set of the type
+// parameter typeParam. Type sets without a union are not restricted and hold any type.
+func typeSetAll(typeParam *ast.TypeParameterDataType, pred func(ast.DataType) bool) bool {
+	union := constraintInterface(typeParam.Constraint).Union
+	if len(union) == 0 {
+		return false
+	}
+
+	for _, term := range union {
+		if !pred(term.Type) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isComparableType reports whether values of dType may be compared with == and !=.
+func isComparableType(dType ast.DataType) bool {
+	switch dType := ast.Underlying(dType).(type) {
+	case *ast.SliceDataType, *ast.MapDataType, *ast.FunctionDataType:
+		return false
+	case *ast.StructDataType:
+		for _, field := range dType.Fields {
+			if !isComparableType(field.Type) {
+				return false
+			}
+		}
+
+		return true
+	case *ast.TypeParameterDataType:
+		return constraintInterface(dType.Constraint).Comparable || typeSetAll(dType, isComparableType)
+	default:
+		return true
+	}
+}
+
+// checkComparable checks that the operands of == or != are comparable. Slices, maps
+// and functions compare only to nil, and values of a type parameter only if all
+// types in its type set do.
+func checkComparable(expr *ast.InfixExpression, leftType, rightType ast.DataType) []string {
+	if leftType == parser.NIL || rightType == parser.NIL {
+		return nil
+	}
+
+	for _, dType := range []ast.DataType{leftType, rightType} {
+		if isComparableType(dType) {
+			continue
+		}
+
+		var msg string
+		switch ast.Underlying(dType).(type) {
+		case *ast.TypeParameterDataType:
+			msg = fmt.Sprintf("analyzer error. incomparable types in type set of %s", dType.Name())
+		case *ast.StructDataType:
+			msg = fmt.Sprintf("analyzer error. invalid operation: %s (struct containing %s cannot be compared)", expr.String(), incomparableField(dType).Name())
+		default:
+			msg = fmt.Sprintf("analyzer error. invalid operation: %s (%s can only be compared to nil)", expr.String(), dType.Name())
+		}
+
+		return []string{msg}
+	}
+
+	return nil
+}
+
+// incomparableField returns the type of the first field of a struct type that makes
+// the struct incomparable.
+func incomparableField(dType ast.DataType) ast.DataType {
+	for _, field := range ast.Underlying(dType).(*ast.StructDataType).Fields {
+		if isComparableType(field.Type) {
+			continue
+		}
+
+		if _, ok := ast.Underlying(field.Type).(*ast.StructDataType); ok {
+			return incomparableField(field.Type)
+		}
+
+		return field.Type
+	}
+
+	return dType
+}
+
+func analyzeFunctionLiteral(expr *ast.FunctionLiteral, env *object.Environment) (ast.DataType, []string) {
+	typeEnv, errors := typeParameterEnvironment(expr.TypeParams, env)
+	if expr.Receiver != nil && len(errors) == 0 {
+		typeEnv, errors = receiverTypeParameterEnvironment(expr.Receiver, typeEnv)
+	}
+
+	for _, ident := range expr.Parameters {
+		errors = append(errors, resolveDataType(*ident.DataType, typeEnv)...)
+	}
+
+	errors = append(errors, resolveDataType(expr.ReturnType, typeEnv)...)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	// Declared functions are visible in their own body to allow recursion.
+	placeholder := &object.Function{Receiver: expr.Receiver, Name: expr.Name, TypeParams: expr.TypeParams, Parameters: expr.Parameters, ReturnType: expr.ReturnType, Body: expr.Body, Variadic: expr.Variadic}
+	if expr.Receiver != nil {
+		typeName, errors := analyzeReceiver(expr.Receiver, typeEnv)
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		env.SetMethod(typeName, expr.Name.Value, placeholder)
+	} else if expr.Name != nil {
+		env.Set(expr.Name.Value, placeholder)
+	}
+
+	env = object.NewEnclosedEnvironment(typeEnv)
+	fn := &ast.FunctionDataType{
+		TypeParams: expr.TypeParams,
+		ReturnType: expr.ReturnType,
+		Variadic:   expr.Variadic,
+	}
+
+	if expr.Receiver != nil {
+		env.Set(expr.Receiver.Value, NativeTypeToDefaultObj(*expr.Receiver.DataType))
+	}
+
+	for _, ident := range expr.Parameters {
+		env.Set(ident.Value, NativeTypeToDefaultObj(*ident.DataType))
+		fn.Parameters = append(fn.Parameters, *ident.DataType)
+	}
+
+	errors = analyzeBlockStatement(expr.Body, expr.ReturnType, env)
+	return fn, errors
+}
+
+// analyzeReceiver checks the receiver of a method and returns the name of the type
+// the method belongs to.
+func analyzeReceiver(receiver *ast.Identifier, env *object.Environment) (string, []string) {
+	receiverType := *receiver.DataType
+	if refType, ok := receiverType.(*ast.ReferenceDataType); ok {
+		receiverType = refType.ValueType
+	}
+
+	named, ok := receiverType.(*ast.NamedDataType)
+	if !ok {
+		return "", []string{fmt.Sprintf("Analyzer error. invalid receiver type %s", receiverType.Name())}
+	}
+
+	if errors := resolveDataType(named, env); len(errors) != 0 {
+		return "", errors
+	}
+
+	return ast.TypeName(named), nil
+}
+
+// receiverTypeParameterEnvironment declares the type parameters of a method of a
+// generic type, which are named by the type arguments of its receiver type, as T in
+// (s *Stack[T]). They take the constraints of the type parameters of the type.
+func receiverTypeParameterEnvironment(receiver *ast.Identifier, env *object.Environment) (*object.Environment, []string) {
+	receiverType := *receiver.DataType
+	if refType, ok := receiverType.(*ast.ReferenceDataType); ok {
+		receiverType = refType.ValueType
+	}
+
+	named, ok := receiverType.(*ast.NamedDataType)
+	if !ok || len(named.TypeArgs) == 0 {
+		return env, nil
+	}
+
+	obj, _ := env.Get(named.TypeName)
+	typeObj, ok := obj.(*object.DataTypeObject)
+	if !ok {
+		return env, nil
+	}
+
+	decl, ok := typeObj.DataType.(*ast.NamedDataType)
+	if !ok || len(decl.TypeParams) != len(named.TypeArgs) {
+		return env, nil
+	}
+
+	params := make([]*ast.TypeParameterDataType, len(named.TypeArgs))
+	for i, arg := range named.TypeArgs {
+		argName, ok := arg.(*ast.NamedDataType)
+		if !ok || len(argName.TypeArgs) != 0 {
+			return nil, []string{fmt.Sprintf("Analyzer error. receiver type parameter %s must be an identifier", arg.Name())}
+		}
+
+		params[i] = &ast.TypeParameterDataType{TypeName: argName.TypeName, Constraint: decl.TypeParams[i].Constraint}
+	}
+
+	return typeParameterEnvironment(params, env)
+}
+
+func NativeTypeToDefaultObj(rawType ast.DataType) object.Object {
+	switch rawType := rawType.(type) {
+	case *ast.IntegerDataType:
+		return &object.Integer{}
+	case *ast.FloatDataType:
+		return &object.Float{}
+	case *ast.ByteDataType, *ast.RuneDataType:
+		return &object.Integer{IntegerType: rawType}
+	case *ast.BooleanDataType:
+		return object.FALSE
+	case *ast.StringDataType:
+		return &object.String{}
+	case *ast.SliceDataType:
+		return &object.SliceObject{ValueType: rawType.Type}
+	case *ast.MapDataType:
+		return &object.MapObject{KeyType: rawType.KeyType, ValueType: rawType.ValueType}
+	case *ast.StructDataType:
+		return newStructObject(rawType, rawType)
+	case *ast.NamedDataType:
+		if rawType.Alias && rawType.Underlying != nil {
+			return NativeTypeToDefaultObj(rawType.Underlying)
+		}
+
+		if object.IsInterfaceType(rawType) {
+			return &object.Interface{InterfaceType: rawType}
+		}
+
+		if structType, ok := rawType.Underlying.(*ast.StructDataType); ok {
+			return newStructObject(rawType, structType)
+		}
+
+		return object.Convert(NativeTypeToDefaultObj(rawType.Underlying), rawType)
+	case *ast.AnyDataType, *ast.InterfaceDataType:
+		return &object.Interface{InterfaceType: rawType}
+	case *ast.ReferenceDataType:
+		return &object.ReferenceObject{ValueType: rawType.ValueType}
+	case *ast.ChanDataType:
+		return &object.ChanObject{
+			Chan:     nil,
+			ChanType: rawType.ValueType,
+		}
+	case *ast.FunctionDataType:
+		return &object.NilFunction{FunctionType: rawType}
+	case *ast.TypeParameterDataType:
+		// Values of type parameters are only analyzed. The evaluator replaces type
+		// parameters with the type arguments before it creates values.
+		return &object.Interface{InterfaceType: rawType}
+	default:
+		return &object.Nil{}
+	}
+}
+
+func newStructObject(dType ast.DataType, structType *ast.StructDataType) *object.StructObject {
+	obj := &object.StructObject{
+		StructType: dType,
+		Fields:     make(map[string]*object.Object, len(structType.Fields)),
+	}
+
+	for _, field := range structType.Fields {
+		obj.SetField(field.Name, NativeTypeToDefaultObj(field.Type))
+	}
+
+	return obj
+}
+
+func analyzeInfixExpression(expr *ast.InfixExpression, env *object.Environment) (ast.DataType, []string) {
+	leftType, errors := AnalyzeExpression(expr.Left, env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	rightType, errors := AnalyzeExpression(expr.Right, env)
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	// Untyped operands of an operation on typed values take their type. Operations on
+	// untyped constants are folded exactly.
+	leftType, rightType = untypedOperandTypes(expr, leftType, rightType, env)
+	if !isUntypedConstant(expr, env) {
+		if isUntypedConstant(expr.Left, env) {
+			errors = append(errors, convertConstant(expr.Left, leftType, env)...)
+		}
+
+		if isUntypedConstant(expr.Right, env) {
+			errors = append(errors, convertConstant(expr.Right, rightType, env)...)
+		}
+	}
+
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	if expr.Operator == "==" || expr.Operator == "!=" {
+		if errors := checkComparable(expr, leftType, rightType); len(errors) != 0 {
+			return nil, errors
+		}
+	}
+
+	switch expr.Operator {
+	case "+":
+		return analyzePlusInfixOperator(leftType, rightType)
+	case "-":
+		return analyzeMinusInfixOperator(leftType, rightType)
+	case "*":
+		return analyzeAsteriksInfixOperator(leftType, rightType)
+	case "/":
+		return analyzeSlashInfixOperator(leftType, rightType)
+	case "==":
+		if isAssignable(leftType, rightType, env) || isAssignable(rightType, leftType, env) {
+			return parser.BOOLEAN, nil
+		}
+		return analyzeEqInfixOperator(leftType, rightType)
+	case "!=":
+		if isAssignable(leftType, rightType, env) || isAssignable(rightType, leftType, env) {
+			return parser.BOOLEAN, nil
+		}
+		return analyzeNeqInfixOperator(leftType, rightType)
+	case "<":
+		if leftType == parser.ANY || rightType == parser.ANY {
+			return parser.BOOLEAN, nil
+		}
+		return analyzeLtInfixOperator(leftType, rightType)
+	case ">":
+		if leftType == parser.ANY || rightType == parser.ANY {
+			return parser.BOOLEAN, nil
+		}
+		return analyzeGtInfixOperator(leftType, rightType)
+	case "<=", ">=":
+		if leftType == parser.ANY || rightType == parser.ANY {
+			return parser.BOOLEAN, nil
+		}
+		return analyzeOrderedInfixOperator(expr.Operator, leftType, rightType)
+	case "%":
+		return analyzePercentInfixOperator(leftType, rightType)
+	case "&&", "||":
+		return analyzeLogicalInfixOperator(expr.Operator, leftType, rightType)
+	case "&", "|", "^", "&^":
+		return analyzeBitwiseInfixOperator(expr.Operator, leftType, rightType)
+	case "<<", ">>":
+		return analyzeShiftInfixOperator(expr.Operator, leftType, rightType)
+	default:
+		msg := fmt.Sprintf("analyzer error. unsupported infix operator type %s", expr.Operator)
+		errors = append(errors, msg)
+		return nil, errors
+	}
+}
+
+// untypedOperandTypes gives an untyped constant operand the type of the other operand,
+// e.g. an integer literal the type of a float64 or a string literal that of a named
+// string type.
+func untypedOperandTypes(expr *ast.InfixExpression, leftType, rightType ast.DataType, env *object.Environment) (ast.DataType, ast.DataType) {
+	switch {
+	case expr.Operator == "<<" || expr.Operator == ">>":
+		return leftType, rightType
+	case isUntypedConstant(expr.Left, env) && isUntypedConstant(expr.Right, env):
+		// Untyped numeric operands take the kind that comes later in int, rune, float.
+		if isNumericType(leftType) && isNumericType(rightType) {
+			for _, kind := range []ast.DataType{parser.FLOAT64, parser.RUNE} {
+				if leftType == kind || rightType == kind {
+					return kind, kind
+				}
+			}
+		}
+
+		return leftType, rightType
+	case takesConstantType(leftType, rightType) && isUntypedConstant(expr.Right, env):
+		return leftType, leftType
+	case takesConstantType(rightType, leftType) && isUntypedConstant(expr.Left, env):
+		return rightType, rightType
+	default:
+		return leftType, rightType
+	}
+}
+
+// constantType returns the type of the value expr assigned to targetType. Untyped
+// constants take the target type, e.g. an integer constant assigned to a float64,
+// and must be representable by it.
+func constantType(targetType, valueType ast.DataType, expr ast.Expression, env *object.Environment) (ast.DataType, []string) {
+	targetTuple, ok := targetType.(*ast.TupleDataType)
+	valueTuple, ok2 := valueType.(*ast.TupleDataType)
+	tuple, ok3 := expr.(*ast.TupleExpression)
+	if ok && ok2 && ok3 && len(targetTuple.Types) == len(tuple.Values) && len(valueTuple.Types) == len(tuple.Values) {
+		types := make([]ast.DataType, len(tuple.Values))
+		for i, value := range tuple.Values {
+			var errors []string
+			types[i], errors = constantType(targetTuple.Types[i], valueTuple.Types[i], value, env)
+			if len(errors) != 0 {
+				return nil, errors
+			}
+		}
+
+		return &ast.TupleDataType{Types: types}, nil
+	}
+
+	if !isUntypedConstant(expr, env) {
+		return valueType, nil
+	}
+
+	if takesConstantType(targetType, valueType) {
+		return targetType, convertConstant(expr, targetType, env)
+	}
+
+	// Elsewhere, e.g. in an interface, constants take their default type.
+	return valueType, convertConstant(expr, valueType, env)
+}
+
+// takesConstantType reports whether an untyped constant of valueType may take
+// targetType.
+func takesConstantType(targetType, valueType ast.DataType) bool {
+	if typeParam, ok := ast.Underlying(targetType).(*ast.TypeParameterDataType); ok {
+		return typeSetAll(typeParam, func(dType ast.DataType) bool { return takesConstantType(dType, valueType) })
+	}
+
+	if isNumericType(targetType) && isNumericType(valueType) {
+		return true
+	}
+
+	return ast.Underlying(targetType) == valueType
+}
+
+// isUntypedConstant reports whether expr is an untyped constant: a literal, an untyped
+// named constant or an operation on untyped constants.
+func isUntypedConstant(expr ast.Expression, env *object.Environment) bool {
+	switch expr := expr.(type) {
+	case *ast.IntegerLiteral, *ast.RuneLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.Boolean:
+		return true
+	case *ast.Identifier:
+		obj, _ := env.Get(expr.Value)
+		constant, ok := obj.(*object.Constant)
+		return ok && constant.DataType == nil
+	case *ast.PrefixExpression:
+		switch expr.Operator {
+		case "-", "!", "^":
+			return isUntypedConstant(expr.Right, env)
+		}
+	case *ast.InfixExpression:
+		return isUntypedConstant(expr.Left, env) && isUntypedConstant(expr.Right, env)
+	}
+
+	return false
+}
+
+// isConstant reports whether expr is a constant, typed or untyped.
+func isConstant(expr ast.Expression, env *object.Environment) bool {
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		obj, _ := env.Get(expr.Value)
+		_, ok := obj.(*object.Constant)
+		return ok
+	case *ast.PrefixExpression:
+		switch expr.Operator {
+		case "-", "!", "^":
+			return isConstant(expr.Right, env)
+		}
+
+		return false
+	case *ast.InfixExpression:
+		return isConstant(expr.Left, env) && isConstant(expr.Right, env)
+	}
+
+	return isUntypedConstant(expr, env)
+}
+
+func analyzeConstStatement(stmt *ast.ConstStatement, env *object.Environment) []string {
+	var errors []string
+	for _, spec := range stmt.Specs {
+		errors = append(errors, analyzeConstSpec(spec, env)...)
+	}
+
+	return errors
+}
+
+// analyzeConstSpec checks the value of a constant and replaces it with the folded value,
+// so that the evaluator sees a literal.
+func analyzeConstSpec(spec *ast.ConstSpec, env *object.Environment) []string {
+	if spec.Name.DataType != nil {
+		if errors := resolveDataType(*spec.Name.DataType, env); len(errors) != 0 {
+			return errors
+		}
+	}
+
+	constEnv := object.NewEnclosedEnvironment(env)
+	constEnv.Set("iota", &object.Constant{Value: &object.UntypedInteger{Value: big.NewInt(spec.Iota)}})
+
+	valueType, errors := analyzeSingleValue(spec.Value, constEnv)
+	if len(errors) != 0 {
+		return errors
+	}
+
+	value, errors := foldConstant(spec.Value, constEnv)
+	if len(errors) != 0 {
+		return errors
+	}
+
+	var constType ast.DataType
+	switch {
+	case spec.Name.DataType != nil:
+		constType = *spec.Name.DataType
+
+		valueType, errors = constantType(constType, valueType, spec.Value, constEnv)
+		if len(errors) != 0 {
+			return errors
+		}
+
+		if !isAssignable(constType, valueType, env) {
+			return []string{typeMismatchError(constType, valueType, env)}
+		}
+
+		value, errors = representConstant(value, constType)
+		if len(errors) != 0 {
+			return errors
+		}
+
+		value = object.Convert(value, constType)
+	case !isUntypedConstant(spec.Value, constEnv):
+		// Operations on typed constants are typed.
+		constType = valueType
+		spec.Name.DataType = &constType
+	}
+
+	spec.Value = constantLiteral(value)
+	env.Set(spec.Name.Value, &object.Constant{Value: value, DataType: constType})
+
+	return nil
+}
+
+// constantLiteral returns the literal of a folded constant value.
+func constantLiteral(value object.Object) ast.Expression {
+	switch value := value.(type) {
+	case *object.UntypedInteger:
+		return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: value.Inspect()}, Value: value.Value.Int64()}
+	case *object.UntypedFloat:
+		// The literal keeps the exact value, as a fraction if float64 cannot hold it.
+		f, _ := value.Value.Float64()
+		literal := value.Value.RatString()
+		if exact := new(big.Rat).SetFloat64(f); exact != nil && exact.Cmp(value.Value) == 0 {
+			literal = strconv.FormatFloat(f, 'g', -1, 64)
+		}
+
+		return &ast.FloatLiteral{Token: token.Token{Type: token.FLOAT, Literal: literal}, Value: f}
+	case *object.Integer:
+		if value.IntegerType == parser.RUNE {
+			return &ast.RuneLiteral{Token: token.Token{Type: token.CHAR, Literal: string(rune(value.Value))}, Value: rune(value.Value)}
+		}
+
+		return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: value.Inspect()}, Value: value.Value}
+	case *object.Float:
+		return &ast.FloatLiteral{Token: token.Token{Type: token.FLOAT, Literal: value.Inspect()}, Value: value.Value}
+	case *object.String:
+		return &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: value.Value}, Value: value.Value}
+	default:
+		if value == object.TRUE {
+			return &ast.Boolean{Token: token.Token{Type: token.TRUE, Literal: "true"}, Value: true}
+		}
+
+		return &ast.Boolean{Token: token.Token{Type: token.FALSE, Literal: "false"}, Value: false}
+	}
+}
+
+// foldConstant evaluates the constant expression expr, whose types are already checked.
+// Untyped numeric constants are folded exactly.
+func foldConstant(expr ast.Expression, env *object.Environment) (object.Object, []string) {
+	switch expr := expr.(type) {
+	case *ast.IntegerLiteral:
+		value, ok := new(big.Int).SetString(expr.Token.Literal, 0)
+		if !ok {
+			value = big.NewInt(expr.Value)
+		}
+
+		return &object.UntypedInteger{Value: value}, nil
+	case *ast.RuneLiteral:
+		return object.NewInteger(int64(expr.Value), parser.RUNE), nil
+	case *ast.FloatLiteral:
+		value, ok := new(big.Rat).SetString(strings.ReplaceAll(expr.Token.Literal, "_", ""))
+		if !ok {
+			value = new(big.Rat).SetFloat64(expr.Value)
+		}
+
+		return &object.UntypedFloat{Value: value}, nil
+	case *ast.StringLiteral:
+		return &object.String{Value: expr.Value}, nil
+	case *ast.Boolean:
+		return object.NativeBoolean(expr.Value), nil
+	case *ast.Identifier:
+		obj, _ := env.Get(expr.Value)
+		if constant, ok := obj.(*object.Constant); ok {
+			return constant.Value, nil
+		}
+	case *ast.PrefixExpression:
+		right, errors := foldConstant(expr.Right, env)
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		return foldPrefixConstant(expr, right)
+	case *ast.InfixExpression:
+		left, errors := foldConstant(expr.Left, env)
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		right, errors := foldConstant(expr.Right, env)
+		if len(errors) != 0 {
+			return nil, errors
+		}
+
+		return foldInfixConstant(expr, left, right)
+	}
+
+	return nil, []string{fmt.Sprintf("Analyzer error. %s is not constant", expr.String())}
+}
+
+// ConstantValue returns the exact value of the literal the analyzer folded a constant
+// declaration to.
+func ConstantValue(literal ast.Expression) object.Object {
+	value, _ := foldConstant(literal, nil)
+	return value
+}
+
+func foldPrefixConstant(expr *ast.PrefixExpression, right object.Object) (object.Object, []string) {
+	switch right := right.(type) {
+	case *object.UntypedInteger:
+		switch expr.Operator {
+		case "-":
+			return &object.UntypedInteger{Value: new(big.Int).Neg(right.Value)}, nil
+		case "^":
+			return &object.UntypedInteger{Value: new(big.Int).Not(right.Value)}, nil
+		}
+	case *object.UntypedFloat:
+		if expr.Operator == "-" {
+			return &object.UntypedFloat{Value: new(big.Rat).Neg(right.Value)}, nil
+		}
+	case *object.Integer:
+		switch expr.Operator {
+		case "-":
+			return integerConstant(new(big.Int).Neg(big.NewInt(right.Value)), right.IntegerType)
+		case "^":
+			return object.NewInteger(^right.Value, right.IntegerType), nil
+		}
+	case *object.Float:
+		if expr.Operator == "-" {
+			return object.NewFloat(-right.Value, right.FloatType), nil
+		}
+	case *object.Boolean:
+		if expr.Operator == "!" {
+			return object.NativeBoolean(!right.Value), nil
+		}
+	}
+
+	return nil, []string{fmt.Sprintf("Analyzer error. %s is not constant", expr.String())}
+}
+
+func foldInfixConstant(expr *ast.InfixExpression, left, right object.Object) (object.Object, []string) {
+	if expr.Operator == "<<" || expr.Operator == ">>" {
+		return foldShiftConstant(expr, left, right)
+	}
+
+	// An untyped operand takes the type of a typed one.
+	var errors []string
+	switch {
+	case isUntypedNumber(left) && !isUntypedNumber(right):
+		left, errors = representConstant(left, right.Type())
+	case isUntypedNumber(right) && !isUntypedNumber(left):
+		right, errors = representConstant(right, left.Type())
+	}
+
+	if len(errors) != 0 {
+		return nil, errors
+	}
+
+	leftUntyped, leftIsUntyped := left.(*object.UntypedInteger)
+	rightUntyped, rightIsUntyped := right.(*object.UntypedInteger)
+	if leftIsUntyped && rightIsUntyped {
+		return foldUntypedInteger(expr, leftUntyped.Value, rightUntyped.Value)
+	}
+
+	if leftRat, ok := untypedRat(left); ok {
+		if rightRat, ok := untypedRat(right); ok {
+			return foldUntypedFloat(expr, leftRat, rightRat)
+		}
+	}
+
+	leftInt, leftIsInt := left.(*object.Integer)
+	rightInt, rightIsInt := right.(*object.Integer)
+	if leftIsInt && rightIsInt {
+		return foldIntegerConstant(expr, leftInt, rightInt)
+	}
+
+	leftFloat, leftIsFloat := left.(*object.Float)
+	rightFloat, rightIsFloat := right.(*object.Float)
+	if leftIsInt && rightIsFloat {
+		leftFloat, leftIsFloat = object.NewFloat(float64(leftInt.Value), rightFloat.FloatType), true
+	}
+
+	if rightIsInt && leftIsFloat {
+		rightFloat, rightIsFloat = object.NewFloat(float64(rightInt.Value), leftFloat.FloatType), true
+	}
+
+	if leftIsFloat && rightIsFloat {
+		return foldFloatConstant(expr, leftFloat, rightFloat)
+	}
+
+	if leftStr, ok := left.(*object.String); ok {
+		if rightStr, ok := right.(*object.String); ok {
+			if expr.Operator == "+" {
+				return &object.String{Value: leftStr.Value + rightStr.Value}, nil
+			}
+
+			if value, ok := compareConstants(expr.Operator, strings.Compare(leftStr.Value, rightStr.Value)); ok {
+				return value, nil
+			}
+		}
+	}
+
+	if leftBool, ok := left.(*object.Boolean); ok {
+		if rightBool, ok := right.(*object.Boolean); ok {
+			switch expr.Operator {
+			case "&&":
+				return object.NativeBoolean(leftBool.Value && rightBool.Value), nil
+			case "||":
+				return object.NativeBoolean(leftBool.Value || rightBool.Value), nil
+			case "==":
+				return object.NativeBoolean(leftBool.Value == rightBool.Value), nil
+			case "!=":
+				return object.NativeBoolean(leftBool.Value != rightBool.Value), nil
+			}
+		}
+	}
+
+	return nil, []string{fmt.Sprintf("Analyzer error. %s is not constant", expr.String())}
+}
+
+func isUntypedNumber(value object.Object) bool {
+	switch value.(type) {
+	case *object.UntypedInteger, *object.UntypedFloat:
+		return true
+	default:
+		return false
+	}
+}
+
+// untypedRat returns the value of an untyped numeric constant as a fraction.
+func untypedRat(value object.Object) (*big.Rat, bool) {
+	switch value := value.(type) {
+	case *object.UntypedInteger:
+		return new(big.Rat).SetInt(value.Value), true
+	case *object.UntypedFloat:
+		return value.Value, true
+	default:
+		return nil, false
+	}
+}
+
+func foldUntypedInteger(expr *ast.InfixExpression, x, y *big.Int) (object.Object, []string) {
+	z := new(big.Int)
+	switch expr.Operator {
+	case "+":
+		z.Add(x, y)
+	case "-":
+		z.Sub(x, y)
+	case "*":
+		z.Mul(x, y)
+	case "/", "%":
+		if y.Sign() == 0 {
+			return nil, []string{fmt.Sprintf("Analyzer error. invalid operation: division by zero in %s", expr.String())}
+		}
+
+		if expr.Operator == "/" {
+			z.Quo(x, y)
+		} else {
+			z.Rem(x, y)
+		}
+	case "&":
+		z.And(x, y)
+	case "|":
+		z.Or(x, y)
+	case "^":
+		z.Xor(x, y)
+	case "&^":
+		z.AndNot(x, y)
+	default:
+		if value, ok := compareConstants(expr.Operator, x.Cmp(y)); ok {
+			return value, nil
+		}
+
+		return nil, []string{fmt.Sprintf("Analyzer error. %s is not constant", expr.String())}
+	}
+
+	return &object.UntypedInteger{Value: z}, nil
+}
+
+func foldUntypedFloat(expr *ast.InfixExpression, x, y *big.Rat) (object.Object, []string) {
+	z := new(big.Rat)
+	switch expr.Operator {
+	case "+":
+		z.Add(x, y)
+	case "-":
+		z.Sub(x, y)
+	case "*":
+		z.Mul(x, y)
+	case "/":
+		if y.Sign() == 0 {
+			return nil, []string{fmt.Sprintf("Analyzer error. invalid operation: division by zero in %s", expr.String())}
+		}
+
+		z.Quo(x, y)
+	default:
+		if value, ok := compareConstants(expr.Operator, x.Cmp(y)); ok {
+			return value, nil
+		}
+
+		return nil, []string{fmt.Sprintf("Analyzer error. %s is not constant", expr.String())}
+	}
+
+	return &object.UntypedFloat{Value: z}, nil
+}
+
+func foldIntegerConstant(expr *ast.InfixExpression, left, right *object.Integer) (object.Object, []string) {
+	// Operations with a typed constant are of its type.
+	dType := left.IntegerType
+	if dType == nil {
+		dType = right.IntegerType
+	}
+
+	// Results are computed exactly, so that overflows can be reported.
+	a, b := left.Value, right.Value
+	x, y := big.NewInt(a), big.NewInt(b)
+	switch expr.Operator {
+	case "+":
+		return integerConstant(x.Add(x, y), dType)
+	case "-":
+		return integerConstant(x.Sub(x, y), dType)
+	case "*":
+		return integerConstant(x.Mul(x, y), dType)
+	case "/", "%":
+		if b == 0 {
+			return nil, []string{fmt.Sprintf("Analyzer error. invalid operation: division by zero in %s", expr.String())}
+		}
+
+		if expr.Operator == "/" {
+			return integerConstant(x.Quo(x, y), dType)
+		}
+
+		return integerConstant(x.Rem(x, y), dType)
+	case "&":
+		return object.NewInteger(a&b, dType), nil
+	case "|":
+		return object.NewInteger(a|b, dType), nil
+	case "^":
+		return object.NewInteger(a^b, dType), nil
+	case "&^":
+		return object.NewInteger(a&^b, dType), nil
+	}
+
+	if value, ok := compareConstants(expr.Operator, cmp.Compare(a, b)); ok {
+		return value, nil
+	}
+
+	return nil, []string{fmt.Sprintf("Analyzer error. %s is not constant", expr.String())}
+}
+
+// foldShiftConstant shifts the integer constant left by the count right. The result
+// has the type of left, and is untyped if left is.
+func foldShiftConstant(expr *ast.InfixExpression, left, right object.Object) (object.Object, []string) {
+	x, untyped := constantInteger(left)
+	count, _ := constantInteger(right)
+	if x == nil || count == nil {
+		return nil, []string{fmt.Sprintf("Analyzer error. invalid operation: shift of %s", expr.String())}
+	}
+
+	if count.Sign() < 0 {
+		return nil, []string{fmt.Sprintf("Analyzer error. invalid operation: negative shift count in %s", expr.String())}
+	}
+
+	var dType ast.DataType
+	if integer, ok := left.(*object.Integer); ok {
+		dType = integer.IntegerType
+	}
+
+	// The result of a huge shift is not computed, it overflows anyway.
+	const maxShift = 1 << 12
+	if count.Cmp(big.NewInt(maxShift)) > 0 {
+		if expr.Operator == "<<" && x.Sign() != 0 {
+			return nil, []string{fmt.Sprintf("Analyzer error. constant %s overflows %s", expr.String(), integerTypeName(dType))}
+		}
+
+		count = big.NewInt(maxShift)
+	}
+
+	z := new(big.Int)
+	if expr.Operator == ">>" {
+		z.Rsh(x, uint(count.Uint64()))
+	} else {
+		z.Lsh(x, uint(count.Uint64()))
+	}
+
+	if untyped {
+		return &object.UntypedInteger{Value: z}, nil
+	}
+
+	return integerConstant(z, dType)
+}
+
+// constantInteger returns the value of an integer constant, or of an untyped float
+// constant that is whole, and whether the constant is untyped.
+func constantInteger(value object.Object) (*big.Int, bool) {
+	switch value := value.(type) {
+	case *object.UntypedInteger:
+		return value.Value, true
+	case *object.UntypedFloat:
+		if value.Value.IsInt() {
+			return value.Value.Num(), true
+		}
+	case *object.Integer:
+		return big.NewInt(value.Value), false
+	}
+
+	return nil, false
+}
+
+// integerConstant returns the constant value of type dType, which is nil for int.
+func integerConstant(value *big.Int, dType ast.DataType) (object.Object, []string) {
+	if !value.IsInt64() || !fitsInteger(value.Int64(), dType) {
+		return nil, []string{fmt.Sprintf("Analyzer error. constant %s overflows %s", value, integerTypeName(dType))}
+	}
+
+	return object.NewInteger(value.Int64(), dType), nil
+}
+
+// fitsInteger reports whether value is representable by the integer type dType.
+func fitsInteger(value int64, dType ast.DataType) bool {
+	switch ast.Underlying(dType).(type) {
+	case *ast.ByteDataType:
+		return value >= 0 && value <= math.MaxUint8
+	case *ast.RuneDataType:
+		return value >= math.MinInt32 && value <= math.MaxInt32
+	default:
+		return true
+	}
+}
+
+func integerTypeName(dType ast.DataType) string {
+	if dType == nil {
+		return parser.INT.Name()
+	}
+
+	return dType.Name()
+}
+
+// floatConstant returns the constant value of type dType, which is nil for float64.
+func floatConstant(value *big.Rat, dType ast.DataType) (object.Object, []string) {
+	f, _ := value.Float64()
+	if math.IsInf(f, 0) {
+		name := parser.FLOAT64.Name()
+		if dType != nil {
+			name = dType.Name()
+		}
+
+		return nil, []string{fmt.Sprintf("Analyzer error. constant %s overflows %s", value.FloatString(0), name)}
+	}
+
+	return object.NewFloat(f, dType), nil
+}
+
+// representConstant returns the constant value converted to dType, the type it takes.
+// Numeric constants must be representable by it: integers must fit and untyped floats
+// converted to integers must be whole. Untyped constants stored in other types, e.g.
+// interfaces, take their default type.
+func representConstant(value object.Object, dType ast.DataType) (object.Object, []string) {
+	var floatType, integerType ast.DataType
+	switch {
+	case isFloatType(dType):
+		floatType = dType
+	case isIntegerType(dType):
+		integerType = dType
+	}
+
+	switch value := value.(type) {
+	case *object.UntypedInteger:
+		if floatType != nil {
+			return floatConstant(new(big.Rat).SetInt(value.Value), floatType)
+		}
+
+		return integerConstant(value.Value, integerType)
+	case *object.UntypedFloat:
+		if integerType == nil {
+			return floatConstant(value.Value, floatType)
+		}
+
+		if !value.Value.IsInt() {
+			return nil, []string{fmt.Sprintf("Analyzer error. constant %s truncated to integer", value.Inspect())}
+		}
+
+		return integerConstant(value.Value.Num(), integerType)
+	case *object.Integer:
+		if floatType != nil {
+			return object.NewFloat(float64(value.Value), floatType), nil
+		}
 
-	if identType != parser.ANY && identType.Name() != exprType.Name() {
-		msg := fmt.Sprintf("Analyzer error. type mismatch. expected %s, got %s", identType.Name(), exprType.Name())
-		errors = append(errors, msg)
-	}
+		if integerType != nil {
+			if !fitsInteger(value.Value, integerType) {
+				return nil, []string{fmt.Sprintf("Analyzer error. constant %d overflows %s", value.Value, integerType.Name())}
+			}
 
-	env.Set(stmt.Name.Value, NativeTypeToDefaultObj(identType))
+			return object.NewInteger(value.Value, integerType), nil
+		}
+	}
 
-	return errors
+	return value, nil
 }
 
-func analyzeReturnStatement(stmt *ast.ReturnStatement, returnType ast.DataType, env *object.Environment) []string {
-	stmtReturnType, errors := AnalyzeExpression(stmt.ReturnValue, env)
+// convertConstant converts the constant expr to dType, the type it takes. The value of
+// an untyped constant is recorded for the evaluator in that type, so that e.g. 2.0
+// assigned to an int is an integer.
+func convertConstant(expr ast.Expression, dType ast.DataType, env *object.Environment) []string {
+	value, errors := foldConstant(expr, env)
 	if len(errors) != 0 {
 		return errors
 	}
 
-	if returnType != parser.ANY && stmtReturnType.Name() != returnType.Name() {
-		msg := fmt.Sprintf("analyzer error. function returns %s, got=%s", returnType.Name(), stmtReturnType.Name())
-		errors = append(errors, msg)
+	value, errors = representConstant(value, dType)
+	if len(errors) != 0 {
+		return errors
 	}
 
-	return errors
-}
+	// Values of type parameters only get their type when the function is called.
+	if _, ok := ast.Underlying(dType).(*ast.TypeParameterDataType); !ok && isUntypedConstant(expr, env) {
+		setConstant(expr, value)
+	}
 
-func analyzeExpressionStatement(expr *ast.ExpressionStatement, env *object.Environment) []string {
-	_, errors := AnalyzeExpression(expr.Expression, env)
-	return errors
+	return nil
 }
 
-func AnalyzeExpression(expr ast.Expression, env *object.Environment) (ast.DataType, []string) {
-	var errors []string
+// setConstant records the folded value of the constant expression expr.
+func setConstant(expr ast.Expression, value object.Object) {
 	switch expr := expr.(type) {
-	case *ast.IntegerLiteral:
-		return parser.INT, errors
-	case *ast.Boolean:
-		return parser.BOOLEAN, errors
-	case *ast.BashExpression:
-		return parser.STRING, errors
 	case *ast.Identifier:
-		obj, ok := env.Get(expr.Value)
-		if ok {
-			return obj.Type(), nil
-		}
-
-		fnObj, ok := object.Builtins[expr.Value]
-		if ok {
-			return fnObj.Type(), nil
-		}
-
-		msg := fmt.Sprintf("analyzer error. unknown identifier %s", expr.Value)
-		errors = append(errors, msg)
-		return nil, errors
-	case *ast.CallExpression:
-		return analyzeCallExpression(expr, env)
-	case *ast.IndexExpression:
-		return analyzeIndexExpression(expr, env)
-	case *ast.ReadChanExpression:
-		obj, ok := env.Get(expr.Source.Value)
-		if !ok {
-			msg := fmt.Sprintf("analyzer error. unknown identifier %s", expr.Source.Value)
-			errors = append(errors, msg)
-			return nil, errors
-		}
-
-		if chn, ok := obj.(*object.ChanObject); !ok {
-			msg := fmt.Sprintf("analyzer error. expected identifier to be type *object.ChanObject, got %s", obj)
-			errors = append(errors, msg)
-			return nil, errors
-		} else {
-			return chn.ChanType, nil
-		}
+		expr.Constant = constantLiteral(value)
+	case *ast.FloatLiteral:
+		expr.Constant = constantLiteral(value)
 	case *ast.PrefixExpression:
-		return analyzePrefixExpression(expr, env)
-	case *ast.StringLiteral:
-		return parser.STRING, errors
+		expr.Constant = constantLiteral(value)
 	case *ast.InfixExpression:
-		return analyzeInfixExpression(expr, env)
-	case *ast.BashVarExpression:
-		return parser.STRING, errors
-	case *ast.FunctionLiteral:
-		return analyzeFunctionLiteral(expr, env)
-	default:
-		msg := fmt.Sprintf("analyzer error. unexpected expression type %T", expr)
-		errors = append(errors, msg)
-		return nil, errors
+		expr.Constant = constantLiteral(value)
 	}
 }
 
-func analyzeIndexExpression(expr *ast.IndexExpression, env *object.Environment) (ast.DataType, []string) {
-	lType, errors := AnalyzeExpression(expr.Left, env)
-	if len(errors) > 0 {
-		return nil, errors
-	}
-
-	sliceType, ok := lType.(*ast.SliceDataType)
-	if !ok {
-		return nil, []string{fmt.Sprintf("Analyzer error. expected slice type for index expression, got=%T", lType)}
+// checkConstantExpression folds expr if it is a constant expression, so that
+// overflows and divisions by zero are reported. An untyped constant is evaluated in its
+// default type, unless it is converted to another type.
+func checkConstantExpression(expr ast.Expression, env *object.Environment) []string {
+	if !isConstant(expr, env) {
+		return nil
 	}
 
-	indexType, errors := AnalyzeExpression(expr.Index, env)
-	if len(errors) > 0 {
-		return nil, errors
+	value, errors := foldConstant(expr, env)
+	if len(errors) != 0 || !isUntypedConstant(expr, env) {
+		return errors
 	}
 
-	_, ok = indexType.(*ast.IntegerDataType)
-	if !ok {
-		return nil, []string{fmt.Sprintf("Analyzer error. expected integer type for index expression, got=%T", lType)}
+	if value, errors := representConstant(value, value.Type()); len(errors) == 0 {
+		setConstant(expr, value)
 	}
 
-	return sliceType.Type, nil
+	return nil
 }
 
-func analyzeIfStatement(expr *ast.IfStatement, returnType ast.DataType, env *object.Environment) []string {
-	conditionType, errors := AnalyzeExpression(expr.Condition, env)
-	if len(errors) != 0 {
-		return errors
+func foldFloatConstant(expr *ast.InfixExpression, left, right *object.Float) (object.Object, []string) {
+	dType := left.FloatType
+	if dType == nil {
+		dType = right.FloatType
 	}
 
-	if conditionType != parser.BOOLEAN {
-		msg := fmt.Sprintf("Analyzer error. expected boolean type for if expression, got %s", conditionType.Name())
-		return []string{msg}
+	a, b := left.Value, right.Value
+	switch expr.Operator {
+	case "+":
+		return object.NewFloat(a+b, dType), nil
+	case "-":
+		return object.NewFloat(a-b, dType), nil
+	case "*":
+		return object.NewFloat(a*b, dType), nil
+	case "/":
+		if b == 0 {
+			return nil, []string{fmt.Sprintf("Analyzer error. invalid operation: division by zero in %s", expr.String())}
+		}
+
+		return object.NewFloat(a/b, dType), nil
 	}
 
-	errors = append(errors, analyzeBlockStatement(expr.Consequence, returnType, env)...)
-	if expr.Alternative != nil {
-		errors = append(errors, analyzeBlockStatement(expr.Alternative, returnType, env)...)
+	if value, ok := compareConstants(expr.Operator, cmp.Compare(a, b)); ok {
+		return value, nil
 	}
-	return errors
+
+	return nil, []string{fmt.Sprintf("Analyzer error. %s is not constant", expr.String())}
 }
 
-func analyzeCallExpression(expr *ast.CallExpression, env *object.Environment) (ast.DataType, []string) {
-	dType, errors := AnalyzeExpression(expr.Function, env)
-	if len(errors) != 0 {
-		return nil, errors
+// compareConstants returns the result of the comparison operator given the ordering
+// of its operands, or false if operator is not a comparison.
+func compareConstants(operator string, order int) (object.Object, bool) {
+	switch operator {
+	case "==":
+		return object.NativeBoolean(order == 0), true
+	case "!=":
+		return object.NativeBoolean(order != 0), true
+	case "<":
+		return object.NativeBoolean(order < 0), true
+	case ">":
+		return object.NativeBoolean(order > 0), true
+	case "<=":
+		return object.NativeBoolean(order <= 0), true
+	case ">=":
+		return object.NativeBoolean(order >= 0), true
+	default:
+		return nil, false
 	}
+}
 
-	switch fnType := dType.(type) {
-	case *ast.BuiltinDataType:
-		return parser.ANY, nil
-	case *ast.FunctionDataType:
-		if len(expr.Arguments) != len(fnType.Parameters) {
-			errors = append(errors, fmt.Sprintf("analyzer error. Incorrect parameter count. expected %d, got=%d", len(fnType.Parameters), len(expr.Arguments)))
-			return nil, errors
-		}
-		for i, param := range expr.Arguments {
-			var arg ast.DataType
-			arg, tempErrors := AnalyzeExpression(param, env)
-			if len(tempErrors) != 0 {
-				return nil, append(errors, tempErrors...)
-			}
+// The type predicates hold for a type parameter if they hold for all types in its
+// type set.
 
-			if fnType.Parameters[i].Name() != arg.Name() {
-				msg := fmt.Sprintf("analyzer error. Incorrect type passed into function. expected %s, got=%s", fnType.Name(), arg.Name())
-				errors = append(errors, msg)
-			}
-		}
+func isIntegerType(dType ast.DataType) bool {
+	switch dType := ast.Underlying(dType).(type) {
+	case *ast.IntegerDataType, *ast.ByteDataType, *ast.RuneDataType:
+		return true
+	case *ast.TypeParameterDataType:
+		return typeSetAll(dType, isIntegerType)
+	default:
+		return false
+	}
+}
 
-		if len(errors) != 0 {
-			return nil, errors
-		}
+func isStringType(dType ast.DataType) bool {
+	if typeParam, ok := ast.Underlying(dType).(*ast.TypeParameterDataType); ok {
+		return typeSetAll(typeParam, isStringType)
+	}
 
-		return fnType.ReturnType, nil
+	return ast.Underlying(dType) == parser.STRING
+}
+
+func isFloatType(dType ast.DataType) bool {
+	switch dType := ast.Underlying(dType).(type) {
+	case *ast.FloatDataType:
+		return true
+	case *ast.TypeParameterDataType:
+		return typeSetAll(dType, isFloatType)
 	default:
-		msg := fmt.Sprintf("Analyzer error. Unsupported call type %T", dType)
-		return nil, []string{msg}
+		return false
 	}
 }
 
-func analyzeFunctionLiteral(expr *ast.FunctionLiteral, env *object.Environment) (ast.DataType, []string) {
-	env = object.NewEnclosedEnvironment(env)
-	fn := &ast.FunctionDataType{
-		ReturnType: expr.ReturnType,
+func isNumericType(dType ast.DataType) bool {
+	switch dType := ast.Underlying(dType).(type) {
+	case *ast.FloatDataType:
+		return true
+	case *ast.TypeParameterDataType:
+		return typeSetAll(dType, isNumericType)
+	default:
+		return isIntegerType(dType)
 	}
+}
 
-	for _, ident := range expr.Parameters {
-		env.Set(ident.Value, NativeTypeToDefaultObj(*ident.DataType))
-		fn.Parameters = append(fn.Parameters, *ident.DataType)
+// isOrderedType reports whether values of dType may be ordered with < and added with +.
+func isOrderedType(dType ast.DataType) bool {
+	if typeParam, ok := ast.Underlying(dType).(*ast.TypeParameterDataType); ok {
+		return typeSetAll(typeParam, isOrderedType)
 	}
 
-	errors := analyzeBlockStatement(expr.Body, expr.ReturnType, env)
-	return fn, errors
+	return isNumericType(dType) || isStringType(dType)
 }
 
-func NativeTypeToDefaultObj(rawType ast.DataType) object.Object {
-	switch rawType := rawType.(type) {
-	case *ast.IntegerDataType:
-		return &object.Integer{}
-	case *ast.BooleanDataType:
-		return &object.Boolean{}
-	case *ast.StringDataType:
-		return &object.String{}
-	case *ast.SliceDataType:
-		return &object.SliceObject{ValueType: rawType.Type}
-	case *ast.AnyDataType:
-		return &object.Any{}
-	case *ast.ReferenceDataType:
-		val := NativeTypeToDefaultObj(rawType.ValueType)
-		return &object.ReferenceObject{Value: &val}
-	case *ast.ChanDataType:
-		return &object.ChanObject{
-			Chan:     nil,
-			ChanType: rawType.ValueType,
-		}
+func analyzeOrderedInfixOperator(operator string, leftType, rightType ast.DataType) (ast.DataType, []string) {
+	switch {
+	case isOrderedType(leftType) && ast.Identical(leftType, rightType):
+		return parser.BOOLEAN, nil
 	default:
-		return &object.Nil{}
+		msg := fmt.Sprintf("analyzer error. unsupported expression type for '%s' operator: %s and %s", operator, leftType.Name(), rightType.Name())
+		return nil, []string{msg}
 	}
 }
 
-func analyzeInfixExpression(expr *ast.InfixExpression, env *object.Environment) (ast.DataType, []string) {
-	leftType, errors := AnalyzeExpression(expr.Left, env)
-	if len(errors) != 0 {
-		return nil, errors
+func analyzeLogicalInfixOperator(operator string, leftType, rightType ast.DataType) (ast.DataType, []string) {
+	switch {
+	case ast.Underlying(leftType) == parser.BOOLEAN && ast.Identical(leftType, rightType):
+		return leftType, nil
+	default:
+		msg := fmt.Sprintf("analyzer error. unsupported expression type for '%s' operator: %s and %s", operator, leftType.Name(), rightType.Name())
+		return nil, []string{msg}
 	}
+}
 
-	rightType, errors := AnalyzeExpression(expr.Right, env)
-	if len(errors) != 0 {
-		return nil, errors
+func analyzeBitwiseInfixOperator(operator string, leftType, rightType ast.DataType) (ast.DataType, []string) {
+	switch {
+	case isIntegerType(leftType) && ast.Identical(leftType, rightType):
+		return leftType, nil
+	default:
+		msg := fmt.Sprintf("analyzer error. unsupported expression type for '%s' operator: %s and %s", operator, leftType.Name(), rightType.Name())
+		return nil, []string{msg}
 	}
+}
 
-	switch expr.Operator {
-	case "+":
-		return analyzePlusInfixOperator(leftType, rightType)
-	case "-":
-		return analyzeMinusInfixOperator(leftType, rightType)
-	case "*":
-		return analyzeAsteriksInfixOperator(leftType, rightType)
-	case "/":
-		return analyzeSlashInfixOperator(leftType, rightType)
-	case "==":
-		if leftType == parser.ANY || rightType == parser.ANY {
-			return parser.BOOLEAN, nil
-		}
-		return analyzeEqInfixOperator(leftType, rightType)
-	case "!=":
-		if leftType == parser.ANY || rightType == parser.ANY {
-			return parser.BOOLEAN, nil
-		}
-		return analyzeNeqInfixOperator(leftType, rightType)
-	case "<":
-		if leftType == parser.ANY || rightType == parser.ANY {
-			return parser.BOOLEAN, nil
-		}
-		return analyzeLtInfixOperator(leftType, rightType)
-	case ">":
-		if leftType == parser.ANY || rightType == parser.ANY {
-			return parser.BOOLEAN, nil
-		}
-		return analyzeGtInfixOperator(leftType, rightType)
-	case "%":
-		return analyzePercentInfixOperator(leftType, rightType)
+// analyzeShiftInfixOperator checks x << n and x >> n. The result has the type of x
+// and n may be of any integer type.
+func analyzeShiftInfixOperator(operator string, leftType, rightType ast.DataType) (ast.DataType, []string) {
+	switch {
+	case isIntegerType(leftType) && isIntegerType(rightType):
+		return leftType, nil
 	default:
-		msg := fmt.Sprintf("analyzer error. unsupported infix operator type %s", expr.Operator)
-		errors = append(errors, msg)
-		return nil, errors
+		msg := fmt.Sprintf("analyzer error. unsupported expression type for '%s' operator: %s and %s", operator, leftType.Name(), rightType.Name())
+		return nil, []string{msg}
 	}
 }
 
 func analyzePercentInfixOperator(leftType ast.DataType, rightType ast.DataType) (ast.DataType, []string) {
 	switch {
-	case leftType.Name() == parser.INT.Name() && rightType.Name() == parser.INT.Name():
-		return parser.INT, nil
+	case isIntegerType(leftType) && ast.Identical(leftType, rightType):
+		return leftType, nil
 	default:
 		msg := fmt.Sprintf("analyzer error. unsupported expression for 'percent' operator: %s and %s", leftType.Name(), rightType.Name())
 		errors := []string{msg}
@@ -418,7 +3480,7 @@ func analyzePercentInfixOperator(leftType ast.DataType, rightType ast.DataType)
 }
 
 func analyzeNeqInfixOperator(leftType, rightType ast.DataType) (ast.DataType, []string) {
-	if leftType == rightType {
+	if ast.Identical(leftType, rightType) {
 		return parser.BOOLEAN, nil
 	} else {
 		msg := fmt.Sprintf("analyzer error. unsupported comparison for '==' operator: %s and %s", leftType.Name(), rightType.Name())
@@ -428,7 +3490,7 @@ func analyzeNeqInfixOperator(leftType, rightType ast.DataType) (ast.DataType, []
 }
 
 func analyzeEqInfixOperator(leftType, rightType ast.DataType) (ast.DataType, []string) {
-	if leftType == rightType {
+	if ast.Identical(leftType, rightType) {
 		return parser.BOOLEAN, nil
 	} else {
 		msg := fmt.Sprintf("analyzer error. unsupported comparison for '==' operator: %s and %s", leftType.Name(), rightType.Name())
@@ -439,7 +3501,7 @@ func analyzeEqInfixOperator(leftType, rightType ast.DataType) (ast.DataType, []s
 
 func analyzeLtInfixOperator(leftType, rightType ast.DataType) (ast.DataType, []string) {
 	switch {
-	case leftType == parser.INT && rightType == parser.INT:
+	case isOrderedType(leftType) && ast.Identical(leftType, rightType):
 		return parser.BOOLEAN, nil
 	default:
 		msg := fmt.Sprintf("analyzer error. unsupported expression type for '<' operator: %s and %s", leftType.Name(), rightType.Name())
@@ -450,7 +3512,7 @@ func analyzeLtInfixOperator(leftType, rightType ast.DataType) (ast.DataType, []s
 
 func analyzeGtInfixOperator(leftType, rightType ast.DataType) (ast.DataType, []string) {
 	switch {
-	case leftType == parser.INT && rightType == parser.INT:
+	case isOrderedType(leftType) && ast.Identical(leftType, rightType):
 		return parser.BOOLEAN, nil
 	default:
 		msg := fmt.Sprintf("analyzer error. unsupported expression type for '>' operator %s", rightType.Name())
@@ -461,8 +3523,8 @@ func analyzeGtInfixOperator(leftType, rightType ast.DataType) (ast.DataType, []s
 
 func analyzeAsteriksInfixOperator(leftType, rightType ast.DataType) (ast.DataType, []string) {
 	switch {
-	case leftType == parser.INT && rightType == parser.INT:
-		return parser.INT, nil
+	case isNumericType(leftType) && ast.Identical(leftType, rightType):
+		return leftType, nil
 	default:
 		msg := fmt.Sprintf("analyzer error. unsupported expression type for '*' operator %s", rightType.Name())
 		errors := []string{msg}
@@ -472,8 +3534,8 @@ func analyzeAsteriksInfixOperator(leftType, rightType ast.DataType) (ast.DataTyp
 
 func analyzeSlashInfixOperator(leftType, rightType ast.DataType) (ast.DataType, []string) {
 	switch {
-	case leftType == parser.INT && rightType == parser.INT:
-		return parser.INT, nil
+	case isNumericType(leftType) && ast.Identical(leftType, rightType):
+		return leftType, nil
 	default:
 		msg := fmt.Sprintf("analyzer error. unsupported expression type for '/' operator %s", rightType.Name())
 		errors := []string{msg}
@@ -483,8 +3545,8 @@ func analyzeSlashInfixOperator(leftType, rightType ast.DataType) (ast.DataType,
 
 func analyzeMinusInfixOperator(leftType, rightType ast.DataType) (ast.DataType, []string) {
 	switch {
-	case leftType == parser.INT && rightType == parser.INT:
-		return parser.INT, nil
+	case isNumericType(leftType) && ast.Identical(leftType, rightType):
+		return leftType, nil
 	default:
 		msg := fmt.Sprintf("analyzer error. unsupported expression type for '-' operator %s", rightType.Name())
 		errors := []string{msg}
@@ -494,10 +3556,8 @@ func analyzeMinusInfixOperator(leftType, rightType ast.DataType) (ast.DataType,
 
 func analyzePlusInfixOperator(leftType, rightType ast.DataType) (ast.DataType, []string) {
 	switch {
-	case leftType == parser.INT && rightType == parser.INT:
-		return parser.INT, nil
-	case leftType == parser.STRING && rightType == parser.STRING:
-		return parser.STRING, nil
+	case isOrderedType(leftType) && ast.Identical(leftType, rightType):
+		return leftType, nil
 	default:
 		msg := fmt.Sprintf("analyzer error. unsupported expression type for '+' operator %s", rightType.Name())
 		errors := []string{msg}
@@ -516,11 +3576,17 @@ func analyzePrefixExpression(expr *ast.PrefixExpression, env *object.Environment
 		return analyzeBangPrefixOperator(rightType)
 	case "-":
 		return analyzeMinusPrefixOperator(rightType)
+	case "^":
+		return analyzeXorPrefixOperator(rightType)
 	case "-f":
 		return analyzeFoperPrefixExpression(rightType)
 	case "*":
 		return analyzeAsteriksPrefixExpression(rightType)
 	case "&":
+		if errors := analyzeAddressOperand(expr.Right, env); len(errors) != 0 {
+			return nil, errors
+		}
+
 		return analyzeRefPrefixExpression(rightType)
 	default:
 		msg := fmt.Sprintf("analyzer error. unsupportet prefix operator type %s", expr.Operator)
@@ -529,6 +3595,53 @@ func analyzePrefixExpression(expr *ast.PrefixExpression, env *object.Environment
 	}
 }
 
+// isAddressable reports whether expr denotes a variable: a variable name, a slice
+// element, a pointer indirection, or a field of an addressable struct or of a struct
+// a pointer points to.
+func isAddressable(expr ast.Expression, env *object.Environment) bool {
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		obj, ok := env.Get(expr.Value)
+		_, isConstant := obj.(*object.Constant)
+		return ok && !isConstant
+	case *ast.IndexExpression:
+		lType, _ := AnalyzeExpression(expr.Left, env)
+		_, ok := ast.Underlying(lType).(*ast.SliceDataType)
+		return ok
+	case *ast.PrefixExpression:
+		return expr.Operator == "*"
+	case *ast.SelectorExpression:
+		lType, _ := AnalyzeExpression(expr.Left, env)
+		if _, ok := ast.Underlying(lType).(*ast.ReferenceDataType); ok {
+			return true
+		}
+
+		return isAddressable(expr.Left, env)
+	default:
+		return false
+	}
+}
+
+func isMapType(dType ast.DataType) bool {
+	_, ok := ast.Underlying(dType).(*ast.MapDataType)
+	return ok
+}
+
+// analyzeAddressOperand checks that the address of expr can be taken. Variables and
+// composite literals are addressable.
+func analyzeAddressOperand(expr ast.Expression, env *object.Environment) []string {
+	switch expr.(type) {
+	case *ast.StructLiteral, *ast.SliceLiteral, *ast.MapLiteral:
+		return nil
+	}
+
+	if isAddressable(expr, env) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("analyzer error. invalid operation: cannot take address of %s", expr.String())}
+}
+
 func analyzeRefPrefixExpression(rightType ast.DataType) (ast.DataType, []string) {
 	return &ast.ReferenceDataType{
 		ValueType: rightType,
@@ -557,7 +3670,7 @@ func analyzeFoperPrefixExpression(rightType ast.DataType) (ast.DataType, []strin
 }
 
 func analyzeMinusPrefixOperator(rightType ast.DataType) (ast.DataType, []string) {
-	if rightType == parser.INT {
+	if isNumericType(rightType) {
 		return rightType, nil
 	} else {
 		msg := fmt.Sprintf("analyzer error. unsupported expression type for '-' operator %s", rightType.Name())
@@ -566,6 +3679,16 @@ func analyzeMinusPrefixOperator(rightType ast.DataType) (ast.DataType, []string)
 	}
 }
 
+func analyzeXorPrefixOperator(rightType ast.DataType) (ast.DataType, []string) {
+	if isIntegerType(rightType) {
+		return rightType, nil
+	} else {
+		msg := fmt.Sprintf("analyzer error. unsupported expression type for '^' operator %s", rightType.Name())
+		errors := []string{msg}
+		return nil, errors
+	}
+}
+
 func analyzeBangPrefixOperator(rightType ast.DataType) (ast.DataType, []string) {
 	if rightType == parser.BOOLEAN {
 		return rightType, nil
@@ -2,13 +2,17 @@ package ast
 
 import (
 	"bytes"
+	"strings"
+
 	"kstmc.com/gosha/internal/token"
 )
 
+// AssignStatement assigns to existing variables, slice and map elements, struct fields
+// and pointer indirections, e.g. 'xs[i], *p = v, w'.
 type AssignStatement struct {
-	Token token.Token
-	Name  *Identifier
-	Value Expression
+	Token   token.Token
+	Targets []Expression
+	Value   Expression
 }
 
 func (as *AssignStatement) statementNode() {
@@ -22,7 +26,12 @@ func (as *AssignStatement) TokenLiteral() string {
 func (as *AssignStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(as.Name.String() + " ")
+	var targets []string
+	for _, target := range as.Targets {
+		targets = append(targets, target.String())
+	}
+
+	out.WriteString(strings.Join(targets, ", ") + " ")
 	out.WriteString(as.TokenLiteral() + " ")
 
 	if as.Value != nil {
@@ -13,12 +13,14 @@ func TestString(t *testing.T) {
 					Type:    token.INITASSIGN,
 					Literal: ":=",
 				},
-				Name: &Identifier{
-					Token: token.Token{
-						Type:    token.IDENT,
-						Literal: "goyda",
+				Targets: []Expression{
+					&Identifier{
+						Token: token.Token{
+							Type:    token.IDENT,
+							Literal: "goyda",
+						},
+						Value: "goyda",
 					},
-					Value: "goyda",
 				},
 				Value: &Identifier{
 					Token: token.Token{
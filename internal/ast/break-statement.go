@@ -4,6 +4,7 @@ import "kstmc.com/gosha/internal/token"
 
 type BreakStatement struct {
 	Token token.Token
+	Label *Identifier
 }
 
 func (bs *BreakStatement) statementNode() {
@@ -15,5 +16,9 @@ func (bs *BreakStatement) TokenLiteral() string {
 }
 
 func (bs *BreakStatement) String() string {
+	if bs.Label != nil {
+		return bs.Token.Literal + " " + bs.Label.String()
+	}
+
 	return bs.Token.Literal
 }
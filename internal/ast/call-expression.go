@@ -10,6 +10,11 @@ type CallExpression struct {
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
+	// Ellipsis is set if the last argument is a slice spread with '...'.
+	Ellipsis bool
+	// TypeArgs are the type arguments inferred by the analyzer for a call of a
+	// generic function.
+	TypeArgs []DataType
 }
 
 func (ce *CallExpression) expressionNode() {
@@ -31,6 +36,10 @@ func (ce *CallExpression) String() string {
 	out.WriteString(ce.Function.String())
 	out.WriteString("(")
 	out.WriteString(strings.Join(args, ", "))
+	if ce.Ellipsis {
+		out.WriteString("...")
+	}
+
 	out.WriteString(")")
 
 	return out.String()
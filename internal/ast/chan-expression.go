@@ -24,7 +24,7 @@ func (sce *SendChanStatement) String() string {
 
 type ReadChanExpression struct {
 	Token  token.Token
-	Source *Identifier
+	Source Expression
 }
 
 func (rce *ReadChanExpression) expressionNode() {
@@ -0,0 +1,59 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// ConstSpec declares a single constant. Iota is the index of the spec in its
+// declaration.
+type ConstSpec struct {
+	Name  *Identifier
+	Value Expression
+	Iota  int64
+}
+
+func (cs *ConstSpec) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(cs.Name.String())
+	if cs.Name.DataType != nil {
+		out.WriteString(" " + (*cs.Name.DataType).Name())
+	}
+
+	out.WriteString(" = ")
+	if cs.Value != nil {
+		out.WriteString(cs.Value.String())
+	}
+
+	return out.String()
+}
+
+// ConstStatement declares constants, e.g. 'const X = 5' or 'const ( A = iota; B )'.
+type ConstStatement struct {
+	Token token.Token
+	Specs []*ConstSpec
+}
+
+func (cs *ConstStatement) statementNode() {
+
+}
+
+func (cs *ConstStatement) TokenLiteral() string {
+	return cs.Token.Literal
+}
+
+func (cs *ConstStatement) String() string {
+	if len(cs.Specs) == 1 {
+		return cs.TokenLiteral() + " " + cs.Specs[0].String()
+	}
+
+	var specs []string
+	for _, spec := range cs.Specs {
+		specs = append(specs, spec.String())
+	}
+
+	return cs.TokenLiteral() + " (" + strings.Join(specs, "; ") + ")"
+}
@@ -0,0 +1,24 @@
+package ast
+
+import "kstmc.com/gosha/internal/token"
+
+type ContinueStatement struct {
+	Token token.Token
+	Label *Identifier
+}
+
+func (cs *ContinueStatement) statementNode() {
+
+}
+
+func (cs *ContinueStatement) TokenLiteral() string {
+	return cs.Token.Literal
+}
+
+func (cs *ContinueStatement) String() string {
+	if cs.Label != nil {
+		return cs.Token.Literal + " " + cs.Label.String()
+	}
+
+	return cs.Token.Literal
+}
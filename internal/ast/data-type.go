@@ -3,6 +3,8 @@ package ast
 import (
 	"bytes"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"kstmc.com/gosha/internal/token"
 )
@@ -35,6 +37,13 @@ func (bdt *BreakDataType) Name() string {
 	return "break"
 }
 
+type ContinueDataType struct {
+}
+
+func (cdt *ContinueDataType) Name() string {
+	return "continue"
+}
+
 type IntegerDataType struct {
 }
 
@@ -42,6 +51,27 @@ func (idt *IntegerDataType) Name() string {
 	return "int"
 }
 
+type ByteDataType struct {
+}
+
+func (bdt *ByteDataType) Name() string {
+	return "byte"
+}
+
+type RuneDataType struct {
+}
+
+func (rdt *RuneDataType) Name() string {
+	return "rune"
+}
+
+type FloatDataType struct {
+}
+
+func (fdt *FloatDataType) Name() string {
+	return "float64"
+}
+
 type StringDataType struct {
 }
 
@@ -70,17 +100,29 @@ func (adt *AnyDataType) Name() string {
 	return "any"
 }
 
+// FunctionDataType is the type of a function. The last parameter of a variadic
+// function is a slice holding the trailing arguments. Generic functions list their
+// type parameters in TypeParams.
 type FunctionDataType struct {
+	TypeParams []*TypeParameterDataType
 	Parameters []DataType
 	ReturnType DataType
+	Variadic   bool
 }
 
 func (fdt *FunctionDataType) Name() string {
 	var out bytes.Buffer
 
-	out.WriteString("func(")
+	out.WriteString("func")
+	out.WriteString(TypeParametersString(fdt.TypeParams))
+	out.WriteString("(")
 	var paramsTemp []string
-	for _, param := range fdt.Parameters {
+	for i, param := range fdt.Parameters {
+		if sliceType, ok := param.(*SliceDataType); ok && fdt.Variadic && i == len(fdt.Parameters)-1 {
+			paramsTemp = append(paramsTemp, "..."+sliceType.Type.Name())
+			continue
+		}
+
 		paramsTemp = append(paramsTemp, param.Name())
 	}
 
@@ -91,6 +133,20 @@ func (fdt *FunctionDataType) Name() string {
 	return out.String()
 }
 
+// TupleDataType is the result type of a function with multiple return values.
+type TupleDataType struct {
+	Types []DataType
+}
+
+func (tdt *TupleDataType) Name() string {
+	var types []string
+	for _, dType := range tdt.Types {
+		types = append(types, dType.Name())
+	}
+
+	return "(" + strings.Join(types, ", ") + ")"
+}
+
 type ReturnDataType struct {
 }
 
@@ -105,6 +161,21 @@ func (edt *ErrorDataType) Name() string {
 	return "error"
 }
 
+type PackageDataType struct {
+	PackageName string
+}
+
+func (pdt *PackageDataType) Name() string {
+	return "package " + pdt.PackageName
+}
+
+// IsExported reports whether name starts with an upper-case letter, which makes
+// the identifier visible outside of the package declaring it.
+func IsExported(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
 type BuiltinDataType struct {
 	Parameters []DataType
 	ReturnType DataType
@@ -145,3 +216,487 @@ type ChanDataType struct {
 func (cdt *ChanDataType) Name() string {
 	return "chan " + cdt.ValueType.Name()
 }
+
+type MapDataType struct {
+	KeyType   DataType
+	ValueType DataType
+}
+
+func (mdt *MapDataType) Name() string {
+	return "map[" + mdt.KeyType.Name() + "]" + mdt.ValueType.Name()
+}
+
+type StructField struct {
+	Name string
+	Type DataType
+}
+
+type StructDataType struct {
+	Fields []*StructField
+}
+
+func (sdt *StructDataType) Name() string {
+	var out bytes.Buffer
+
+	var fields []string
+	for _, field := range sdt.Fields {
+		fields = append(fields, field.Name+" "+field.Type.Name())
+	}
+
+	out.WriteString("struct { ")
+	out.WriteString(strings.Join(fields, "; "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+func (sdt *StructDataType) Field(name string) (*StructField, bool) {
+	for _, field := range sdt.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+
+	return nil, false
+}
+
+// NamedDataType is a type introduced by a type declaration. References to the type
+// are parsed with empty Underlying, which is filled in by the analyzer. An alias
+// declared with `type A = T` denotes T itself and is marked with Alias.
+//
+// The declaration of a generic type lists its TypeParams. References to a generic
+// type carry TypeArgs and are resolved to an instance of Origin.
+type NamedDataType struct {
+	TypeName string
+	// Package is the name of the package that declares the type, or empty for
+	// types declared by the script itself.
+	Package string
+	// Path is the import path of the package that declares the type. Unlike
+	// Package it tells apart packages with the same name.
+	Path       string
+	Underlying DataType
+	Alias      bool
+	TypeParams []*TypeParameterDataType
+	TypeArgs   []DataType
+	Origin     *NamedDataType
+	// Decl is the declaration a reference to the type is resolved to.
+	Decl *NamedDataType
+
+	instances []*NamedDataType
+}
+
+func (ndt *NamedDataType) Name() string {
+	if ndt.Alias && ndt.Underlying != nil {
+		return ndt.Underlying.Name()
+	}
+
+	if len(ndt.TypeArgs) == 0 {
+		return ndt.qualifiedName()
+	}
+
+	var args []string
+	for _, arg := range ndt.TypeArgs {
+		args = append(args, arg.Name())
+	}
+
+	return ndt.qualifiedName() + "[" + strings.Join(args, ", ") + "]"
+}
+
+func (ndt *NamedDataType) qualifiedName() string {
+	if ndt.Package == "" {
+		return ndt.TypeName
+	}
+
+	return ndt.Package + "." + ndt.TypeName
+}
+
+// TypeName returns the name the methods of dType are declared with, which for
+// instances of a generic type is the name of the generic type. Types of imported
+// packages are qualified with the import path of the package.
+func TypeName(dType DataType) string {
+	if named, ok := Unalias(dType).(*NamedDataType); ok {
+		if named.Path != "" {
+			return named.Path + "." + named.TypeName
+		}
+
+		return named.qualifiedName()
+	}
+
+	return dType.Name()
+}
+
+// Declaration returns the declaration of the named type dType, which for instances of
+// a generic type is the declaration of the generic type.
+func Declaration(dType DataType) (*NamedDataType, bool) {
+	named, ok := Unalias(dType).(*NamedDataType)
+	if !ok {
+		return nil, false
+	}
+
+	if named.Origin != nil {
+		named = named.Origin
+	}
+
+	if named.Decl != nil {
+		return named.Decl, true
+	}
+
+	return named, true
+}
+
+// Instantiate returns the instance of the generic type decl for the type arguments
+// args. Instances are shared, so that instantiating a type twice with identical
+// arguments yields identical types.
+func Instantiate(decl *NamedDataType, args []DataType) *NamedDataType {
+	for _, instance := range decl.instances {
+		if identicalLists(instance.TypeArgs, args) {
+			return instance
+		}
+	}
+
+	instance := &NamedDataType{TypeName: decl.TypeName, Package: decl.Package, Path: decl.Path, TypeArgs: args, Origin: decl}
+	decl.instances = append(decl.instances, instance)
+	instance.Underlying = Substitute(decl.Underlying, decl.TypeParams, args)
+
+	return instance
+}
+
+// TypeParameterDataType is a type parameter of a generic function or type. Type
+// arguments must satisfy its Constraint.
+type TypeParameterDataType struct {
+	TypeName   string
+	Constraint DataType
+}
+
+func (tpdt *TypeParameterDataType) Name() string {
+	return tpdt.TypeName
+}
+
+// TypeParametersString returns the type parameter list of a generic declaration, or
+// an empty string if there are no type parameters.
+func TypeParametersString(params []*TypeParameterDataType) string {
+	if len(params) == 0 {
+		return ""
+	}
+
+	var out []string
+	for _, param := range params {
+		out = append(out, param.TypeName+" "+param.Constraint.Name())
+	}
+
+	return "[" + strings.Join(out, ", ") + "]"
+}
+
+// Substitute returns dType with the type parameters params replaced by args. Types
+// that do not mention the parameters are returned unchanged.
+func Substitute(dType DataType, params []*TypeParameterDataType, args []DataType) DataType {
+	if len(params) == 0 {
+		return dType
+	}
+
+	return SubstituteFunc(dType, func(param *TypeParameterDataType) DataType {
+		for i, p := range params {
+			if p == param {
+				return args[i]
+			}
+		}
+
+		return nil
+	})
+}
+
+// SubstituteFunc returns dType with every type parameter replaced by the type typeArg
+// returns for it. Type parameters for which typeArg returns nil are kept.
+func SubstituteFunc(dType DataType, typeArg func(*TypeParameterDataType) DataType) DataType {
+	switch t := dType.(type) {
+	case *TypeParameterDataType:
+		if arg := typeArg(t); arg != nil {
+			return arg
+		}
+	case *NamedDataType:
+		if t.Alias && t.Underlying != nil {
+			if underlying := SubstituteFunc(t.Underlying, typeArg); underlying != t.Underlying {
+				return underlying
+			}
+		}
+
+		if t.Origin != nil {
+			if typeArgs, changed := substituteList(t.TypeArgs, typeArg); changed {
+				return Instantiate(t.Origin, typeArgs)
+			}
+		}
+	case *SliceDataType:
+		if elemType := SubstituteFunc(t.Type, typeArg); elemType != t.Type {
+			return &SliceDataType{Type: elemType}
+		}
+	case *ReferenceDataType:
+		if valueType := SubstituteFunc(t.ValueType, typeArg); valueType != t.ValueType {
+			return &ReferenceDataType{ValueType: valueType}
+		}
+	case *ChanDataType:
+		if valueType := SubstituteFunc(t.ValueType, typeArg); valueType != t.ValueType {
+			return &ChanDataType{ValueType: valueType}
+		}
+	case *MapDataType:
+		keyType := SubstituteFunc(t.KeyType, typeArg)
+		valueType := SubstituteFunc(t.ValueType, typeArg)
+		if keyType != t.KeyType || valueType != t.ValueType {
+			return &MapDataType{KeyType: keyType, ValueType: valueType}
+		}
+	case *TupleDataType:
+		if types, changed := substituteList(t.Types, typeArg); changed {
+			return &TupleDataType{Types: types}
+		}
+	case *FunctionDataType:
+		parameters, changed := substituteList(t.Parameters, typeArg)
+		returnType := SubstituteFunc(t.ReturnType, typeArg)
+		if changed || returnType != t.ReturnType {
+			return &FunctionDataType{TypeParams: t.TypeParams, Parameters: parameters, ReturnType: returnType, Variadic: t.Variadic}
+		}
+	case *StructDataType:
+		fields := make([]*StructField, len(t.Fields))
+		changed := false
+		for i, field := range t.Fields {
+			fields[i] = &StructField{Name: field.Name, Type: SubstituteFunc(field.Type, typeArg)}
+			changed = changed || fields[i].Type != field.Type
+		}
+
+		if changed {
+			return &StructDataType{Fields: fields}
+		}
+	case *InterfaceDataType:
+		methods := make([]*InterfaceMethod, len(t.Methods))
+		changed := false
+		for i, method := range t.Methods {
+			methodType := SubstituteFunc(method.Type, typeArg).(*FunctionDataType)
+			methods[i] = &InterfaceMethod{Name: method.Name, Type: methodType}
+			changed = changed || methodType != method.Type
+		}
+
+		if changed {
+			return &InterfaceDataType{Methods: methods, Union: t.Union, Comparable: t.Comparable}
+		}
+	}
+
+	return dType
+}
+
+func substituteList(types []DataType, typeArg func(*TypeParameterDataType) DataType) ([]DataType, bool) {
+	result := make([]DataType, len(types))
+	changed := false
+	for i, dType := range types {
+		result[i] = SubstituteFunc(dType, typeArg)
+		changed = changed || result[i] != dType
+	}
+
+	return result, changed
+}
+
+// Underlying returns the type a named type was declared with.
+func Underlying(dType DataType) DataType {
+	for {
+		named, ok := dType.(*NamedDataType)
+		if !ok || named.Underlying == nil {
+			return dType
+		}
+
+		dType = named.Underlying
+	}
+}
+
+// Unalias returns the type an alias stands for.
+func Unalias(dType DataType) DataType {
+	for {
+		named, ok := dType.(*NamedDataType)
+		if !ok || !named.Alias || named.Underlying == nil {
+			return dType
+		}
+
+		dType = named.Underlying
+	}
+}
+
+// IsNamed reports whether dType is a declared or predeclared named type.
+func IsNamed(dType DataType) bool {
+	switch Unalias(dType).(type) {
+	case *NamedDataType, *TypeParameterDataType, *IntegerDataType, *ByteDataType, *RuneDataType,
+		*FloatDataType, *StringDataType, *BooleanDataType, *ErrorDataType:
+		return true
+	}
+
+	return false
+}
+
+// Identical reports whether x and y denote the same type. Named types are identical
+// only if they originate in the same declaration, other types are compared by
+// structure.
+func Identical(x, y DataType) bool {
+	x, y = Unalias(x), Unalias(y)
+	if x == y {
+		return true
+	}
+
+	if x == nil || y == nil {
+		return false
+	}
+
+	switch x := x.(type) {
+	case *TypeParameterDataType:
+		return false
+	case *NamedDataType:
+		y, ok := y.(*NamedDataType)
+		return ok && x.TypeName == y.TypeName && x.Underlying == y.Underlying
+	case *SliceDataType:
+		y, ok := y.(*SliceDataType)
+		return ok && Identical(x.Type, y.Type)
+	case *ReferenceDataType:
+		y, ok := y.(*ReferenceDataType)
+		return ok && Identical(x.ValueType, y.ValueType)
+	case *PointerDataType:
+		y, ok := y.(*PointerDataType)
+		return ok && Identical(x.ValueType, y.ValueType)
+	case *ChanDataType:
+		y, ok := y.(*ChanDataType)
+		return ok && Identical(x.ValueType, y.ValueType)
+	case *MapDataType:
+		y, ok := y.(*MapDataType)
+		return ok && Identical(x.KeyType, y.KeyType) && Identical(x.ValueType, y.ValueType)
+	case *TupleDataType:
+		y, ok := y.(*TupleDataType)
+		return ok && identicalLists(x.Types, y.Types)
+	case *FunctionDataType:
+		y, ok := y.(*FunctionDataType)
+		return ok && x.Variadic == y.Variadic && identicalLists(x.Parameters, y.Parameters) &&
+			Identical(x.ReturnType, y.ReturnType)
+	case *StructDataType:
+		y, ok := y.(*StructDataType)
+		if !ok || len(x.Fields) != len(y.Fields) {
+			return false
+		}
+
+		for i, field := range x.Fields {
+			if field.Name != y.Fields[i].Name || !Identical(field.Type, y.Fields[i].Type) {
+				return false
+			}
+		}
+
+		return true
+	case *InterfaceDataType:
+		y, ok := y.(*InterfaceDataType)
+		if !ok || len(x.Methods) != len(y.Methods) || len(x.Union) != len(y.Union) || x.Comparable != y.Comparable {
+			return false
+		}
+
+		for i, term := range x.Union {
+			if term.Tilde != y.Union[i].Tilde || !Identical(term.Type, y.Union[i].Type) {
+				return false
+			}
+		}
+
+		for _, method := range x.Methods {
+			other, ok := y.Method(method.Name)
+			if !ok || !Identical(method.Type, other.Type) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	// The remaining types are predeclared and carry no structure.
+	if _, ok := y.(*NamedDataType); ok {
+		return false
+	}
+
+	return x.Name() == y.Name()
+}
+
+func identicalLists(x, y []DataType) bool {
+	if len(x) != len(y) {
+		return false
+	}
+
+	for i := range x {
+		if !Identical(x[i], y[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type InterfaceMethod struct {
+	Name string
+	Type *FunctionDataType
+}
+
+// TypeTerm is a term of the type set of a constraint. A term ~T stands for all types
+// with the underlying type T.
+type TypeTerm struct {
+	Tilde bool
+	Type  DataType
+}
+
+func (tt *TypeTerm) String() string {
+	if tt.Tilde {
+		return "~" + tt.Type.Name()
+	}
+
+	return tt.Type.Name()
+}
+
+// InterfaceDataType is an interface type. Interfaces used as constraints may also
+// restrict their type set to the types of a Union or to Comparable types. Embeds are
+// the types named on a line of their own, which the analyzer merges into the interface.
+type InterfaceDataType struct {
+	Methods    []*InterfaceMethod
+	Union      []*TypeTerm
+	Comparable bool
+	Embeds     []DataType
+}
+
+func (idt *InterfaceDataType) Name() string {
+	var elems []string
+	if idt.Comparable {
+		elems = append(elems, "comparable")
+	}
+
+	if len(idt.Union) != 0 {
+		var terms []string
+		for _, term := range idt.Union {
+			terms = append(terms, term.String())
+		}
+
+		elems = append(elems, strings.Join(terms, " | "))
+	}
+
+	for _, embedded := range idt.Embeds {
+		elems = append(elems, embedded.Name())
+	}
+
+	for _, method := range idt.Methods {
+		elems = append(elems, method.Name+strings.TrimPrefix(method.Type.Name(), "func"))
+	}
+
+	if len(elems) == 0 {
+		return "interface {}"
+	}
+
+	var out bytes.Buffer
+
+	out.WriteString("interface { ")
+	out.WriteString(strings.Join(elems, "; "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+func (idt *InterfaceDataType) Method(name string) (*InterfaceMethod, bool) {
+	for _, method := range idt.Methods {
+		if method.Name == name {
+			return method, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,31 @@
+package ast
+
+import (
+	"bytes"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// DeferStatement defers a function call or a shell command until the enclosing
+// function returns.
+type DeferStatement struct {
+	Token token.Token
+	Call  Expression
+}
+
+func (ds *DeferStatement) statementNode() {
+
+}
+
+func (ds *DeferStatement) TokenLiteral() string {
+	return ds.Token.Literal
+}
+
+func (ds *DeferStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("defer ")
+	out.WriteString(ds.Call.String())
+
+	return out.String()
+}
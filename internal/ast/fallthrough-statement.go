@@ -0,0 +1,19 @@
+package ast
+
+import "kstmc.com/gosha/internal/token"
+
+type FallthroughStatement struct {
+	Token token.Token
+}
+
+func (fs *FallthroughStatement) statementNode() {
+
+}
+
+func (fs *FallthroughStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+
+func (fs *FallthroughStatement) String() string {
+	return fs.Token.Literal
+}
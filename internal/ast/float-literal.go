@@ -0,0 +1,23 @@
+package ast
+
+import "kstmc.com/gosha/internal/token"
+
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+	// Constant is the value converted to the type the literal takes, e.g. an integer
+	// for 2.0 assigned to an int. It is set by the analyzer.
+	Constant Expression
+}
+
+func (fl *FloatLiteral) expressionNode() {
+
+}
+
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
@@ -0,0 +1,48 @@
+package ast
+
+import (
+	"bytes"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// ForRangeStatement is 'for key, value := range iterable { ... }'. Key and Value are
+// nil if the loop does not declare them.
+type ForRangeStatement struct {
+	Token    token.Token
+	Key      *Identifier
+	Value    *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (frs *ForRangeStatement) statementNode() {
+
+}
+
+func (frs *ForRangeStatement) TokenLiteral() string {
+	return frs.Token.Literal
+}
+
+func (frs *ForRangeStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(frs.Token.Literal)
+	out.WriteString(" ")
+	if frs.Key != nil {
+		out.WriteString(frs.Key.String())
+		if frs.Value != nil {
+			out.WriteString(", " + frs.Value.String())
+		}
+
+		out.WriteString(" := ")
+	}
+
+	out.WriteString("range ")
+	out.WriteString(frs.Iterable.String())
+	out.WriteString(" {")
+	out.WriteString(frs.Body.String())
+	out.WriteString("}")
+
+	return out.String()
+}
@@ -6,9 +6,12 @@ import (
 	"kstmc.com/gosha/internal/token"
 )
 
+// ForStatement is a for loop. Init, Condition and Post are nil if omitted.
 type ForStatement struct {
 	Token       token.Token
+	Init        Statement
 	Condition   Expression
+	Post        Statement
 	Consequence *BlockStatement
 }
 
@@ -25,8 +28,25 @@ func (fs *ForStatement) String() string {
 
 	out.WriteString(fs.Token.Literal)
 	out.WriteString(" ")
-	out.WriteString(fs.Condition.String())
-	out.WriteString(" {")
+	if fs.Init != nil || fs.Post != nil {
+		if fs.Init != nil {
+			out.WriteString(fs.Init.String())
+		}
+
+		out.WriteString("; ")
+		if fs.Condition != nil {
+			out.WriteString(fs.Condition.String())
+		}
+
+		out.WriteString("; ")
+		if fs.Post != nil {
+			out.WriteString(fs.Post.String() + " ")
+		}
+	} else if fs.Condition != nil {
+		out.WriteString(fs.Condition.String() + " ")
+	}
+
+	out.WriteString("{")
 	out.WriteString(fs.Consequence.String())
 	out.WriteString("}")
 
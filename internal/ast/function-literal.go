@@ -9,10 +9,14 @@ import (
 
 type FunctionLiteral struct {
 	Token      token.Token
+	Receiver   *Identifier
 	Name       *Identifier
+	TypeParams []*TypeParameterDataType
 	Parameters []*Identifier
 	Body       *BlockStatement
 	ReturnType DataType
+	// Variadic is set if the last parameter collects the trailing arguments.
+	Variadic bool
 }
 
 func (fl *FunctionLiteral) expressionNode() {
@@ -32,10 +36,15 @@ func (fl *FunctionLiteral) String() string {
 	}
 
 	out.WriteString(fl.TokenLiteral())
+	if fl.Receiver != nil {
+		out.WriteString(" (" + fl.Receiver.Value + " " + (*fl.Receiver.DataType).Name() + ")")
+	}
+
 	if fl.Name != nil {
 		out.WriteString(" " + fl.Name.Value)
 	}
 
+	out.WriteString(TypeParametersString(fl.TypeParams))
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
@@ -6,6 +6,8 @@ type Identifier struct {
 	Token    token.Token
 	Value    string
 	DataType *DataType
+	// Constant is the value of a constant name in the type it takes, set by the analyzer.
+	Constant Expression
 }
 
 func (i *Identifier) expressionNode() {
@@ -0,0 +1,51 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// ImportSpec imports the package at Path. Name renames the package in the
+// importing file and is nil if the package keeps its own name.
+type ImportSpec struct {
+	Name *Identifier
+	Path string
+}
+
+func (is *ImportSpec) String() string {
+	if is.Name == nil {
+		return strconv.Quote(is.Path)
+	}
+
+	return is.Name.String() + " " + strconv.Quote(is.Path)
+}
+
+// ImportStatement imports packages, e.g. 'import "./lib/deploy.gosha"' or
+// 'import ( "a"; h "company/helpers" )'.
+type ImportStatement struct {
+	Token token.Token
+	Specs []*ImportSpec
+}
+
+func (is *ImportStatement) statementNode() {
+
+}
+
+func (is *ImportStatement) TokenLiteral() string {
+	return is.Token.Literal
+}
+
+func (is *ImportStatement) String() string {
+	if len(is.Specs) == 1 {
+		return is.TokenLiteral() + " " + is.Specs[0].String()
+	}
+
+	var specs []string
+	for _, spec := range is.Specs {
+		specs = append(specs, spec.String())
+	}
+
+	return is.TokenLiteral() + " (" + strings.Join(specs, "; ") + ")"
+}
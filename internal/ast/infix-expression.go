@@ -10,6 +10,9 @@ type InfixExpression struct {
 	Left     Expression
 	Operator string
 	Right    Expression
+	// Constant is the value the analyzer folded the constant expression to, converted
+	// to the type it takes. The evaluator uses it instead of evaluating the expression.
+	Constant Expression
 }
 
 func (oe *InfixExpression) expressionNode() {
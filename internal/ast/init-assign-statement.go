@@ -2,12 +2,14 @@ package ast
 
 import (
 	"bytes"
+	"strings"
+
 	"kstmc.com/gosha/internal/token"
 )
 
 type InitAssignStatement struct {
 	Token token.Token
-	Name  *Identifier
+	Names []*Identifier
 	Value Expression
 }
 
@@ -22,7 +24,12 @@ func (is *InitAssignStatement) TokenLiteral() string {
 func (is *InitAssignStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(is.Name.String() + " ")
+	var names []string
+	for _, name := range is.Names {
+		names = append(names, name.String())
+	}
+
+	out.WriteString(strings.Join(names, ", ") + " ")
 	out.WriteString(is.TokenLiteral() + " ")
 
 	if is.Value != nil {
@@ -31,3 +38,21 @@ func (is *InitAssignStatement) String() string {
 
 	return out.String()
 }
+
+// RepeatedName returns the first name other than _ that appears more than once in names.
+func RepeatedName(names []*Identifier) (string, bool) {
+	seen := map[string]bool{}
+	for _, name := range names {
+		if name.Value == "_" {
+			continue
+		}
+
+		if seen[name.Value] {
+			return name.Value, true
+		}
+
+		seen[name.Value] = true
+	}
+
+	return "", false
+}
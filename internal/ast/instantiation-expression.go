@@ -0,0 +1,41 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// InstantiationExpression instantiates a generic function with explicit type
+// arguments, as in Map[int, string]. A single type argument is parsed as an
+// IndexExpression, since f[T] may also index a value.
+type InstantiationExpression struct {
+	Token    token.Token
+	Function Expression
+	TypeArgs []Expression
+}
+
+func (ie *InstantiationExpression) expressionNode() {
+
+}
+
+func (ie *InstantiationExpression) TokenLiteral() string {
+	return ie.Token.Literal
+}
+
+func (ie *InstantiationExpression) String() string {
+	var out bytes.Buffer
+
+	var args []string
+	for _, arg := range ie.TypeArgs {
+		args = append(args, arg.String())
+	}
+
+	out.WriteString(ie.Function.String())
+	out.WriteString("[")
+	out.WriteString(strings.Join(args, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
@@ -0,0 +1,42 @@
+package ast
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// InterpolatedString is a string literal f"..." with expressions embedded in braces.
+// Parts holds its text segments as string literals and its embedded expressions in
+// source order.
+type InterpolatedString struct {
+	Token token.Token
+	Parts []Expression
+}
+
+func (is *InterpolatedString) expressionNode() {
+
+}
+
+func (is *InterpolatedString) TokenLiteral() string {
+	return is.Token.Literal
+}
+
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+	out.WriteString(`f"`)
+	for _, part := range is.Parts {
+		if text, ok := part.(*StringLiteral); ok {
+			quoted := strconv.Quote(text.Value)
+			out.WriteString(strings.NewReplacer("{", "{{", "}", "}}").Replace(quoted[1 : len(quoted)-1]))
+			continue
+		}
+
+		out.WriteString("{" + part.String() + "}")
+	}
+
+	out.WriteString(`"`)
+	return out.String()
+}
@@ -0,0 +1,22 @@
+package ast
+
+import "kstmc.com/gosha/internal/token"
+
+// LabeledStatement is a statement with a label that break and continue may refer to.
+type LabeledStatement struct {
+	Token     token.Token
+	Label     *Identifier
+	Statement Statement
+}
+
+func (ls *LabeledStatement) statementNode() {
+
+}
+
+func (ls *LabeledStatement) TokenLiteral() string {
+	return ls.Token.Literal
+}
+
+func (ls *LabeledStatement) String() string {
+	return ls.Label.String() + ":\n" + ls.Statement.String()
+}
@@ -0,0 +1,57 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+type KeyValueExpression struct {
+	Token token.Token
+	Key   Expression
+	Value Expression
+}
+
+func (kve *KeyValueExpression) expressionNode() {
+
+}
+
+func (kve *KeyValueExpression) TokenLiteral() string {
+	return kve.Token.Literal
+}
+
+func (kve *KeyValueExpression) String() string {
+	return kve.Key.String() + ": " + kve.Value.String()
+}
+
+type MapLiteral struct {
+	Token token.Token
+	Type  *MapDataType
+	Pairs []*KeyValueExpression
+}
+
+func (ml *MapLiteral) expressionNode() {
+
+}
+
+func (ml *MapLiteral) TokenLiteral() string {
+	return ml.Token.Literal
+}
+
+func (ml *MapLiteral) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ml.Type.Name())
+	out.WriteString("{")
+
+	var pairs []string
+	for _, pair := range ml.Pairs {
+		pairs = append(pairs, pair.String())
+	}
+
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
@@ -0,0 +1,19 @@
+package ast
+
+import "kstmc.com/gosha/internal/token"
+
+type NilLiteral struct {
+	Token token.Token
+}
+
+func (nl *NilLiteral) expressionNode() {
+
+}
+
+func (nl *NilLiteral) TokenLiteral() string {
+	return nl.Token.Literal
+}
+
+func (nl *NilLiteral) String() string {
+	return nl.Token.Literal
+}
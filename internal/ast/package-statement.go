@@ -0,0 +1,21 @@
+package ast
+
+import "kstmc.com/gosha/internal/token"
+
+// PackageStatement names the package a file belongs to, e.g. 'package deploy'.
+type PackageStatement struct {
+	Token token.Token
+	Name  *Identifier
+}
+
+func (ps *PackageStatement) statementNode() {
+
+}
+
+func (ps *PackageStatement) TokenLiteral() string {
+	return ps.Token.Literal
+}
+
+func (ps *PackageStatement) String() string {
+	return ps.TokenLiteral() + " " + ps.Name.String()
+}
@@ -9,6 +9,8 @@ type PrefixExpression struct {
 	Token    token.Token
 	Operator string
 	Right    Expression
+	// Constant is the folded value of a constant expression, see InfixExpression.
+	Constant Expression
 }
 
 func (pr *PrefixExpression) expressionNode() {
@@ -0,0 +1,26 @@
+package ast
+
+import (
+	"strconv"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// RuneLiteral is a rune literal such as 'a' or '\n'. It is an untyped constant whose
+// default type is rune.
+type RuneLiteral struct {
+	Token token.Token
+	Value rune
+}
+
+func (rl *RuneLiteral) expressionNode() {
+
+}
+
+func (rl *RuneLiteral) TokenLiteral() string {
+	return rl.Token.Literal
+}
+
+func (rl *RuneLiteral) String() string {
+	return strconv.QuoteRune(rl.Value)
+}
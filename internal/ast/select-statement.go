@@ -0,0 +1,72 @@
+package ast
+
+import (
+	"bytes"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// CommClause is a case of a select statement. Comm is nil for the default clause,
+// otherwise it is a send statement or a receive, possibly assigned to variables.
+type CommClause struct {
+	Token token.Token
+	Comm  Statement
+	Body  *BlockStatement
+}
+
+// Receive returns the receive expression of the clause, or nil if the clause
+// does not receive from a channel.
+func (cc *CommClause) Receive() *ReadChanExpression {
+	var value Expression
+	switch comm := cc.Comm.(type) {
+	case *ExpressionStatement:
+		value = comm.Expression
+	case *InitAssignStatement:
+		value = comm.Value
+	case *AssignStatement:
+		value = comm.Value
+	}
+
+	receive, _ := value.(*ReadChanExpression)
+	return receive
+}
+
+func (cc *CommClause) String() string {
+	var out bytes.Buffer
+
+	if cc.Comm == nil {
+		out.WriteString("default: ")
+	} else {
+		out.WriteString("case " + cc.Comm.String() + ": ")
+	}
+
+	out.WriteString(cc.Body.String())
+
+	return out.String()
+}
+
+type SelectStatement struct {
+	Token token.Token
+	Cases []*CommClause
+}
+
+func (ss *SelectStatement) statementNode() {
+
+}
+
+func (ss *SelectStatement) TokenLiteral() string {
+	return ss.Token.Literal
+}
+
+func (ss *SelectStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("select {")
+	for _, clause := range ss.Cases {
+		out.WriteString(clause.String())
+	}
+
+	out.WriteString("}")
+
+	return out.String()
+}
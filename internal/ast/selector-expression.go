@@ -0,0 +1,23 @@
+package ast
+
+import (
+	"kstmc.com/gosha/internal/token"
+)
+
+type SelectorExpression struct {
+	Token token.Token
+	Left  Expression
+	Field *Identifier
+}
+
+func (se *SelectorExpression) expressionNode() {
+
+}
+
+func (se *SelectorExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+
+func (se *SelectorExpression) String() string {
+	return se.Left.String() + "." + se.Field.String()
+}
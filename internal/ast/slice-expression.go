@@ -0,0 +1,43 @@
+package ast
+
+import (
+	"bytes"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// SliceExpression is a[low:high]. Low and High are nil if omitted.
+type SliceExpression struct {
+	Token token.Token
+	Left  Expression
+	Low   Expression
+	High  Expression
+}
+
+func (se *SliceExpression) expressionNode() {
+
+}
+
+func (se *SliceExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Low != nil {
+		out.WriteString(se.Low.String())
+	}
+
+	out.WriteString(":")
+	if se.High != nil {
+		out.WriteString(se.High.String())
+	}
+
+	out.WriteString("])")
+
+	return out.String()
+}
@@ -0,0 +1,63 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// StructLiteral is a composite literal of a named type, either keyed (Host{Name: "a"})
+// or positional (Host{"a", 22}). Keyed literals store *KeyValueExpression values.
+// Whether the type is a struct, slice or map is known only once it is resolved.
+type StructLiteral struct {
+	Token  token.Token
+	Type   DataType
+	Values []Expression
+}
+
+func (sl *StructLiteral) expressionNode() {
+
+}
+
+func (sl *StructLiteral) TokenLiteral() string {
+	return sl.Token.Literal
+}
+
+func (sl *StructLiteral) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(sl.Type.Name())
+	out.WriteString("{")
+
+	var values []string
+	for _, value := range sl.Values {
+		values = append(values, value.String())
+	}
+
+	out.WriteString(strings.Join(values, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// SliceLiteral returns the literal as a literal of the slice type sliceType.
+func (sl *StructLiteral) SliceLiteral(sliceType *SliceDataType) *SliceLiteral {
+	return &SliceLiteral{Token: sl.Token, Type: sliceType.Type, Values: sl.Values}
+}
+
+// MapLiteral returns the literal as a literal of the map type mapType. It reports
+// false if a value has no key.
+func (sl *StructLiteral) MapLiteral(mapType *MapDataType) (*MapLiteral, bool) {
+	lit := &MapLiteral{Token: sl.Token, Type: mapType}
+	for _, value := range sl.Values {
+		pair, ok := value.(*KeyValueExpression)
+		if !ok {
+			return nil, false
+		}
+
+		lit.Pairs = append(lit.Pairs, pair)
+	}
+
+	return lit, true
+}
@@ -0,0 +1,84 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// CaseClause is a case of an expression switch. Values is empty for the default clause.
+type CaseClause struct {
+	Token  token.Token
+	Values []Expression
+	Body   *BlockStatement
+}
+
+// Fallthrough reports whether the clause ends with a fallthrough statement.
+func (cc *CaseClause) Fallthrough() bool {
+	statements := cc.Body.Statements
+	if len(statements) == 0 {
+		return false
+	}
+
+	_, ok := statements[len(statements)-1].(*FallthroughStatement)
+	return ok
+}
+
+func (cc *CaseClause) String() string {
+	var out bytes.Buffer
+
+	if len(cc.Values) == 0 {
+		out.WriteString("default: ")
+	} else {
+		var values []string
+		for _, value := range cc.Values {
+			values = append(values, value.String())
+		}
+
+		out.WriteString("case " + strings.Join(values, ", ") + ": ")
+	}
+
+	out.WriteString(cc.Body.String())
+
+	return out.String()
+}
+
+// SwitchStatement is an expression switch. Tag is nil for a tagless switch,
+// whose cases are boolean conditions. Init is nil if omitted.
+type SwitchStatement struct {
+	Token token.Token
+	Init  Statement
+	Tag   Expression
+	Cases []*CaseClause
+}
+
+func (ss *SwitchStatement) statementNode() {
+
+}
+
+func (ss *SwitchStatement) TokenLiteral() string {
+	return ss.Token.Literal
+}
+
+func (ss *SwitchStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("switch ")
+	if ss.Init != nil {
+		out.WriteString(ss.Init.String() + "; ")
+	}
+
+	if ss.Tag != nil {
+		out.WriteString(ss.Tag.String() + " ")
+	}
+
+	out.WriteString("{")
+	for _, clause := range ss.Cases {
+		out.WriteString(clause.String())
+	}
+
+	out.WriteString("}")
+
+	return out.String()
+}
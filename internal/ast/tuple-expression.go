@@ -0,0 +1,31 @@
+package ast
+
+import (
+	"strings"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// TupleExpression is a list of expressions on the right side of an assignment or
+// in a return statement, e.g. 'return a, b'.
+type TupleExpression struct {
+	Token  token.Token
+	Values []Expression
+}
+
+func (te *TupleExpression) expressionNode() {
+
+}
+
+func (te *TupleExpression) TokenLiteral() string {
+	return te.Token.Literal
+}
+
+func (te *TupleExpression) String() string {
+	var values []string
+	for _, value := range te.Values {
+		values = append(values, value.String())
+	}
+
+	return strings.Join(values, ", ")
+}
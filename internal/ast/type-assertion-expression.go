@@ -0,0 +1,28 @@
+package ast
+
+import (
+	"kstmc.com/gosha/internal/token"
+)
+
+// TypeAssertionExpression is x.(T). Type is nil for x.(type) in type switches.
+type TypeAssertionExpression struct {
+	Token token.Token
+	Left  Expression
+	Type  DataType
+}
+
+func (tae *TypeAssertionExpression) expressionNode() {
+
+}
+
+func (tae *TypeAssertionExpression) TokenLiteral() string {
+	return tae.Token.Literal
+}
+
+func (tae *TypeAssertionExpression) String() string {
+	if tae.Type == nil {
+		return tae.Left.String() + ".(type)"
+	}
+
+	return tae.Left.String() + ".(" + tae.Type.Name() + ")"
+}
@@ -0,0 +1,34 @@
+package ast
+
+import (
+	"bytes"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+type TypeStatement struct {
+	Token token.Token
+	Name  *Identifier
+	Type  *NamedDataType
+}
+
+func (ts *TypeStatement) statementNode() {
+
+}
+
+func (ts *TypeStatement) TokenLiteral() string {
+	return ts.Token.Literal
+}
+
+func (ts *TypeStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ts.TokenLiteral() + " ")
+	out.WriteString(ts.Name.String() + TypeParametersString(ts.Type.TypeParams) + " ")
+	if ts.Type.Alias {
+		out.WriteString("= ")
+	}
+	out.WriteString(ts.Type.Underlying.Name())
+
+	return out.String()
+}
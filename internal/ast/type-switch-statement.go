@@ -0,0 +1,73 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"kstmc.com/gosha/internal/token"
+)
+
+// TypeCaseClause is a case of a type switch. Types is empty for the default clause.
+type TypeCaseClause struct {
+	Token token.Token
+	Types []DataType
+	Body  *BlockStatement
+}
+
+func (tcc *TypeCaseClause) String() string {
+	var out bytes.Buffer
+
+	if len(tcc.Types) == 0 {
+		out.WriteString("default: ")
+	} else {
+		var types []string
+		for _, dType := range tcc.Types {
+			types = append(types, dType.Name())
+		}
+
+		out.WriteString("case " + strings.Join(types, ", ") + ": ")
+	}
+
+	out.WriteString(tcc.Body.String())
+
+	return out.String()
+}
+
+// TypeSwitchStatement is a type switch. Init and Binding are nil if omitted.
+type TypeSwitchStatement struct {
+	Token   token.Token
+	Init    Statement
+	Binding *Identifier
+	Subject Expression
+	Cases   []*TypeCaseClause
+}
+
+func (tss *TypeSwitchStatement) statementNode() {
+
+}
+
+func (tss *TypeSwitchStatement) TokenLiteral() string {
+	return tss.Token.Literal
+}
+
+func (tss *TypeSwitchStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("switch ")
+	if tss.Init != nil {
+		out.WriteString(tss.Init.String() + "; ")
+	}
+
+	if tss.Binding != nil {
+		out.WriteString(tss.Binding.String() + " := ")
+	}
+
+	out.WriteString(tss.Subject.String() + ".(type) {")
+	for _, clause := range tss.Cases {
+		out.WriteString(clause.String())
+	}
+
+	out.WriteString("}")
+
+	return out.String()
+}
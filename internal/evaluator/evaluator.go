@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"syscall"
 
 	"kstmc.com/gosha/internal/analyzer"
 	"kstmc.com/gosha/internal/ast"
+	"kstmc.com/gosha/internal/lexer"
 	"kstmc.com/gosha/internal/object"
 	"kstmc.com/gosha/internal/parser"
 	"kstmc.com/gosha/internal/token"
@@ -17,8 +20,8 @@ import (
 
 var (
 	NIL   = &object.Nil{}
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
+	TRUE  = object.TRUE
+	FALSE = object.FALSE
 
 	isBashCommandInteractive map[string]bool = map[string]bool{
 		"vi":    true,
@@ -27,23 +30,49 @@ var (
 		"nano":  true,
 		"links": true,
 	}
+
+	// importedPackages holds the packages imported so far by the absolute path of
+	// their file or directory. A nil package is still being loaded.
+	importedPackages = map[string]*object.Package{}
+
+	// goroutineErrors receives the first error, e.g. a panic, that a goroutine does
+	// not recover. Like in Go, it ends the whole program.
+	goroutineErrors = make(chan *object.Error, 1)
 )
 
 func Eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.ReadChanExpression:
-		obj, _ := env.Get(node.Source.Value)
-		chn := obj.(*object.ChanObject)
-		val := <-chn.Chan
+		val, _ := evalReadChanExpression(node, env)
 		return val
 	case *ast.SendChanStatement:
 		obj, _ := env.Get(node.Destination.Value)
 		chn := obj.(*object.ChanObject)
 		val := Eval(node.Source, env)
-		chn.Chan <- val
+		if isError(val) {
+			return val
+		}
+
+		if !chn.Send(copyValue(val)) {
+			return newError("send on closed channel")
+		}
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.RuneLiteral:
+		return object.NewInteger(int64(node.Value), parser.RUNE)
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
+	case *ast.FloatLiteral:
+		if node.Constant != nil {
+			return Eval(node.Constant, env)
+		}
+
+		return &object.Float{Value: node.Value}
 	case *ast.ReturnStatement:
+		if node.ReturnValue == nil {
+			return &object.ReturnValue{Value: NIL}
+		}
+
 		val := Eval(node.ReturnValue, env)
 		if isError(val) {
 			return val
@@ -51,24 +80,21 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 		return &object.ReturnValue{Value: val}
 	case *ast.Program:
-		return evalProgram(node, env)
+		return runProgram(node, env)
 	case *ast.BreakStatement:
-		return &object.BreakObject{}
+		return &object.BreakObject{Label: labelName(node.Label)}
+	case *ast.ContinueStatement:
+		return &object.ContinueObject{Label: labelName(node.Label)}
+	case *ast.LabeledStatement:
+		return evalLabeledStatement(node, env)
 	case *ast.DataTypeExpression:
-		return &object.DataTypeObject{DataType: node.Type}
+		return &object.DataTypeObject{DataType: runtimeDataType(node.Type, env)}
 	case *ast.AssignStatement:
-		val := Eval(node.Value, env)
-		if isError(val) {
-			return val
-		}
-
-		if !env.Contains(node.Name.Value) {
-			return newError("unknown variable: %q", node.Name.Value)
-		}
-
-		env.Update(node.Name.Value, val)
+		return evalAssignStatement(node, env)
 	case *ast.GoStatement:
-		return evalGoStatement(node.Expr, env)
+		return evalGoStatement(node, env)
+	case *ast.DeferStatement:
+		return evalDeferStatement(node, env)
 	case *ast.VarStatement:
 		var val object.Object
 		if node.Value != nil {
@@ -83,27 +109,30 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 
 		if val == nil && node.Name.DataType != nil {
-			env.Set(node.Name.Value, analyzer.NativeTypeToDefaultObj(*node.Name.DataType))
-		} else if node.Name.DataType != nil && (*node.Name.DataType).Name() == parser.ANY.Name() {
-			env.Set(node.Name.Value, &object.Any{Value: val.Inspect()})
+			env.Set(node.Name.Value, analyzer.NativeTypeToDefaultObj(runtimeDataType(*node.Name.DataType, env)))
+		} else if node.Name.DataType != nil {
+			env.Set(node.Name.Value, object.Convert(copyValue(val), runtimeDataType(*node.Name.DataType, env)))
 		} else {
-			env.Set(node.Name.Value, val)
+			env.Set(node.Name.Value, copyValue(val))
 		}
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
 	case *ast.InitAssignStatement:
-		val := Eval(node.Value, env)
-		if isError(val) {
-			return val
-		}
-
-		if env.Contains(node.Name.Value) {
-			return newError("Identifier %q already exists", node.Name.Value)
-		}
-
-		env.Set(node.Name.Value, val)
+		return evalInitAssignStatement(node, env)
+	case *ast.TypeStatement:
+		env.Set(node.Name.Value, &object.DataTypeObject{DataType: node.Type})
+	case *ast.ConstStatement:
+		return evalConstStatement(node, env)
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
+	case *ast.TypeSwitchStatement:
+		return evalTypeSwitchStatement(node, env)
+	case *ast.SwitchStatement:
+		return evalSwitchStatement(node, env)
+	case *ast.SelectStatement:
+		return evalSelectStatement(node, env)
 	case *ast.CallExpression:
-		function := Eval(node.Function, env)
+		function, args := evalCallee(node, env)
 		if isError(function) {
 			return function
 		}
@@ -113,16 +142,25 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		//	return applyBuiltin(fn, node.Arguments, env)
 		//}
 
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+		if function == object.Builtins["recover"] {
+			return evalRecover(env)
+		}
+
+		if function == object.Builtins["new"] {
+			return evalNew(args)
+		}
+
+		if function == object.Builtins["make"] {
+			return evalMake(args)
 		}
 
 		return applyFunction(function, args, env)
 	case *ast.IfStatement:
 		return evalIfExpression(node, env)
 	case *ast.ForStatement:
-		return evalForStatement(node, env)
+		return evalForStatement(node, "", env)
+	case *ast.ForRangeStatement:
+		return evalForRangeStatement(node, "", env)
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 	case *ast.BlockStatement:
@@ -130,14 +168,22 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.Boolean:
 		return rawBooleanToBooleanObject(node.Value)
 	case *ast.Identifier:
+		if node.Constant != nil {
+			return Eval(node.Constant, env)
+		}
+
 		return evalIdentifier(node, env)
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
 		returnType := node.ReturnType
 		name := node.Name
-		function := &object.Function{Parameters: params, Env: env, Body: body, ReturnType: returnType, Name: name}
-		if name != nil {
+		function := &object.Function{TypeParams: node.TypeParams, Parameters: params, Env: env, Body: body, ReturnType: returnType, Name: name, Variadic: node.Variadic}
+		if node.Receiver != nil {
+			function.Receiver = node.Receiver
+			env.SetMethod(receiverTypeName(node.Receiver), name.Value, function)
+			object.SetTypeMethod(receiverType(node.Receiver), name.Value, function)
+		} else if name != nil {
 			if env.Contains(name.Value) {
 				return newError("function %s already exists", name.Value)
 			} else {
@@ -146,11 +192,47 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 
 		return function
+	case *ast.SliceLiteral:
+		return evalSliceLiteral(node, env)
+	case *ast.MapLiteral:
+		return evalMapLiteral(node, env)
+	case *ast.StructLiteral:
+		return evalStructLiteral(node, env)
+	case *ast.SelectorExpression:
+		return evalSelectorExpression(node, env)
+	case *ast.TypeAssertionExpression:
+		val, ok := evalTypeAssertionExpression(node, env)
+		if ok == FALSE && !isError(val) {
+			return typeAssertionError(node, env)
+		}
+
+		return val
+	case *ast.NilLiteral:
+		return NIL
+	case *ast.TupleExpression:
+		values := evalExpressions(node.Values, env)
+		if len(values) == 1 && isError(values[0]) {
+			return values[0]
+		}
+
+		for i, value := range values {
+			values[i] = copyValue(value)
+		}
+
+		return &object.Tuple{Values: values}
 	case *ast.BashExpression:
 		return evalBashExpression(node, env)
 	case *ast.BashVarExpression:
 		return evalBashVarExpression(node, env)
 	case *ast.PrefixExpression:
+		if node.Constant != nil {
+			return Eval(node.Constant, env)
+		}
+
+		if node.Operator == token.REF {
+			return evalAddressExpression(node.Right, env)
+		}
+
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
@@ -158,32 +240,21 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 		return evalPrefixExpression(node.Operator, right)
 	case *ast.IndexExpression:
-		index := Eval(node.Index, env)
-		if isError(index) {
-			return index
-		}
-
-		intIndex, ok := index.(*object.Integer)
-		if !ok {
-			return newError("expected integer type for index expression, got=%T", index)
+		return evalIndexExpression(node, env)
+	case *ast.InstantiationExpression:
+		generic := Eval(node.Function, env)
+		if isError(generic) {
+			return generic
 		}
 
-		obj := Eval(node.Left, env)
-		if isError(index) {
-			return obj
-		}
-
-		slice, ok := obj.(*object.SliceObject)
-		if !ok {
-			return newError("expected slice obect for index expression, got=%T", obj)
-		}
-
-		if intIndex.Value < 0 || int64(len(slice.Values)) <= intIndex.Value {
-			return newError("out of bound error for slice '%s' at index %d", node.Left.String(), intIndex.Value)
+		return evalInstantiation(generic, node.TypeArgs, env)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+	case *ast.InfixExpression:
+		if node.Constant != nil {
+			return Eval(node.Constant, env)
 		}
 
-		return slice.Values[intIndex.Value]
-	case *ast.InfixExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
@@ -200,112 +271,1631 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	return NIL
 }
 
-func evalGoStatement(expr ast.Expression, env *object.Environment) object.Object {
-	go Eval(expr, env)
+// evalGoStatement starts a goroutine. As with defer, the function and its arguments
+// are evaluated immediately, a shell command only in the new goroutine.
+func evalGoStatement(node *ast.GoStatement, env *object.Environment) object.Object {
+	call, ok := node.Expr.(*ast.CallExpression)
+	if !ok {
+		go func() {
+			reportGoroutineError(Eval(node.Expr, env))
+		}()
+
+		return NIL
+	}
+
+	function, args := evalCallee(call, env)
+	if isError(function) {
+		return function
+	}
+
+	go func() {
+		reportGoroutineError(applyFunction(function, args, env))
+	}()
+
 	return NIL
 }
 
-func evalBashVarExpression(node *ast.BashVarExpression, env *object.Environment) object.Object {
-	num, ok := strconv.Atoi(node.Value[1:])
-	if ok == nil {
-		if len(os.Args) <= num+1 {
-			return newError("provided %d args but %d arg was called", len(os.Args)-1, num)
+// reportGoroutineError ends the program if result, the result of a goroutine, is an error.
+func reportGoroutineError(result object.Object) {
+	if err, ok := result.(*object.Error); ok {
+		select {
+		case goroutineErrors <- err:
+		default:
 		}
-		return &object.String{
-			Value: os.Args[num+1],
+	}
+}
+
+// evalCallee evaluates the function and the arguments of a call. If evaluation fails,
+// the error is returned in place of the function.
+func evalCallee(call *ast.CallExpression, env *object.Environment) (object.Object, []object.Object) {
+	function := Eval(call.Function, env)
+	if isError(function) {
+		return function, nil
+	}
+
+	if fn, ok := function.(*object.Function); ok && len(call.TypeArgs) != 0 {
+		typeArgs := make([]ast.DataType, len(call.TypeArgs))
+		for i, typeArg := range call.TypeArgs {
+			typeArgs[i] = runtimeDataType(typeArg, env)
 		}
+
+		function = instantiate(fn, typeArgs)
 	}
 
-	output, err := exec.Command("bash", "-c", "echo "+node.Value).Output()
-	if err != nil {
-		return newError("bash error %s", err.Error())
+	args := evalExpressions(call.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0], nil
 	}
 
-	return &object.String{Value: string(output)}
+	if len(args) == 1 {
+		if tuple, ok := args[0].(*object.Tuple); ok {
+			args = tuple.Values
+		}
+	}
+
+	switch fn := function.(type) {
+	case *object.Function:
+		if fn.Variadic && !call.Ellipsis {
+			args = packVariadicArguments(fn, args)
+		}
+	case *object.Builtin:
+		// append(xs, ys...) passes the values of ys one by one, and the bytes of ys
+		// if it is a string.
+		if call.Ellipsis && len(args) != 0 {
+			switch spread := object.Unwrap(args[len(args)-1]).(type) {
+			case *object.SliceObject:
+				args = append(args[:len(args)-1:len(args)-1], spread.Values...)
+			case *object.String:
+				args = append(args[:len(args)-1:len(args)-1], stringToSlice(spread.Value, parser.BYTE).Values...)
+			}
+		}
+	}
+
+	return function, args
 }
 
-func evalForStatement(stmt *ast.ForStatement, env *object.Environment) object.Object {
-	for {
-		condition := Eval(stmt.Condition, env)
-		if isError(condition) {
-			return condition
+// packVariadicArguments collects the trailing arguments of a variadic function into
+// the slice its final parameter receives.
+func packVariadicArguments(fn *object.Function, args []object.Object) []object.Object {
+	fixed := len(fn.Parameters) - 1
+	elemType := (*fn.Parameters[fixed].DataType).(*ast.SliceDataType).Type
+	if len(fn.TypeArgs) != 0 {
+		elemType = ast.Substitute(elemType, fn.TypeParams, fn.TypeArgs)
+	}
+
+	variadic := &object.SliceObject{ValueType: elemType, Values: []object.Object{}}
+	for _, arg := range args[fixed:] {
+		variadic.Values = append(variadic.Values, object.Convert(copyValue(arg), elemType))
+	}
+
+	return append(args[:fixed:fixed], variadic)
+}
+
+// evalDeferStatement registers a deferred call. The function and its arguments are
+// evaluated immediately, a deferred shell command only when it runs.
+func evalDeferStatement(node *ast.DeferStatement, env *object.Environment) object.Object {
+	call, ok := node.Call.(*ast.CallExpression)
+	if !ok {
+		env.Defer(func(*object.Panic) object.Object {
+			return Eval(node.Call, env)
+		})
+
+		return NIL
+	}
+
+	function, args := evalCallee(call, env)
+	if isError(function) {
+		return function
+	}
+
+	env.Defer(func(p *object.Panic) object.Object {
+		if fn, ok := function.(*object.Function); ok {
+			return callFunction(fn, args, p)
 		}
 
-		if condition == TRUE {
-			result := Eval(stmt.Consequence, env)
-			if result.Type().Name() == parser.RETURN.Name() {
-				return result
-			} else if result.Type().Name() == parser.BREAK.Name() {
-				return NIL
-			}
+		return applyFunction(function, args, env)
+	})
+
+	return NIL
+}
+
+func evalSliceLiteral(node *ast.SliceLiteral, env *object.Environment) object.Object {
+	elemType := runtimeDataType(node.Type, env)
+	values := evalExpressions(node.Values, env)
+	if len(values) == 1 && isError(values[0]) {
+		return values[0]
+	}
+
+	for i, value := range values {
+		values[i] = copyValue(value)
+	}
+
+	for i, value := range values {
+		values[i] = object.Convert(value, elemType)
+	}
+
+	// Literals are not nil, even if they are empty.
+	if values == nil {
+		values = []object.Object{}
+	}
+
+	return &object.SliceObject{
+		ValueType: elemType,
+		Values:    values,
+	}
+}
+
+func evalMapLiteral(node *ast.MapLiteral, env *object.Environment) object.Object {
+	mapType := runtimeDataType(node.Type, env).(*ast.MapDataType)
+	mapObj := &object.MapObject{
+		Pairs:     make(map[object.HashKey]object.MapPair),
+		KeyType:   mapType.KeyType,
+		ValueType: mapType.ValueType,
+	}
+
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if isError(key) {
+			return key
+		}
+
+		key = mapKey(key, mapType.KeyType)
+		hashable, ok := object.HashableKey(key)
+		if !ok {
+			return newError("unusable as map key: %s", object.Unwrap(key).Type().Name())
+		}
+
+		value := Eval(pair.Value, env)
+		if isError(value) {
+			return value
+		}
+
+		mapObj.Pairs[hashable.HashKey()] = object.MapPair{Key: key, Value: object.Convert(copyValue(value), mapType.ValueType)}
+	}
+
+	return mapObj
+}
+
+func evalStructLiteral(node *ast.StructLiteral, env *object.Environment) object.Object {
+	dType := runtimeDataType(node.Type, env)
+	switch underlying := ast.Underlying(dType).(type) {
+	case *ast.SliceDataType:
+		return object.Convert(evalSliceLiteral(node.SliceLiteral(underlying), env), dType)
+	case *ast.MapDataType:
+		lit, _ := node.MapLiteral(underlying)
+		return object.Convert(evalMapLiteral(lit, env), dType)
+	}
+
+	structObj, ok := analyzer.NativeTypeToDefaultObj(dType).(*object.StructObject)
+	if !ok {
+		return newError("invalid composite literal type %s", node.Type.Name())
+	}
+
+	structType := ast.Underlying(dType).(*ast.StructDataType)
+	for i, value := range node.Values {
+		var field *ast.StructField
+		if pair, ok := value.(*ast.KeyValueExpression); ok {
+			field, _ = structType.Field(pair.Key.(*ast.Identifier).Value)
+			value = pair.Value
 		} else {
-			return NIL
+			field = structType.Fields[i]
 		}
+
+		val := Eval(value, env)
+		if isError(val) {
+			return val
+		}
+
+		structObj.SetField(field.Name, object.Convert(copyValue(val), field.Type))
 	}
+
+	return structObj
 }
 
-func evalProgram(program *ast.Program, env *object.Environment) object.Object {
-	var result object.Object
+func evalSelectorExpression(node *ast.SelectorExpression, env *object.Environment) object.Object {
+	obj := Eval(node.Left, env)
+	if isError(obj) {
+		return obj
+	}
 
-	for _, statement := range program.Statements {
-		env = object.NewEnclosedEnvironment(env)
-		errors := analyzer.AnalyzeStatement(statement, parser.ANY, env)
-		env = object.UnwrapEnvironment(env)
-		if len(errors) != 0 {
-			return newError("analyzer error %s", errors[0])
+	if pkg, ok := obj.(*object.Package); ok {
+		member, ok := pkg.Member(node.Field.Value)
+		if !ok {
+			return newError("undefined: %s", node.String())
 		}
 
-		result = Eval(statement, env)
+		if constant, ok := member.(*object.Constant); ok {
+			return constant.Default()
+		}
 
-		switch result := result.(type) {
-		case *object.ReturnValue:
-			return result.Value
-		case *object.Error:
-			return result
+		return member
+	}
+
+	if iface, ok := obj.(*object.Interface); ok {
+		if iface.Value == nil {
+			return newError("invalid memory address or nil pointer dereference: %s", node.String())
 		}
+
+		obj = iface.Value
 	}
 
-	return result
+	target := obj
+	if ref, ok := obj.(*object.ReferenceObject); ok {
+		if ref.Value == nil {
+			// Methods with pointer receivers may be called on nil pointers.
+			if method, ok := env.LookupMethod(ref.ValueType, node.Field.Value); ok && hasPointerReceiver(method.(*object.Function)) {
+				return bindMethod(method.(*object.Function), obj)
+			}
+
+			return newError("invalid memory address or nil pointer dereference: %s", node.String())
+		}
+
+		target = *ref.Value
+	}
+
+	if structObj, ok := target.(*object.StructObject); ok {
+		if field, ok := structObj.Field(node.Field.Value); ok {
+			return field
+		}
+	}
+
+	if method, ok := object.BuiltinMethods[target.Type().Name()][node.Field.Value]; ok {
+		return method.Bind(node.Field.Value, target)
+	}
+
+	method, ok := env.LookupMethod(target.Type(), node.Field.Value)
+	if !ok {
+		return newError("%s undefined (type %s has no field or method %s)", node.String(), target.Type().Name(), node.Field.Value)
+	}
+
+	fn := method.(*object.Function)
+	if ident, ok := node.Left.(*ast.Identifier); ok && obj == target && hasPointerReceiver(fn) {
+		// Pointer methods of variables get the address of the variable.
+		if cell, ok := env.Ref(ident.Value); ok {
+			obj = &object.ReferenceObject{Value: cell}
+		}
+	}
+
+	return bindMethod(fn, obj)
 }
 
-func evalBashExpression(expr *ast.BashExpression, env *object.Environment) object.Object {
-	var bashArgs []string
-	for _, arg := range expr.Value {
-		if arg[0] == '$' && env.Contains(arg[1:]) {
-			arg, _ := env.Get(arg[1:])
-			bashArgs = append(bashArgs, arg.Inspect())
-		} else {
-			bashArgs = append(bashArgs, arg)
+// bindReceiverTypeParameters binds the type parameters of a method of a generic type
+// to the type arguments of the receiver obj.
+func bindReceiverTypeParameters(method *object.Function, obj object.Object, env *object.Environment) {
+	receiverType := obj.Type()
+	if ref, ok := obj.(*object.ReferenceObject); ok {
+		receiverType = ref.ValueType
+		if ref.Value != nil {
+			receiverType = (*ref.Value).Type()
 		}
 	}
 
-	result, ok := isBashCommandInteractive[bashArgs[0]]
-	if ok && result {
-		cmd := exec.Command("bash", "-c", strings.Join(bashArgs, " "))
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	named, ok := ast.Unalias(receiverType).(*ast.NamedDataType)
+	params := object.ReceiverTypeParameters(method)
+	if !ok || len(named.TypeArgs) != len(params) {
+		return
+	}
+
+	for i, param := range params {
+		env.Set(param.TypeName, &object.DataTypeObject{DataType: named.TypeArgs[i]})
+	}
+}
+
+func hasPointerReceiver(method *object.Function) bool {
+	_, ok := (*method.Receiver.DataType).(*ast.ReferenceDataType)
+	return ok
+}
+
+// bindMethod returns a method value with the receiver bound to obj. Pointer receivers
+// share the receiver object, value receivers get a copy of it.
+func bindMethod(method *object.Function, obj object.Object) *object.Function {
+	isPointerReceiver := hasPointerReceiver(method)
+	ref, isReference := obj.(*object.ReferenceObject)
+
+	receiver := obj
+	switch {
+	case isPointerReceiver && !isReference:
+		receiver = &object.ReferenceObject{Value: &obj}
+	case !isPointerReceiver && isReference:
+		receiver = copyValue(*ref.Value)
+	case !isPointerReceiver:
+		receiver = copyValue(obj)
+	}
+
+	env := object.NewEnclosedEnvironment(method.Env)
+	env.Set(method.Receiver.Value, receiver)
+	bindReceiverTypeParameters(method, obj, env)
+
+	return &object.Function{
+		Name:       method.Name,
+		Parameters: method.Parameters,
+		ReturnType: method.ReturnType,
+		Body:       method.Body,
+		Env:        env,
+		Variadic:   method.Variadic,
+	}
+}
+
+// evalTypeAssertionExpression returns the value held by an interface converted to the
+// asserted type, or the zero value of that type and FALSE if the assertion fails.
+func evalTypeAssertionExpression(node *ast.TypeAssertionExpression, env *object.Environment) (object.Object, *object.Boolean) {
+	obj := Eval(node.Left, env)
+	if isError(obj) {
+		return obj, FALSE
+	}
+
+	value := object.Unwrap(obj)
+	if !hasDynamicType(value, node.Type, env) {
+		return analyzer.NativeTypeToDefaultObj(node.Type), FALSE
+	}
+
+	return object.Convert(value, node.Type), TRUE
+}
+
+func typeAssertionError(node *ast.TypeAssertionExpression, env *object.Environment) object.Object {
+	obj := Eval(node.Left, env)
+	value := object.Unwrap(obj)
+	if value.Type() == parser.NIL {
+		return newError("interface conversion: %s is nil, not %s", obj.Type().Name(), node.Type.Name())
+	}
+
+	if interfaceType, ok := ast.Underlying(node.Type).(*ast.InterfaceDataType); ok {
+		method, _ := object.MissingMethod(value.Type(), interfaceType, env)
+		return newError("interface conversion: %s is not %s: missing method %s", value.Type().Name(), node.Type.Name(), method)
+	}
+
+	return newError("interface conversion: %s is %s, not %s", obj.Type().Name(), value.Type().Name(), node.Type.Name())
+}
+
+// hasDynamicType reports whether a value taken out of an interface satisfies a type
+// assertion or a type switch case with dType.
+func hasDynamicType(value object.Object, dType ast.DataType, env *object.Environment) bool {
+	if value.Type() == parser.NIL {
+		return dType == parser.NIL
+	}
+
+	switch interfaceType := ast.Underlying(dType).(type) {
+	case *ast.AnyDataType:
+		return true
+	case *ast.InterfaceDataType:
+		_, missing := object.MissingMethod(value.Type(), interfaceType, env)
+		return !missing
+	default:
+		return ast.Identical(value.Type(), runtimeDataType(dType, env))
+	}
+}
+
+func evalTypeSwitchStatement(node *ast.TypeSwitchStatement, env *object.Environment) object.Object {
+	if node.Init != nil {
+		env = object.NewEnclosedEnvironment(env)
+		if result := Eval(node.Init, env); isError(result) {
+			return result
+		}
+	}
+
+	obj := Eval(node.Subject, env)
+	if isError(obj) {
+		return obj
+	}
+
+	value := object.Unwrap(obj)
+
+	var matched, defaultClause *ast.TypeCaseClause
+	for _, clause := range node.Cases {
+		if len(clause.Types) == 0 {
+			defaultClause = clause
+			continue
+		}
+
+		for _, dType := range clause.Types {
+			if hasDynamicType(value, dType, env) {
+				matched = clause
+				break
+			}
+		}
+
+		if matched != nil {
+			break
+		}
+	}
+
+	if matched == nil {
+		matched = defaultClause
+	}
+
+	if matched == nil {
+		return NIL
+	}
+
+	clauseEnv := object.NewEnclosedEnvironment(env)
+	if node.Binding != nil {
+		binding := obj
+		if len(matched.Types) == 1 && matched.Types[0] != parser.NIL {
+			binding = object.Convert(copyValue(value), matched.Types[0])
+		}
+
+		clauseEnv.Set(node.Binding.Value, binding)
+	}
+
+	return switchResult(Eval(matched.Body, clauseEnv))
+}
+
+func evalSwitchStatement(node *ast.SwitchStatement, env *object.Environment) object.Object {
+	if node.Init != nil {
+		env = object.NewEnclosedEnvironment(env)
+		if result := Eval(node.Init, env); isError(result) {
+			return result
+		}
+	}
+
+	var tag object.Object = TRUE
+	if node.Tag != nil {
+		tag = Eval(node.Tag, env)
+		if isError(tag) {
+			return tag
+		}
+	}
+
+	matched, defaultIndex := -1, -1
+	for i, clause := range node.Cases {
+		if len(clause.Values) == 0 {
+			defaultIndex = i
+			continue
+		}
+
+		for _, value := range clause.Values {
+			obj := Eval(value, env)
+			if isError(obj) {
+				return obj
+			}
+
+			equal := evalInfixExpression(token.EQ, tag, obj)
+			if isError(equal) {
+				return equal
+			}
+
+			if equal == TRUE {
+				matched = i
+				break
+			}
+		}
+
+		if matched != -1 {
+			break
+		}
+	}
+
+	if matched == -1 {
+		matched = defaultIndex
+	}
+
+	if matched == -1 {
+		return NIL
+	}
+
+	// a clause ending with fallthrough continues with the body of the next clause
+	for _, clause := range node.Cases[matched:] {
+		result := Eval(clause.Body, env)
+		if interruptsBlock(result) || !clause.Fallthrough() {
+			return switchResult(result)
+		}
+	}
+
+	return NIL
+}
+
+// selectCase runs reflect.Select. It reports false instead of panicking if the
+// chosen case sends on a closed channel.
+func selectCase(cases []reflect.SelectCase) (chosen int, recv reflect.Value, recvOK, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	chosen, recv, recvOK = reflect.Select(cases)
+	return chosen, recv, recvOK, true
+}
+
+func evalSelectStatement(node *ast.SelectStatement, env *object.Environment) object.Object {
+	// channel operands and sent values are evaluated once, in source order
+	cases := make([]reflect.SelectCase, len(node.Cases))
+	channels := make([]*object.ChanObject, len(node.Cases))
+	for i, clause := range node.Cases {
+		if clause.Comm == nil {
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectDefault}
+			continue
+		}
+
+		if send, ok := clause.Comm.(*ast.SendChanStatement); ok {
+			val := Eval(send.Source, env)
+			if isError(val) {
+				return val
+			}
+
+			val = copyValue(val)
+
+			obj, _ := env.Get(send.Destination.Value)
+			channels[i] = obj.(*object.ChanObject)
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(channels[i].Chan), Send: reflect.ValueOf(&val).Elem()}
+			continue
+		}
+
+		obj := Eval(clause.Receive().Source, env)
+		if isError(obj) {
+			return obj
+		}
+
+		channels[i] = object.Unwrap(obj).(*object.ChanObject)
+		cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(channels[i].Chan)}
+	}
+
+	chosen, recv, recvOK, ok := selectCase(cases)
+	if !ok {
+		return newError("send on closed channel")
+	}
+
+	clause := node.Cases[chosen]
+
+	clauseEnv := object.NewEnclosedEnvironment(env)
+	if receive := clause.Receive(); receive != nil {
+		var value object.Object
+		if recvOK {
+			value = recv.Interface().(object.Object)
+		} else {
+			value = analyzer.NativeTypeToDefaultObj(channels[chosen].ChanType)
+		}
+
+		values := []object.Object{value, rawBooleanToBooleanObject(recvOK)}
+
+		var result object.Object
+		switch comm := clause.Comm.(type) {
+		case *ast.InitAssignStatement:
+			result = declareValues(comm.Names, values[:len(comm.Names)], clauseEnv)
+		case *ast.AssignStatement:
+			setters, err := evalAssignTargets(comm.Targets, clauseEnv)
+			if err != nil {
+				return err
+			}
+
+			result = assignValues(setters, values[:len(comm.Targets)])
+		}
+
+		if isError(result) {
+			return result
+		}
+	}
+
+	return switchResult(Eval(clause.Body, clauseEnv))
+}
+
+// switchResult turns an unlabeled break, which ends the switch statement, into NIL.
+func switchResult(result object.Object) object.Object {
+	if brk, ok := result.(*object.BreakObject); ok && brk.Label == "" {
+		return NIL
+	}
+
+	return result
+}
+
+func receiverTypeName(receiver *ast.Identifier) string {
+	return ast.TypeName(receiverType(receiver))
+}
+
+// receiverType returns the named type of a method receiver, which may be a pointer to it.
+func receiverType(receiver *ast.Identifier) ast.DataType {
+	dType := *receiver.DataType
+	if refType, ok := dType.(*ast.ReferenceDataType); ok {
+		return refType.ValueType
+	}
+
+	return dType
+}
+
+// evalInstantiation instantiates a generic function or type with the types typeArgs
+// evaluate to.
+func evalInstantiation(generic object.Object, typeArgs []ast.Expression, env *object.Environment) object.Object {
+	types := make([]ast.DataType, len(typeArgs))
+	for i, typeArg := range typeArgs {
+		typeObj, ok := Eval(typeArg, env).(*object.DataTypeObject)
+		if !ok {
+			return newError("%s is not a type", typeArg.String())
+		}
+
+		types[i] = typeObj.DataType
+	}
+
+	switch generic := generic.(type) {
+	case *object.Function:
+		if len(generic.TypeParams) == len(types) {
+			return instantiate(generic, types)
+		}
+	case *object.DataTypeObject:
+		if named, ok := generic.DataType.(*ast.NamedDataType); ok && len(named.TypeParams) == len(types) {
+			return &object.DataTypeObject{DataType: ast.Instantiate(named, types)}
+		}
+	}
+
+	return newError("cannot instantiate %s with %d type arguments", generic.Inspect(), len(types))
+}
+
+// instantiate returns the generic function fn called with the type arguments typeArgs.
+func instantiate(fn *object.Function, typeArgs []ast.DataType) *object.Function {
+	instance := *fn
+	instance.TypeArgs = typeArgs
+	return &instance
+}
+
+// runtimeDataType returns dType with the type parameters replaced by the type
+// arguments they are bound to in env.
+func runtimeDataType(dType ast.DataType, env *object.Environment) ast.DataType {
+	if dType == nil {
+		return nil
+	}
+
+	return ast.SubstituteFunc(dType, func(param *ast.TypeParameterDataType) ast.DataType {
+		if typeObj, ok := env.Get(param.TypeName); ok {
+			if typeObj, ok := typeObj.(*object.DataTypeObject); ok && typeObj.DataType != param {
+				return typeObj.DataType
+			}
+		}
+
+		return nil
+	})
+}
+
+func copyValue(obj object.Object) object.Object {
+	if structObj, ok := obj.(*object.StructObject); ok {
+		return structObj.Copy()
+	}
+
+	return obj
+}
+
+func evalIndexExpression(node *ast.IndexExpression, env *object.Environment) object.Object {
+	obj := Eval(node.Left, env)
+	if isError(obj) {
+		return obj
+	}
+
+	switch obj.(type) {
+	case *object.Function, *object.DataTypeObject:
+		return evalInstantiation(obj, []ast.Expression{node.Index}, env)
+	}
+
+	index := Eval(node.Index, env)
+	if isError(index) {
+		return index
+	}
+
+	switch obj := obj.(type) {
+	case *object.MapObject:
+		val, _ := evalMapIndex(obj, index)
+		return val
+	case *object.SliceObject:
+		intIndex, ok := index.(*object.Integer)
+		if !ok {
+			return newError("expected integer type for index expression, got=%T", index)
+		}
+
+		if intIndex.Value < 0 || int64(len(obj.Values)) <= intIndex.Value {
+			return newError("out of bound error for slice '%s' at index %d", node.Left.String(), intIndex.Value)
+		}
+
+		return obj.Values[intIndex.Value]
+	case *object.String:
+		intIndex, ok := index.(*object.Integer)
+		if !ok {
+			return newError("expected integer type for index expression, got=%T", index)
+		}
+
+		if intIndex.Value < 0 || int64(len(obj.Value)) <= intIndex.Value {
+			return newError("out of bound error for string '%s' at index %d", node.Left.String(), intIndex.Value)
+		}
+
+		return object.NewInteger(int64(obj.Value[intIndex.Value]), parser.BYTE)
+	default:
+		return newError("expected slice or map object for index expression, got=%T", obj)
+	}
+}
+
+// evalSliceExpression returns the part of a string or slice between the low and high
+// bounds. A slice shares its values with the sliced slice.
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	obj := object.Unwrap(Eval(node.Left, env))
+	if isError(obj) {
+		return obj
+	}
+
+	var length int64
+	switch obj := obj.(type) {
+	case *object.String:
+		length = int64(len(obj.Value))
+	case *object.SliceObject:
+		length = int64(len(obj.Values))
+	default:
+		return newError("cannot slice %s (type %s)", node.Left.String(), obj.Type().Name())
+	}
+
+	low, high := int64(0), length
+	for _, bound := range []struct {
+		expr  ast.Expression
+		value *int64
+	}{{node.Low, &low}, {node.High, &high}} {
+		if bound.expr == nil {
+			continue
+		}
+
+		value := Eval(bound.expr, env)
+		if isError(value) {
+			return value
+		}
+
+		intValue, ok := value.(*object.Integer)
+		if !ok {
+			return newError("expected integer type for slice bound, got=%T", value)
+		}
+
+		*bound.value = intValue.Value
+	}
+
+	if low < 0 || high < low || length < high {
+		return newError("slice bounds out of range [%d:%d] with length %d", low, high, length)
+	}
+
+	if str, ok := obj.(*object.String); ok {
+		return &object.String{Value: str.Value[low:high]}
+	}
+
+	slice := obj.(*object.SliceObject)
+	return &object.SliceObject{Values: slice.Values[low:high], ValueType: slice.ValueType, SliceType: slice.SliceType}
+}
+
+// mapKey returns key as stored in a map with keys of type keyType, so that a constant
+// finds the entry of a key of a named type.
+func mapKey(key object.Object, keyType ast.DataType) object.Object {
+	if object.IsInterfaceType(keyType) {
+		return key
+	}
+
+	return object.Convert(key, keyType)
+}
+
+// evalMapIndex returns the value stored under key, or the zero value of the map value type
+// if there is no such key.
+func evalMapIndex(mapObj *object.MapObject, key object.Object) (object.Object, *object.Boolean) {
+	hashable, ok := object.HashableKey(mapKey(key, mapObj.KeyType))
+	if !ok {
+		return newError("unusable as map key: %s", object.Unwrap(key).Type().Name()), FALSE
+	}
+
+	pair, ok := mapObj.Pairs[hashable.HashKey()]
+	if !ok {
+		return analyzer.NativeTypeToDefaultObj(mapObj.ValueType), FALSE
+	}
+
+	return pair.Value, TRUE
+}
+
+// evalCommaOkExpression evaluates expressions used in the 'v, ok' form.
+func evalCommaOkExpression(expr ast.Expression, env *object.Environment) (object.Object, *object.Boolean) {
+	if expr, ok := expr.(*ast.TypeAssertionExpression); ok {
+		return evalTypeAssertionExpression(expr, env)
+	}
+
+	if expr, ok := expr.(*ast.ReadChanExpression); ok {
+		return evalReadChanExpression(expr, env)
+	}
+
+	if expr, ok := expr.(*ast.IndexExpression); ok {
+		obj := Eval(expr.Left, env)
+		if isError(obj) {
+			return obj, FALSE
+		}
+
+		if mapObj, ok := obj.(*object.MapObject); ok {
+			key := Eval(expr.Index, env)
+			if isError(key) {
+				return key, FALSE
+			}
+
+			return evalMapIndex(mapObj, key)
+		}
+	}
+
+	return newError("assignment mismatch: 2 variables but %s returns 1 value", expr.String()), FALSE
+}
+
+// evalReadChanExpression receives a value from a channel. A closed channel yields
+// the zero value of its element type and false.
+func evalReadChanExpression(node *ast.ReadChanExpression, env *object.Environment) (object.Object, *object.Boolean) {
+	obj := Eval(node.Source, env)
+	if isError(obj) {
+		return obj, FALSE
+	}
+
+	chn := object.Unwrap(obj).(*object.ChanObject)
+	val, ok := <-chn.Chan
+	if !ok {
+		return analyzer.NativeTypeToDefaultObj(chn.ChanType), FALSE
+	}
+
+	return val, TRUE
+}
+
+func evalInitAssignStatement(node *ast.InitAssignStatement, env *object.Environment) object.Object {
+	values := evalAssignedValues(node.Value, len(node.Names), env)
+	if len(values) == 1 && isError(values[0]) {
+		return values[0]
+	}
+
+	return declareValues(node.Names, values, env)
+}
+
+// declareValues declares the new names of a := statement and assigns the existing ones.
+func declareValues(names []*ast.Identifier, values []object.Object, env *object.Environment) object.Object {
+	if name, ok := ast.RepeatedName(names); ok {
+		return newError("%s repeated on left side of :=", name)
+	}
+
+	declared := 0
+	for i, name := range names {
+		if name.Value == "_" {
+			continue
+		}
+
+		if current, ok := env.GetLocal(name.Value); ok {
+			env.Update(name.Value, object.Convert(copyValue(values[i]), current.Type()))
+			continue
+		}
+
+		env.Set(name.Value, copyValue(values[i]))
+		declared++
+	}
+
+	if declared == 0 {
+		return newError("no new variables on left side of :=")
+	}
+
+	return NIL
+}
+
+// evalConstStatement declares constants, whose values the analyzer folded into literals.
+// The analyzer of later statements needs to know they are constants.
+func evalConstStatement(node *ast.ConstStatement, env *object.Environment) object.Object {
+	for _, spec := range node.Specs {
+		val := Eval(spec.Value, env)
+		if isError(val) {
+			return val
+		}
+
+		// Untyped constants keep their exact value.
+		constant := &object.Constant{Value: analyzer.ConstantValue(spec.Value)}
+		if spec.Name.DataType != nil {
+			constant.Value = object.Convert(val, *spec.Name.DataType)
+			constant.DataType = *spec.Name.DataType
+		}
+
+		env.Set(spec.Name.Value, constant)
+	}
+
+	return NIL
+}
+
+func evalAssignStatement(node *ast.AssignStatement, env *object.Environment) object.Object {
+	setters, err := evalAssignTargets(node.Targets, env)
+	if err != nil {
+		return err
+	}
+
+	values := evalAssignedValues(node.Value, len(node.Targets), env)
+	if len(values) == 1 && isError(values[0]) {
+		return values[0]
+	}
+
+	return assignValues(setters, values)
+}
+
+// setter stores a value in the location denoted by an assignment target.
+type setter func(val object.Object) object.Object
+
+// evalAssignTargets evaluates the operands of the index expressions and pointer
+// indirections of targets, before any value is assigned.
+func evalAssignTargets(targets []ast.Expression, env *object.Environment) ([]setter, object.Object) {
+	setters := make([]setter, 0, len(targets))
+	for _, target := range targets {
+		set, err := evalAssignTarget(target, env)
+		if err != nil {
+			return nil, err
+		}
+
+		setters = append(setters, set)
+	}
+
+	return setters, nil
+}
+
+func evalAssignTarget(target ast.Expression, env *object.Environment) (setter, object.Object) {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		return evalIdentifierTarget(target, env), nil
+	case *ast.IndexExpression:
+		return evalIndexTarget(target, env)
+	case *ast.SelectorExpression:
+		return evalFieldTarget(target, env)
+	case *ast.PrefixExpression:
+		if target.Operator == "*" {
+			return evalPointerTarget(target, env)
+		}
+	}
+
+	return nil, newError("cannot assign to %s", target.String())
+}
+
+func evalIdentifierTarget(target *ast.Identifier, env *object.Environment) setter {
+	return func(val object.Object) object.Object {
+		if target.Value == "_" {
+			return NIL
+		}
+
+		cell, ok := env.Ref(target.Value)
+		if !ok {
+			return newError("unknown variable: %q", target.Value)
+		}
+
+		storeValue(cell, object.Convert(val, (*cell).Type()))
+		return NIL
+	}
+}
+
+func evalIndexTarget(target *ast.IndexExpression, env *object.Environment) (setter, object.Object) {
+	obj := Eval(target.Left, env)
+	if isError(obj) {
+		return nil, obj
+	}
+
+	index := Eval(target.Index, env)
+	if isError(index) {
+		return nil, index
+	}
+
+	switch obj := obj.(type) {
+	case *object.MapObject:
+		index = mapKey(index, obj.KeyType)
+		hashable, ok := object.HashableKey(index)
+		if !ok {
+			return nil, newError("unusable as map key: %s", object.Unwrap(index).Type().Name())
+		}
+
+		if obj.Pairs == nil {
+			return nil, newError("assignment to entry in nil map '%s'", target.Left.String())
+		}
+
+		return func(val object.Object) object.Object {
+			obj.Pairs[hashable.HashKey()] = object.MapPair{Key: index, Value: object.Convert(val, obj.ValueType)}
+			return NIL
+		}, nil
+	case *object.SliceObject:
+		intIndex, ok := index.(*object.Integer)
+		if !ok {
+			return nil, newError("expected integer type for index expression, got=%T", index)
+		}
+
+		if intIndex.Value < 0 || int64(len(obj.Values)) <= intIndex.Value {
+			return nil, newError("out of bound error for slice '%s' at index %d", target.Left.String(), intIndex.Value)
+		}
+
+		return func(val object.Object) object.Object {
+			storeValue(&obj.Values[intIndex.Value], object.Convert(val, obj.ValueType))
+			return NIL
+		}, nil
+	default:
+		return nil, newError("expected slice or map object for index expression, got=%T", obj)
+	}
+}
+
+func evalFieldTarget(target *ast.SelectorExpression, env *object.Environment) (setter, object.Object) {
+	obj := Eval(target.Left, env)
+	if isError(obj) {
+		return nil, obj
+	}
+
+	if pkg, ok := obj.(*object.Package); ok {
+		cell, ok := pkg.Ref(target.Field.Value)
+		if !ok {
+			return nil, newError("cannot assign to %s", target.String())
+		}
+
+		return func(val object.Object) object.Object {
+			storeValue(cell, object.Convert(val, (*cell).Type()))
+			return NIL
+		}, nil
+	}
+
+	if ref, ok := obj.(*object.ReferenceObject); ok {
+		if ref.Value == nil {
+			return nil, newError("invalid memory address or nil pointer dereference: %s", target.String())
+		}
+
+		obj = *ref.Value
+	}
+
+	structObj, ok := obj.(*object.StructObject)
+	if !ok {
+		return nil, newError("expected struct object for field assignment, got=%T", obj)
+	}
+
+	cell, ok := structObj.Fields[target.Field.Value]
+	if !ok {
+		return nil, newError("%s undefined (type %s has no field %s)", target.String(), structObj.Type().Name(), target.Field.Value)
+	}
+
+	return func(val object.Object) object.Object {
+		storeValue(cell, object.Convert(val, (*cell).Type()))
+		return NIL
+	}, nil
+}
+
+func evalPointerTarget(target *ast.PrefixExpression, env *object.Environment) (setter, object.Object) {
+	obj := Eval(target.Right, env)
+	if isError(obj) {
+		return nil, obj
+	}
+
+	ref, ok := obj.(*object.ReferenceObject)
+	if !ok {
+		return nil, newError("expected reference: %s", obj.Inspect())
+	}
+
+	if ref.Value == nil {
+		return nil, newError("invalid memory address or nil pointer dereference: %s", target.String())
+	}
+
+	return func(val object.Object) object.Object {
+		storeValue(ref.Value, object.Convert(val, (*ref.Value).Type()))
+		return NIL
+	}, nil
+}
+
+// storeValue stores val in cell. A struct stored over a struct of the same type is
+// stored field by field, so that pointers to its fields stay valid.
+func storeValue(cell *object.Object, val object.Object) {
+	current, ok := (*cell).(*object.StructObject)
+	structObj, ok2 := val.(*object.StructObject)
+	if !ok || !ok2 || !ast.Identical(current.Type(), structObj.Type()) {
+		*cell = val
+		return
+	}
+
+	for name, fieldCell := range current.Fields {
+		storeValue(fieldCell, *structObj.Fields[name])
+	}
+}
+
+// assignValues stores values through setters. All values are copied before any of
+// them is stored, so that 'a, b = b, a' swaps.
+func assignValues(setters []setter, values []object.Object) object.Object {
+	copies := make([]object.Object, len(values))
+	for i, val := range values {
+		copies[i] = copyValue(val)
+	}
+
+	for i, set := range setters {
+		if result := set(copies[i]); isError(result) {
+			return result
+		}
+	}
+
+	return NIL
+}
+
+// evalAssignedValues evaluates the values assigned to count variables. All values are
+// evaluated before any of them is assigned.
+func evalAssignedValues(expr ast.Expression, count int, env *object.Environment) []object.Object {
+	switch expr := expr.(type) {
+	case *ast.BashExpression:
+		if count == 2 {
+			out, err := runBashExpression(expr, env)
+			return []object.Object{out, object.Convert(err, parser.ERROR_INTERFACE)}
+		}
+	case *ast.TypeAssertionExpression, *ast.IndexExpression, *ast.ReadChanExpression:
+		if count == 2 {
+			val, ok := evalCommaOkExpression(expr, env)
+			if isError(val) {
+				return []object.Object{val}
+			}
+
+			return []object.Object{val, ok}
+		}
+	}
+
+	val := Eval(expr, env)
+	if isError(val) {
+		return []object.Object{val}
+	}
+
+	values := []object.Object{val}
+	if tuple, ok := val.(*object.Tuple); ok {
+		values = tuple.Values
+	}
+
+	if len(values) != count {
+		return []object.Object{newError("assignment mismatch: %d variables but %d values", count, len(values))}
+	}
+
+	return values
+}
+
+func evalBashVarExpression(node *ast.BashVarExpression, env *object.Environment) object.Object {
+	num, ok := strconv.Atoi(node.Value[1:])
+	if ok == nil {
+		if len(os.Args) <= num+1 {
+			return newError("provided %d args but %d arg was called", len(os.Args)-1, num)
+		}
+		return &object.String{
+			Value: os.Args[num+1],
+		}
+	}
+
+	output, err := exec.Command("bash", "-c", "echo "+node.Value).Output()
+	if err != nil {
+		return newError("bash error %s", err.Error())
+	}
+
+	return &object.String{Value: string(output)}
+}
+
+func labelName(label *ast.Identifier) string {
+	if label == nil {
+		return ""
+	}
+
+	return label.Value
+}
+
+func evalLabeledStatement(stmt *ast.LabeledStatement, env *object.Environment) object.Object {
+	switch inner := stmt.Statement.(type) {
+	case *ast.ForStatement:
+		return evalForStatement(inner, stmt.Label.Value, env)
+	case *ast.ForRangeStatement:
+		return evalForRangeStatement(inner, stmt.Label.Value, env)
+	}
+
+	result := Eval(stmt.Statement, env)
+	if brk, ok := result.(*object.BreakObject); ok && brk.Label == stmt.Label.Value {
+		return NIL
+	}
+
+	return result
+}
+
+// evalLoopBody runs one iteration of the loop labeled label and reports whether
+// the loop must stop, together with the result of the whole loop. Branches that
+// target an enclosing loop stop this one and are passed on.
+func evalLoopBody(body *ast.BlockStatement, label string, env *object.Environment) (object.Object, bool) {
+	switch result := Eval(body, env).(type) {
+	case nil:
+		return NIL, false
+	case *object.BreakObject:
+		if result.Label == "" || result.Label == label {
+			return NIL, true
+		}
+
+		return result, true
+	case *object.ContinueObject:
+		if result.Label == "" || result.Label == label {
+			return NIL, false
+		}
+
+		return result, true
+	default:
+		if result.Type() == parser.RETURN || isError(result) {
+			return result, true
+		}
+
+		return NIL, false
+	}
+}
+
+func evalForStatement(stmt *ast.ForStatement, label string, env *object.Environment) object.Object {
+	env = object.NewEnclosedEnvironment(env)
+
+	var names []string
+	if stmt.Init != nil {
+		if result := Eval(stmt.Init, env); isError(result) {
+			return result
+		}
+
+		if init, ok := stmt.Init.(*ast.InitAssignStatement); ok {
+			for _, name := range init.Names {
+				if name.Value != "_" {
+					names = append(names, name.Value)
+				}
+			}
+		}
+	}
+
+	for {
+		if stmt.Condition != nil {
+			condition := Eval(stmt.Condition, env)
+			if isError(condition) {
+				return condition
+			}
+
+			if condition != TRUE {
+				return NIL
+			}
+		}
+
+		// every iteration gets its own copy of the variables declared in the init
+		// statement, so closures created in the body capture that iteration's values
+		iterEnv := object.NewEnclosedEnvironment(env)
+		for _, name := range names {
+			value, _ := env.Get(name)
+			iterEnv.Set(name, value)
+		}
+
+		result, done := evalLoopBody(stmt.Consequence, label, iterEnv)
+		for _, name := range names {
+			value, _ := iterEnv.Get(name)
+			env.Update(name, value)
+		}
+
+		if done {
+			return result
+		}
+
+		if stmt.Post != nil {
+			if result := Eval(stmt.Post, env); isError(result) {
+				return result
+			}
+		}
+	}
+}
+
+func evalForRangeStatement(stmt *ast.ForRangeStatement, label string, env *object.Environment) object.Object {
+	iterable := object.Unwrap(Eval(stmt.Iterable, env))
+	if isError(iterable) {
+		return iterable
+	}
+
+	// iterate runs the loop body with fresh iteration variables and reports whether
+	// the loop must stop, together with the result of the whole loop.
+	iterate := func(key, value object.Object) (object.Object, bool) {
+		iterEnv := object.NewEnclosedEnvironment(env)
+		if stmt.Key != nil && stmt.Key.Value != "_" {
+			iterEnv.Set(stmt.Key.Value, copyValue(key))
+		}
+
+		if stmt.Value != nil && stmt.Value.Value != "_" {
+			iterEnv.Set(stmt.Value.Value, copyValue(value))
+		}
+
+		return evalLoopBody(stmt.Body, label, iterEnv)
+	}
+
+	switch iterable := iterable.(type) {
+	case *object.SliceObject:
+		for i, value := range iterable.Values {
+			if result, done := iterate(&object.Integer{Value: int64(i)}, value); done {
+				return result
+			}
+		}
+	case *object.MapObject:
+		for _, pair := range iterable.SortedPairs() {
+			if result, done := iterate(pair.Key, pair.Value); done {
+				return result
+			}
+		}
+	case *object.String:
+		for i, r := range iterable.Value {
+			if result, done := iterate(&object.Integer{Value: int64(i)}, object.NewInteger(int64(r), parser.RUNE)); done {
+				return result
+			}
+		}
+	case *object.ChanObject:
+		for value := range iterable.Chan {
+			if result, done := iterate(value, nil); done {
+				return result
+			}
+		}
+	case *object.Integer:
+		for i := int64(0); i < iterable.Value; i++ {
+			if result, done := iterate(&object.Integer{Value: i}, nil); done {
+				return result
+			}
+		}
+	default:
+		return newError("cannot range over %s", iterable.Type().Name())
+	}
+
+	return NIL
+}
+
+// runProgram evaluates the main program. It ends as soon as a goroutine fails, with
+// the error of that goroutine as its result, even if the program is blocked.
+func runProgram(program *ast.Program, env *object.Environment) object.Object {
+	done := make(chan object.Object, 1)
+	go func() {
+		done <- evalProgram(program, env)
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case err := <-goroutineErrors:
+		return err
+	}
+}
+
+func evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	result := evalStatements(program, env)
+	result, _ = runDeferred(env, result)
+
+	return result
+}
+
+// evalStatements evaluates the top-level statements of a program.
+func evalStatements(program *ast.Program, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range program.Statements {
+		env = object.NewSameScopeEnvironment(env)
+		errors := analyzer.AnalyzeStatement(statement, parser.ANY, env)
+		env = object.UnwrapEnvironment(env)
+		if len(errors) != 0 {
+			return newError("analyzer error %s", errors[0])
+		}
+
+		result = Eval(statement, env)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return result.Value
+		case *object.Error:
+			return result
+		}
+	}
+
+	return result
+}
+
+// evalImportStatement binds the imported packages in env. A package is loaded once,
+// however often it is imported, and its methods become callable in env. Predeclared
+// packages like strconv may be imported too.
+func evalImportStatement(node *ast.ImportStatement, env *object.Environment) object.Object {
+	for _, spec := range node.Specs {
+		pkg, errObj := importPackage(spec.Path, env.Dir())
+		if errObj != nil {
+			return errObj
+		}
+
+		if pkg.Env != nil {
+			env.ImportMethods(pkg.Env)
+		}
+
+		name := pkg.Name
+		if spec.Name != nil {
+			name = spec.Name.Value
+		}
+
+		if name != "_" {
+			env.Set(name, pkg)
+		}
+	}
+
+	return NIL
+}
+
+func importPackage(path, dir string) (*object.Package, object.Object) {
+	if pkg, ok := object.Packages[path]; ok {
+		return pkg, nil
+	}
+
+	key, files := importFiles(path, dir)
+	if files == nil {
+		return nil, newError("cannot find package %q", path)
+	}
+
+	pkg, ok := importedPackages[key]
+	switch {
+	case ok && pkg == nil:
+		return nil, newError("import cycle not allowed: %q", path)
+	case ok:
+		return pkg, nil
+	}
+
+	importedPackages[key] = nil
+	pkg, errObj := loadPackage(key, files)
+	if errObj != nil {
+		delete(importedPackages, key)
+		return nil, newError("import %q: %s", path, errObj.(*object.Error).Message)
+	}
+
+	importedPackages[key] = pkg
+	return pkg, nil
+}
+
+// importFiles returns the absolute path and the files of the package imported as
+// path. Paths starting with ./ or ../ are relative to dir, the directory of the
+// importing file. Other relative paths are looked up in the directories listed in
+// GOSHAPATH.
+func importFiles(path, dir string) (string, []string) {
+	var candidates []string
+	switch {
+	case filepath.IsAbs(path):
+		candidates = []string{path}
+	case strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../"):
+		candidates = []string{filepath.Join(dir, path)}
+	default:
+		for _, root := range filepath.SplitList(os.Getenv("GOSHAPATH")) {
+			candidates = append(candidates, filepath.Join(root, path))
+		}
+	}
+
+	for _, candidate := range candidates {
+		// A package is a single file, with or without the .gosha extension, or a
+		// directory of .gosha files.
+		for _, name := range []string{candidate, candidate + ".gosha"} {
+			info, err := os.Stat(name)
+			if err != nil {
+				continue
+			}
+
+			key, err := filepath.Abs(name)
+			if err != nil {
+				continue
+			}
+
+			if !info.IsDir() {
+				return key, []string{key}
+			}
+
+			files, _ := filepath.Glob(filepath.Join(key, "*.gosha"))
+			if len(files) != 0 {
+				return key, files
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// loadPackage evaluates the files of a package in a new environment. The package is
+// named by its package clause, or else after its file or directory.
+func loadPackage(path string, files []string) (*object.Package, object.Object) {
+	env := object.NewEnvironment()
+	env.SetPackagePath(path)
+	pkg := &object.Package{Name: strings.TrimSuffix(filepath.Base(path), ".gosha"), Env: env}
+
+	var programs []*ast.Program
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, newError("%s", err)
+		}
+
+		// Package files may be scripts starting with a shebang line.
+		input := string(data)
+		if strings.HasPrefix(input, "#!") {
+			input = input[strings.IndexByte(input+"\n", '\n'):]
+		}
+
+		p := parser.New(lexer.New(input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			return nil, newError("%s: %s", filepath.Base(file), p.Errors()[0])
+		}
+
+		if len(program.Statements) != 0 {
+			if clause, ok := program.Statements[0].(*ast.PackageStatement); ok {
+				if i != 0 && clause.Name.Value != pkg.Name {
+					return nil, newError("found packages %s and %s in %s", pkg.Name, clause.Name.Value, path)
+				}
+
+				pkg.Name = clause.Name.Value
+			}
+		}
+
+		programs = append(programs, program)
+	}
+
+	if pkg.Name == "main" {
+		return nil, newError("cannot import package main")
+	}
+
+	for i, program := range programs {
+		// Types declared by the package are named after it, so that they and their
+		// methods don't clash with those of the importing file or other packages.
+		for _, stmt := range program.Statements {
+			if stmt, ok := stmt.(*ast.TypeStatement); ok {
+				stmt.Type.Package = pkg.Name
+				stmt.Type.Path = path
+			}
+		}
+
+		env.SetDir(filepath.Dir(files[i]))
+		if result := evalProgram(program, env); isError(result) {
+			return nil, result
+		}
+	}
+
+	return pkg, nil
+}
+
+func evalBashExpression(expr *ast.BashExpression, env *object.Environment) object.Object {
+	out, err := runBashExpression(expr, env)
+	if err != NIL {
+		return newError("bash error %s", err.Inspect())
+	}
+
+	return out
+}
+
+// runBashExpression runs a shell command and returns its output together with an
+// error value that is NIL if the command succeeded.
+func runBashExpression(expr *ast.BashExpression, env *object.Environment) (*object.String, object.Object) {
+	var bashArgs []string
+	for _, arg := range expr.Value {
+		if arg[0] == '$' && env.Contains(arg[1:]) {
+			arg, _ := env.Get(arg[1:])
+			bashArgs = append(bashArgs, arg.Inspect())
+		} else {
+			bashArgs = append(bashArgs, arg)
+		}
+	}
+
+	result, ok := isBashCommandInteractive[bashArgs[0]]
+	if ok && result {
+		cmd := exec.Command("bash", "-c", strings.Join(bashArgs, " "))
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		cmd.SysProcAttr = &syscall.SysProcAttr{
+			Setsid: true,
+			Ctty:   int(os.Stdin.Fd()),
+		}
+
+		err := cmd.Run()
+
+		return &object.String{Value: ""}, commandError(err)
+	}
+
+	output, err := exec.Command("bash", "-c", strings.Join(bashArgs, " ")).Output()
+
+	return &object.String{Value: string(output)}, commandError(err)
+}
 
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			Setsid: true,
-			Ctty:   int(os.Stdin.Fd()),
+// commandError converts an error of a finished command into an error value.
+func commandError(err error) object.Object {
+	if err == nil {
+		return NIL
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return &object.CommandError{
+			ExitCode: exitErr.ExitCode(),
+			Stderr:   strings.TrimSpace(string(exitErr.Stderr)),
 		}
+	}
 
-		err := cmd.Run()
+	return &object.ErrorValue{Message: err.Error()}
+}
 
-		if err != nil {
-			return newError("bash error %s", err.Error())
-		}
-	} else {
-		output, err := exec.Command("bash", "-c", strings.Join(bashArgs, " ")).Output()
-		if err != nil {
-			return newError("bash error %s", err.Error())
+// evalInterpolatedString formats the values embedded in f"..." with Inspect.
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+	for _, part := range node.Parts {
+		value := Eval(part, env)
+		if isError(value) {
+			return value
 		}
 
-		return &object.String{Value: string(output)}
+		out.WriteString(value.Inspect())
 	}
 
-	return &object.String{Value: ""}
+	return &object.String{Value: out.String()}
 }
 
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
@@ -326,22 +1916,181 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 func applyFunction(fn object.Object, args []object.Object, env *object.Environment) object.Object {
 	switch fn := fn.(type) {
 	case *object.Builtin:
-		return fn.Fn(args...)
+		result := fn.Fn(args...)
+		if isError(result) {
+			return result
+		}
+
+		return object.Convert(result, fn.ReturnType)
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		return callFunction(fn, args, nil)
+	case *object.NilFunction:
+		return newError("invalid memory address or nil pointer dereference: call of nil function")
+	case *object.DataTypeObject:
+		return evalConversion(args[0], fn.DataType)
 	default:
 		return newError("not a function: %s", fn.Type().Name())
 	}
 
 }
 
+// evalConversion converts obj to dType. Conversions between integers and floats
+// truncate towards zero, and strings convert to and from bytes and runes.
+func evalConversion(obj object.Object, dType ast.DataType) object.Object {
+	obj = object.Unwrap(obj)
+
+	switch targetType := ast.Underlying(dType).(type) {
+	case *ast.IntegerDataType, *ast.ByteDataType, *ast.RuneDataType:
+		switch obj := obj.(type) {
+		case *object.Float:
+			return object.NewInteger(int64(obj.Value), dType)
+		case *object.Integer:
+			return object.NewInteger(obj.Value, dType)
+		}
+	case *ast.FloatDataType:
+		if integer, ok := obj.(*object.Integer); ok {
+			return object.NewFloat(float64(integer.Value), dType)
+		}
+	case *ast.StringDataType:
+		switch obj := obj.(type) {
+		case *object.Integer:
+			return object.NewString(string(rune(obj.Value)), dType)
+		case *object.SliceObject:
+			return object.NewString(sliceToString(obj), dType)
+		}
+	case *ast.SliceDataType:
+		if str, ok := obj.(*object.String); ok {
+			return stringToSlice(str.Value, targetType.Type)
+		}
+	}
+
+	return object.Convert(copyValue(obj), dType)
+}
+
+// sliceToString converts a []byte or a []rune to a string.
+func sliceToString(slice *object.SliceObject) string {
+	if _, ok := ast.Underlying(slice.ValueType).(*ast.RuneDataType); ok {
+		runes := make([]rune, len(slice.Values))
+		for i, value := range slice.Values {
+			runes[i] = rune(value.(*object.Integer).Value)
+		}
+
+		return string(runes)
+	}
+
+	bytes := make([]byte, len(slice.Values))
+	for i, value := range slice.Values {
+		bytes[i] = byte(value.(*object.Integer).Value)
+	}
+
+	return string(bytes)
+}
+
+// stringToSlice converts str to a []byte or a []rune.
+func stringToSlice(str string, elemType ast.DataType) *object.SliceObject {
+	slice := &object.SliceObject{ValueType: elemType, Values: []object.Object{}}
+	if _, ok := ast.Underlying(elemType).(*ast.RuneDataType); ok {
+		for _, r := range str {
+			slice.Values = append(slice.Values, object.NewInteger(int64(r), elemType))
+		}
+
+		return slice
+	}
+
+	for i := 0; i < len(str); i++ {
+		slice.Values = append(slice.Values, object.NewInteger(int64(str[i]), elemType))
+	}
+
+	return slice
+}
+
+// callFunction calls fn and then the calls it deferred. p is the panic that fn may
+// recover when fn itself is a deferred call, and nil otherwise.
+func callFunction(fn *object.Function, args []object.Object, p *object.Panic) object.Object {
+	extendedEnv := extendFunctionEnv(fn, args)
+	extendedEnv.SetPanic(p)
+
+	returnType := runtimeDataType(fn.ReturnType, extendedEnv)
+	evaluated := unwrapReturnValue(Eval(fn.Body, extendedEnv))
+	evaluated, recovered := runDeferred(extendedEnv, evaluated)
+	if recovered {
+		evaluated = zeroValue(returnType)
+	}
+
+	if isError(evaluated) {
+		return evaluated
+	}
+
+	return object.Convert(evaluated, returnType)
+}
+
+// runDeferred runs the calls deferred in the function scope of env in reverse order
+// and returns the final result of the function. It reports whether a deferred call
+// recovered the panic, i.e. the error, that result held.
+func runDeferred(env *object.Environment, result object.Object) (object.Object, bool) {
+	deferred := env.TakeDeferred()
+	recovered := false
+
+	for i := len(deferred) - 1; i >= 0; i-- {
+		p := &object.Panic{}
+		if err, ok := result.(*object.Error); ok {
+			p.Error = err
+		}
+
+		deferredResult := deferred[i](p)
+		if p.Recovered {
+			result = NIL
+			recovered = true
+		}
+
+		// a panic in a deferred call replaces the current one
+		if isError(deferredResult) {
+			result = deferredResult
+			recovered = false
+		}
+	}
+
+	return result, recovered
+}
+
+// evalRecover stops the panic of the function call that deferred the function
+// calling recover and returns the panic value. It returns nil if there is no such panic.
+func evalRecover(env *object.Environment) object.Object {
+	p := env.Panic()
+	if p == nil || p.Error == nil || p.Recovered {
+		return object.Convert(NIL, parser.ANY)
+	}
+
+	p.Recovered = true
+	if p.Error.Value != nil {
+		return object.Convert(p.Error.Value, parser.ANY)
+	}
+
+	// runtime errors are recovered as error values
+	return object.Convert(&object.ErrorValue{Message: p.Error.Message}, parser.ANY)
+}
+
+func zeroValue(dType ast.DataType) object.Object {
+	if tupleType, ok := dType.(*ast.TupleDataType); ok {
+		values := make([]object.Object, len(tupleType.Types))
+		for i, elemType := range tupleType.Types {
+			values[i] = object.Convert(analyzer.NativeTypeToDefaultObj(elemType), elemType)
+		}
+
+		return &object.Tuple{Values: values}
+	}
+
+	return analyzer.NativeTypeToDefaultObj(dType)
+}
+
 func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
-	env := object.NewEnclosedEnvironment(fn.Env)
+	env := object.NewFunctionEnvironment(fn.Env)
+	for i, typeArg := range fn.TypeArgs {
+		env.Set(fn.TypeParams[i].TypeName, &object.DataTypeObject{DataType: typeArg})
+	}
 
 	for argc, arg := range fn.Parameters {
-		env.Set(arg.Value, args[argc])
+		env.Set(arg.Value, object.Convert(copyValue(args[argc]), runtimeDataType(*arg.DataType, env)))
 	}
 
 	return env
@@ -357,6 +2106,10 @@ func unwrapReturnValue(obj object.Object) object.Object {
 
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
 	val, ok := env.Get(node.Value)
+	if constant, isConstant := val.(*object.Constant); isConstant {
+		return constant.Default()
+	}
+
 	if ok {
 		return val
 	}
@@ -366,6 +2119,11 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return builtin
 	}
 
+	pkg, ok := object.Packages[node.Value]
+	if ok {
+		return pkg
+	}
+
 	return newError("unknown identifier: %s", node.Value)
 }
 
@@ -376,12 +2134,9 @@ func evalBlockStatement(bs *ast.BlockStatement, env *object.Environment) object.
 	for _, statement := range bs.Statements {
 		result = Eval(statement, env)
 
-		if result != nil {
-			rt := result.Type()
-			if rt == parser.RETURN || rt == parser.ERROR {
-				env = object.UnwrapEnvironment(env)
-				return result
-			}
+		if interruptsBlock(result) {
+			env = object.UnwrapEnvironment(env)
+			return result
 		}
 	}
 
@@ -389,6 +2144,16 @@ func evalBlockStatement(bs *ast.BlockStatement, env *object.Environment) object.
 	return result
 }
 
+// interruptsBlock reports whether result ends the enclosing block early.
+func interruptsBlock(result object.Object) bool {
+	if result == nil {
+		return false
+	}
+
+	rt := result.Type()
+	return rt == parser.RETURN || rt == parser.ERROR || rt == parser.BREAK || rt == parser.CONTINUE
+}
+
 func isError(obj object.Object) bool {
 	if obj != nil {
 		return obj.Type() == parser.ERROR
@@ -413,11 +2178,31 @@ func evalIfExpression(ie *ast.IfStatement, env *object.Environment) object.Objec
 }
 
 func evalInfixExpression(operator string, left, right object.Object) object.Object {
+	if (operator == token.EQ || operator == token.NEQ) && (isInterface(left) || isInterface(right)) {
+		return evalInterfaceInfixExpression(operator, left, right)
+	}
+
+	left = object.Unwrap(left)
+	right = object.Unwrap(right)
+
 	switch {
-	case left.Type() == parser.INT && right.Type() == parser.INT:
+	case isInteger(left) && isInteger(right):
 		return evalIntegerInfixExpression(operator, left, right)
-	case left.Type() == parser.STRING && right.Type() == parser.STRING:
+	case isFloat(left) && isFloat(right):
+		return evalFloatInfixExpression(operator, left, right)
+	case isFloat(left) && isInteger(right):
+		// The analyzer only lets integer constants meet floats.
+		return evalFloatInfixExpression(operator, left, evalConversion(right, parser.FLOAT64))
+	case isInteger(left) && isFloat(right):
+		return evalFloatInfixExpression(operator, evalConversion(left, parser.FLOAT64), right)
+	case isString(left) && isString(right):
 		return evalStringInfixExpression(operator, left, right)
+	case left.Type() == parser.NIL || right.Type() == parser.NIL:
+		return evalNilInfixExpression(operator, left, right)
+	case isReference(left) && isReference(right):
+		return evalReferenceInfixExpression(operator, left, right)
+	case isStruct(left) && isStruct(right):
+		return evalStructInfixExpression(operator, left, right)
 	case operator == token.EQ:
 		return rawBooleanToBooleanObject(left == right)
 	case operator == token.NEQ:
@@ -431,12 +2216,147 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	}
 }
 
+func isInterface(obj object.Object) bool {
+	_, ok := obj.(*object.Interface)
+	return ok
+}
+
+// evalInterfaceInfixExpression compares operands of which at least one is an
+// interface. They are equal if their dynamic types and values are. An interface is
+// only nil if it holds no value, not even a nil pointer.
+func evalInterfaceInfixExpression(operator string, left, right object.Object) object.Object {
+	leftValue, rightValue := object.Unwrap(left), object.Unwrap(right)
+	var equal bool
+	switch {
+	case isUntypedNil(left) || isUntypedNil(right):
+		equal = isUntypedNil(leftValue) && isUntypedNil(rightValue)
+	case !ast.Identical(leftValue.Type(), rightValue.Type()):
+		equal = false
+	case !isComparableType(leftValue.Type()):
+		return newError("runtime error: comparing uncomparable type %s", leftValue.Type().Name())
+	default:
+		return evalInfixExpression(operator, leftValue, rightValue)
+	}
+
+	if operator == token.NEQ {
+		return rawBooleanToBooleanObject(!equal)
+	}
+
+	return rawBooleanToBooleanObject(equal)
+}
+
+// isComparableType reports whether dynamic values of dType may be compared. The
+// analyzer can't rule out comparisons of slices, maps and functions stored in
+// interfaces, which panic like in Go.
+func isComparableType(dType ast.DataType) bool {
+	switch dType := ast.Underlying(dType).(type) {
+	case *ast.SliceDataType, *ast.MapDataType, *ast.FunctionDataType:
+		return false
+	case *ast.StructDataType:
+		for _, field := range dType.Fields {
+			if !isComparableType(field.Type) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isUntypedNil reports whether obj is nil itself rather than the nil value of a type.
+func isUntypedNil(obj object.Object) bool {
+	_, ok := obj.(*object.Nil)
+	return ok
+}
+
+func evalNilInfixExpression(operator string, left, right object.Object) object.Object {
+	switch operator {
+	case token.EQ:
+		return rawBooleanToBooleanObject(isNil(left) == isNil(right))
+	case token.NEQ:
+		return rawBooleanToBooleanObject(isNil(left) != isNil(right))
+	default:
+		return newError("unknown operator: %s %s %s", left.Type().Name(), operator, right.Type().Name())
+	}
+}
+
+// isNil reports whether obj is nil or the nil value of a function, pointer, slice,
+// map or channel type.
+func isNil(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case *object.ReferenceObject:
+		return obj.Value == nil
+	case *object.SliceObject:
+		return obj.Values == nil
+	case *object.MapObject:
+		return obj.Pairs == nil
+	case *object.ChanObject:
+		return obj.Chan == nil
+	case *object.NilFunction:
+		return true
+	}
+
+	return obj.Type() == parser.NIL
+}
+
+func isReference(obj object.Object) bool {
+	_, ok := obj.(*object.ReferenceObject)
+	return ok
+}
+
+// evalReferenceInfixExpression compares pointers, which are equal if they point to the
+// same variable or are both nil.
+func evalReferenceInfixExpression(operator string, left, right object.Object) object.Object {
+	leftCell := left.(*object.ReferenceObject).Value
+	rightCell := right.(*object.ReferenceObject).Value
+	switch operator {
+	case token.EQ:
+		return rawBooleanToBooleanObject(leftCell == rightCell)
+	case token.NEQ:
+		return rawBooleanToBooleanObject(leftCell != rightCell)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type().Name(), operator, right.Type().Name())
+	}
+}
+
+func isStruct(obj object.Object) bool {
+	_, ok := obj.(*object.StructObject)
+	return ok
+}
+
+// evalStructInfixExpression compares structs, which are equal if their corresponding
+// fields are equal.
+func evalStructInfixExpression(operator string, left, right object.Object) object.Object {
+	equal := true
+	for name, leftField := range left.(*object.StructObject).Fields {
+		rightField, _ := right.(*object.StructObject).Field(name)
+		result := evalInfixExpression(token.EQ, *leftField, rightField)
+		if isError(result) {
+			return result
+		}
+
+		if result != TRUE {
+			equal = false
+			break
+		}
+	}
+
+	switch operator {
+	case token.EQ:
+		return rawBooleanToBooleanObject(equal)
+	case token.NEQ:
+		return rawBooleanToBooleanObject(!equal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type().Name(), operator, right.Type().Name())
+	}
+}
+
 func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
 	switch operator {
 	case "+":
-		return &object.String{Value: leftVal + rightVal}
+		return object.NewString(leftVal+rightVal, namedOperandType(left, right))
 	case "==":
 		if leftVal == rightVal {
 			return TRUE
@@ -449,6 +2369,14 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 		} else {
 			return FALSE
 		}
+	case token.LT:
+		return rawBooleanToBooleanObject(leftVal < rightVal)
+	case token.GT:
+		return rawBooleanToBooleanObject(leftVal > rightVal)
+	case token.LTEQ:
+		return rawBooleanToBooleanObject(leftVal <= rightVal)
+	case token.GTEQ:
+		return rawBooleanToBooleanObject(leftVal >= rightVal)
 	default:
 		return newError("unsupported operator: %s %s %s", left.Type().Name(), operator, right.Type().Name())
 	}
@@ -470,21 +2398,69 @@ func evalBooleanInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
+func isInteger(obj object.Object) bool {
+	_, ok := obj.(*object.Integer)
+	return ok
+}
+
+func isFloat(obj object.Object) bool {
+	_, ok := obj.(*object.Float)
+	return ok
+}
+
+func isString(obj object.Object) bool {
+	_, ok := obj.(*object.String)
+	return ok
+}
+
+// namedOperandType returns the type of the result of an operation on left and right.
+// An operand of a named type passes its type on to a constant operand.
+func namedOperandType(left, right object.Object) ast.DataType {
+	if _, ok := left.Type().(*ast.NamedDataType); ok {
+		return left.Type()
+	}
+
+	return right.Type()
+}
+
+// evalIntegerInfixExpression evaluates operators on integers. The analyzer only lets
+// an int meet a byte or a rune if it is a constant, which then takes their type.
 func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
+	dType := left.Type()
+	if dType == parser.INT {
+		dType = right.Type()
+	}
+
 	switch operator {
 	case token.PLUS:
-		return &object.Integer{Value: leftVal + rightVal}
+		return object.NewInteger(leftVal+rightVal, dType)
 	case token.MINUS:
-		return &object.Integer{Value: leftVal - rightVal}
+		return object.NewInteger(leftVal-rightVal, dType)
 	case token.ASTERISK:
-		return &object.Integer{Value: leftVal * rightVal}
-	case token.SLASH:
-		return &object.Integer{Value: leftVal / rightVal}
-	case token.PERCENT:
-		return &object.Integer{Value: leftVal % rightVal}
+		return object.NewInteger(leftVal*rightVal, dType)
+	case token.SLASH, token.PERCENT:
+		if rightVal == 0 {
+			return newError("integer divide by zero")
+		}
+
+		if operator == token.SLASH {
+			return object.NewInteger(leftVal/rightVal, dType)
+		}
+
+		return object.NewInteger(leftVal%rightVal, dType)
+	case token.REF:
+		return object.NewInteger(leftVal&rightVal, dType)
+	case token.PIPE:
+		return object.NewInteger(leftVal|rightVal, dType)
+	case token.XOR:
+		return object.NewInteger(leftVal^rightVal, dType)
+	case token.ANDNOT:
+		return object.NewInteger(leftVal&^rightVal, dType)
+	case token.SHL, token.SHR:
+		return evalShiftExpression(operator, left.(*object.Integer), rightVal)
 	case token.EQ:
 		return rawBooleanToBooleanObject(leftVal == rightVal)
 	case token.NEQ:
@@ -493,38 +2469,227 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 		return rawBooleanToBooleanObject(leftVal < rightVal)
 	case token.GT:
 		return rawBooleanToBooleanObject(leftVal > rightVal)
+	case token.LTEQ:
+		return rawBooleanToBooleanObject(leftVal <= rightVal)
+	case token.GTEQ:
+		return rawBooleanToBooleanObject(leftVal >= rightVal)
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 
 	}
 }
 
+// evalShiftExpression shifts left by count bits. The result has the type of left.
+func evalShiftExpression(operator string, left *object.Integer, count int64) object.Object {
+	if count < 0 {
+		return newError("negative shift amount")
+	}
+
+	if operator == token.SHL {
+		return object.NewInteger(left.Value<<count, left.Type())
+	}
+
+	return object.NewInteger(left.Value>>count, left.Type())
+}
+
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Float).Value
+	rightVal := right.(*object.Float).Value
+	dType := namedOperandType(left, right)
+
+	switch operator {
+	case token.PLUS:
+		return object.NewFloat(leftVal+rightVal, dType)
+	case token.MINUS:
+		return object.NewFloat(leftVal-rightVal, dType)
+	case token.ASTERISK:
+		return object.NewFloat(leftVal*rightVal, dType)
+	case token.SLASH:
+		return object.NewFloat(leftVal/rightVal, dType)
+	case token.EQ:
+		return rawBooleanToBooleanObject(leftVal == rightVal)
+	case token.NEQ:
+		return rawBooleanToBooleanObject(leftVal != rightVal)
+	case token.LT:
+		return rawBooleanToBooleanObject(leftVal < rightVal)
+	case token.GT:
+		return rawBooleanToBooleanObject(leftVal > rightVal)
+	case token.LTEQ:
+		return rawBooleanToBooleanObject(leftVal <= rightVal)
+	case token.GTEQ:
+		return rawBooleanToBooleanObject(leftVal >= rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type().Name(), operator, right.Type().Name())
+	}
+}
+
 func evalPrefixExpression(operator string, right object.Object) object.Object {
 	switch operator {
 	case token.BANG:
 		return evalBangOperatorExpression(right)
 	case token.MINUS:
 		return evalMinusPrefixOperatorExpression(right)
+	case token.XOR:
+		return evalXorPrefixOperatorExpression(right)
 	case token.FOPER:
 		return evalFoperPrefixOperatorExpression(right)
 	case token.ASTERISK:
 		return evalAsteriskPrefixOperatorExpression(right)
-	case token.REF:
-		return evalRefPrefixOperatorExpression(right)
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
 }
 
-func evalRefPrefixOperatorExpression(right object.Object) object.Object {
-	return &object.ReferenceObject{
-		Value: &right,
+// evalAddressExpression returns a pointer to the variable, slice element or struct
+// field node denotes.
+// Other values, i.e. composite literals, are stored in a new cell.
+func evalAddressExpression(node ast.Expression, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	case *ast.Identifier:
+		if cell, ok := env.Ref(node.Value); ok {
+			return &object.ReferenceObject{Value: cell}
+		}
+	case *ast.IndexExpression:
+		obj := Eval(node.Left, env)
+		if isError(obj) {
+			return obj
+		}
+
+		if slice, ok := obj.(*object.SliceObject); ok {
+			index := Eval(node.Index, env)
+			if isError(index) {
+				return index
+			}
+
+			intIndex, ok := index.(*object.Integer)
+			if !ok {
+				return newError("expected integer type for index expression, got=%T", index)
+			}
+
+			if intIndex.Value < 0 || int64(len(slice.Values)) <= intIndex.Value {
+				return newError("out of bound error for slice '%s' at index %d", node.Left.String(), intIndex.Value)
+			}
+
+			return &object.ReferenceObject{Value: &slice.Values[intIndex.Value]}
+		}
+	case *ast.SelectorExpression:
+		obj := Eval(node.Left, env)
+		if isError(obj) {
+			return obj
+		}
+
+		if ref, ok := obj.(*object.ReferenceObject); ok {
+			if ref.Value == nil {
+				return newError("invalid memory address or nil pointer dereference: %s", node.String())
+			}
+
+			obj = *ref.Value
+		}
+
+		if structObj, ok := obj.(*object.StructObject); ok {
+			if cell, ok := structObj.Fields[node.Field.Value]; ok {
+				return &object.ReferenceObject{Value: cell}
+			}
+		}
+	case *ast.PrefixExpression:
+		// &*p is p.
+		if node.Operator == token.ASTERISK {
+			return Eval(node.Right, env)
+		}
+	}
+
+	val := Eval(node, env)
+	if isError(val) {
+		return val
+	}
+
+	return &object.ReferenceObject{Value: &val}
+}
+
+// evalNew returns a pointer to a new zero value of the type args holds.
+func evalNew(args []object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments for new. expected 1, got %d", len(args))
+	}
+
+	typeObj, ok := args[0].(*object.DataTypeObject)
+	if !ok {
+		return newError("%s is not a type", args[0].Inspect())
+	}
+
+	val := analyzer.NativeTypeToDefaultObj(typeObj.DataType)
+	return &object.ReferenceObject{Value: &val}
+}
+
+// evalMake returns a new slice, map or channel of the type args holds. Slices hold
+// zero values up to their length.
+func evalMake(args []object.Object) object.Object {
+	if len(args) == 0 {
+		return newError("not enough arguments for make")
+	}
+
+	typeObj, ok := args[0].(*object.DataTypeObject)
+	if !ok {
+		return newError("%s is not a type", args[0].Inspect())
+	}
+
+	sizes := make([]int, len(args)-1)
+	for i, arg := range args[1:] {
+		size, ok := object.Unwrap(arg).(*object.Integer)
+		if !ok {
+			return newError("expected integer size argument to make, got=%s", arg.Type().Name())
+		}
+
+		if size.Value < 0 {
+			return newError("negative size argument in make: %d", size.Value)
+		}
+
+		sizes[i] = int(size.Value)
+	}
+
+	switch dType := ast.Underlying(typeObj.DataType).(type) {
+	case *ast.SliceDataType:
+		if len(sizes) == 0 {
+			return newError("missing len argument to make(%s)", typeObj.DataType.Name())
+		}
+
+		capacity := sizes[0]
+		if len(sizes) == 2 {
+			if sizes[1] < sizes[0] {
+				return newError("len larger than cap in make(%s)", typeObj.DataType.Name())
+			}
+
+			capacity = sizes[1]
+		}
+
+		values := make([]object.Object, sizes[0], capacity)
+		for i := range values {
+			values[i] = analyzer.NativeTypeToDefaultObj(dType.Type)
+		}
+
+		return &object.SliceObject{ValueType: dType.Type, Values: values}
+	case *ast.MapDataType:
+		return &object.MapObject{Pairs: make(map[object.HashKey]object.MapPair), KeyType: dType.KeyType, ValueType: dType.ValueType}
+	case *ast.ChanDataType:
+		// make(chan T) creates an unbuffered channel
+		size := 0
+		if len(sizes) != 0 {
+			size = sizes[0]
+		}
+
+		return &object.ChanObject{Chan: make(chan object.Object, size), ChanType: dType.ValueType}
+	default:
+		return newError("cannot make %s; type must be slice, map, or channel", typeObj.DataType.Name())
 	}
 }
 
 func evalAsteriskPrefixOperatorExpression(right object.Object) object.Object {
 	switch right := right.(type) {
 	case *object.ReferenceObject:
+		if right.Value == nil {
+			return newError("invalid memory address or nil pointer dereference")
+		}
+
 		return *right.Value
 	default:
 		return newError("expected reference: %s", right.Inspect())
@@ -532,7 +2697,7 @@ func evalAsteriskPrefixOperatorExpression(right object.Object) object.Object {
 }
 
 func evalFoperPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != parser.STRING {
+	if !isString(right) {
 		return newError("unknown operator: -f %s", right.Type())
 	}
 
@@ -546,12 +2711,23 @@ func evalFoperPrefixOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != parser.INT {
+	switch right := object.Unwrap(right).(type) {
+	case *object.Integer:
+		return object.NewInteger(-right.Value, right.Type())
+	case *object.Float:
+		return object.NewFloat(-right.Value, right.Type())
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
+}
+
+func evalXorPrefixOperatorExpression(right object.Object) object.Object {
+	integer, ok := object.Unwrap(right).(*object.Integer)
+	if !ok {
+		return newError("unknown operator: ^%s", right.Type())
+	}
 
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+	return object.NewInteger(^integer.Value, integer.Type())
 }
 
 func evalBangOperatorExpression(right object.Object) object.Object {
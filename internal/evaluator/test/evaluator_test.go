@@ -1,7 +1,11 @@
 package test
 
 import (
+	"kstmc.com/gosha/internal/lexer"
 	"kstmc.com/gosha/internal/object"
+	"kstmc.com/gosha/internal/parser"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -253,3 +257,1611 @@ func TestFunctions(t *testing.T) {
 		testIntegerObject(t, testEval(tt.input), tt.expected)
 	}
 }
+
+func TestSliceLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"[]int{1, 2, 3}[1]", 2},
+		{"len([]string{\"a\", \"b\"})", 2},
+		{"var xs = []int{\n1,\n2,\n}; xs[1]", 2},
+		{"var xs = [][]int{{1}, {2, 3}}; xs[1][1]", 3},
+		{"len([]int{})", 0},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestSliceLiteralTypeMismatch(t *testing.T) {
+	evaluated := testEval(`[]int{1, "a"}`)
+
+	if _, ok := evaluated.(*object.Error); !ok {
+		t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+}
+
+func TestMaps(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`map[string]int{"a": 1, "b": 2}["b"]`, 2},
+		{`map[string]int{"a": 1}["c"]`, 0},
+		{"var m = map[int]int{\n1: 10,\n2: 20,\n}; m[2]", 20},
+		{`var m = map[string]int{}; m["a"] = 3; m["a"]`, 3},
+		{`var m = map[string]int{"a": 1, "b": 2}; delete(m, "a"); len(m)`, 1},
+		{`var m = map[string][]int{"a": {1, 2}}; m["a"][1]`, 2},
+		{`var m = make(map[string]int); m["x"] = 4; m["x"]`, 4},
+		{`var m = map[string]int{"a": 7}; v, ok := m["a"]; if ok { return v }; return 0`, 7},
+		{`var m = map[string]int{"a": 7}; v, ok := m["b"]; if ok { return 1 }; return v`, 0},
+		{"type P struct {\nX int\nY string\n}\nm := map[P]int{P{1, \"a\"}: 1}\nm[P{2, \"a\"}] = 2\nm[P{1, \"a\"}] * 10 + m[P{2, \"a\"}]", 12},
+		{"a := 1\nb := 1\nm := map[*int]int{&a: 1}\nm[&b] = 2\nm[&a] * 10 + len(m)", 12},
+		{`m := map[any]int{1: 1, "1": 2}; var k any = "1"; m[k] * 10 + m[1]`, 21},
+		{`m := map[any]int{1: 1, "1": 2}; delete(m, any(1)); len(m)`, 1},
+		{"type Shape interface {\nN() int\n}\ntype Sq int\nfunc (s Sq) N() int {\nreturn int(s)\n}\nm := map[Shape]int{Sq(2): 5}\nvar s Shape = Sq(2)\nm[s] + m[Sq(3)]", 5},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMapErrors(t *testing.T) {
+	tests := []string{
+		`map[string]int{"a": "b"}`,
+		`map[string]int{1: 1}`,
+		`var m = map[string]int{}; m[1]`,
+		`var m = map[string]int{}; m["a"] = true`,
+		`var m map[string]int; m["a"] = 1`,
+		`map[[]int]int{}`,
+		`var m map[func()]int`,
+		"type K struct {\nA []int\n}\nm := map[K]int{}",
+		`m := map[any]int{}; m[[]int{1}] = 1`,
+		"type K struct {\nV any\n}\nm := map[K]int{}\nm[K{[]int{1}}]",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestStructs(t *testing.T) {
+	hostType := "type Host struct {\nName string\nPort int\n}\n"
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{hostType + `h := Host{Name: "a", Port: 22}; h.Port`, 22},
+		{hostType + `h := Host{"a", 22}; h.Port`, 22},
+		{hostType + `var h Host; h.Port`, 0},
+		{hostType + `h := Host{}; h.Port = 8; h.Port`, 8},
+		{hostType + `h := Host{Port: 1}; g := h; g.Port = 2; h.Port`, 1},
+		{hostType + `hosts := []Host{{Port: 1}, {Port: 2}}; hosts[1].Port = 5; hosts[1].Port`, 5},
+		{hostType + "func (h Host) Next() int {\nreturn h.Port + 1\n}\nh := Host{Port: 3}; h.Next()", 4},
+		{hostType + "func (h Host) Set(p int) {\nh.Port = p\n}\nh := Host{Port: 3}; h.Set(4); h.Port", 3},
+		{hostType + "func (h *Host) Set(p int) {\nh.Port = p\n}\nh := Host{Port: 3}; h.Set(4); h.Port", 4},
+		{hostType + "func (h *Host) Set(p int) {\nh.Port = p\n}\nh := Host{}; p := &h; p.Set(6); h.Port", 6},
+		{hostType + `m := map[string]Host{"a": {Port: 9}}; m["a"].Port`, 9},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	equalityTests := []struct {
+		input    string
+		expected bool
+	}{
+		{hostType + `a := Host{Name: "a", Port: 1}; b := Host{Name: "a", Port: 1}; a == b`, true},
+		{hostType + `a := Host{Port: 1}; c := a; a == c`, true},
+		{hostType + `a := Host{Port: 1}; b := Host{Port: 2}; a != b`, true},
+		{"type In struct {\nA string\n}\ntype Out struct {\nI In\n}\na := Out{I: In{A: \"x\"}}; b := Out{I: In{A: \"y\"}}; a == b", false},
+	}
+
+	for _, tt := range equalityTests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestStructErrors(t *testing.T) {
+	hostType := "type Host struct {\nName string\nPort int\n}\n"
+
+	tests := []string{
+		hostType + `Host{Nme: "a"}`,
+		hostType + `Host{Port: "a"}`,
+		hostType + `Host{"a"}`,
+		hostType + `h := Host{}; h.Addr`,
+		hostType + `h := Host{}; h.Port = "x"`,
+		`h := Unknown{}`,
+		"type S struct {\nX []int\n}\na := S{}; b := S{}; a == b",
+		"type S struct {\nF func()\n}\na := S{}; b := S{}; a != b",
+		"xs := []int{}; ys := []int{}; xs == ys",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestRecursiveFunctions(t *testing.T) {
+	input := `
+func sum(n int) int {
+	if n == 0 {
+		return 0
+	}
+
+	return n + sum(n - 1)
+}
+sum(4)
+`
+
+	testIntegerObject(t, testEval(input), 10)
+}
+
+func TestInterfaces(t *testing.T) {
+	shapeTypes := `
+type Shape interface {
+	Area() int
+}
+type Rect struct {
+	W, H int
+}
+func (r Rect) Area() int {
+	return r.W * r.H
+}
+type Square struct {
+	S int
+}
+func (s *Square) Area() int {
+	return s.S * s.S
+}
+`
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{shapeTypes + `var s Shape = Rect{2, 3}; s.Area()`, 6},
+		{shapeTypes + `var s Shape = &Square{4}; s.Area()`, 16},
+		{shapeTypes + "func area(s Shape) int {\nreturn s.Area()\n}\narea(Rect{1, 5})", 5},
+		{shapeTypes + `shapes := []Shape{Rect{2, 2}, &Square{3}}; shapes[1].Area()`, 9},
+		{shapeTypes + `var s Shape = Rect{1, 1}; s = &Square{2}; s.Area()`, 4},
+		{`var x any = 5; x.(int)`, 5},
+		{`var x any = "a"; n, ok := x.(int); if ok { n = 1 }; n`, 0},
+		{shapeTypes + `var x any = Rect{2, 4}; s := x.(Shape); s.Area()`, 8},
+		{shapeTypes + `var x any = Rect{2, 4}; r := x.(Rect); r.W`, 2},
+		{shapeTypes + "type Solid interface {\nShape\nVolume() int\n}\nfunc (r Rect) Volume() int {\nreturn r.Area() * 2\n}\nvar s Solid = Rect{1, 2}\nvar sh Shape = s\ns.Volume() + sh.Area()", 6},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestNilInterfaces(t *testing.T) {
+	shapeType := "type Shape interface {\nArea() int\n}\n"
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{shapeType + `var s Shape; s == nil`, true},
+		{`var x any = 1; x != nil`, true},
+		{`var x any = 1; x = nil; x == nil`, true},
+		{`var x any = 2; x == 2`, true},
+		{"type Port int\nvar a any = 1\nvar b any = Port(1)\na == b", false},
+		{"type Port int\nvar a any = Port(1)\na == Port(1)", true},
+		{`any(1) == any(1.0)`, false},
+		{`var p *int; var x any = p; x == nil`, false},
+		{`var x any; var y any = 1; x != y`, true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestNilCollections(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`var m map[string]int; m == nil`, true},
+		{`var xs []int; xs == nil`, true},
+		{`var c chan int; c == nil`, true},
+		{`m := map[string]int{}; m == nil`, false},
+		{`xs := []int{}; xs != nil`, true},
+		{`xs := make([]int, 0); xs == nil`, false},
+		{`c := make(chan int); c == nil`, false},
+		{`var xs []int; xs = append(xs, 1); xs == nil`, false},
+		{`xs := []int{1}; xs = nil; xs == nil`, true},
+		{`m := map[string]int{}; m = nil; m == nil`, true},
+		{"type S struct {\nxs []int\n}\nS{}.xs == nil", true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestTypeSwitches(t *testing.T) {
+	describe := `
+func describe(v any) int {
+	switch x := v.(type) {
+	case int:
+		return x + 1
+	case string, bool:
+		return 2
+	case nil:
+		return 3
+	default:
+		return 4
+	}
+	return 0
+}
+`
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{describe + `describe(9)`, 10},
+		{describe + `describe("a")`, 2},
+		{describe + `describe(true)`, 2},
+		{describe + `describe(nil)`, 3},
+		{describe + `describe([]int{1})`, 4},
+		{"var a any = 3\nn := 0\nswitch k := 2; v := a.(type) {\ncase int:\nn = v * k\n}\nn", 6},
+		// A type declared in a function is distinct from a package type of the same name.
+		{"type T struct {\nB string\n}\nfunc local() any {\ntype T struct {\nB string\n}\nreturn T{B: \"x\"}\n}\nn := 0\n_, ok := local().(T)\nif ok {\nn = 1\n}\nswitch local().(type) {\ncase T:\nn = n + 2\n}\nn", 0},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestInterfaceErrors(t *testing.T) {
+	shapeTypes := `
+type Shape interface {
+	Area() int
+}
+type Square struct {
+	S int
+}
+func (s *Square) Area() int {
+	return s.S * s.S
+}
+`
+
+	tests := []string{
+		shapeTypes + `var s Shape = 5`,
+		shapeTypes + `var s Shape = Square{1}`,
+		shapeTypes + `var s Shape; s.Area()`,
+		shapeTypes + `var s Shape = &Square{1}; s.Perimeter()`,
+		`var x any = "a"; x.(int)`,
+		`n := 1; n.(int)`,
+		`var x any = 1; x.(type)`,
+		"var x any = 1\nswitch x.(type) {\ncase int:\ncase int:\n}",
+		`var x any = []int{1}; x == x`,
+		shapeTypes + "type Solid interface {\nShape\nVolume() int\n}\nvar s Solid = &Square{1}",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestMultipleReturnValues(t *testing.T) {
+	divmod := "func divmod(a int, b int) (int, int) {\nreturn a / b, a % b\n}\n"
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{divmod + `q, r := divmod(7, 2); q`, 3},
+		{divmod + `q, r := divmod(7, 2); r`, 1},
+		{divmod + `_, r := divmod(9, 4); r`, 1},
+		{divmod + `q := 0; q, r := divmod(8, 2); q`, 4},
+		{`a, b := 1, 2; a, b = b, a; a`, 2},
+		{`a, b := 1, 2; a, b = b, a; b`, 1},
+		{`a := 1; _, a = 5, 6; a`, 6},
+		{divmod + "func add(x int, y int) int {\nreturn x + y\n}\nadd(divmod(7, 2))", 4},
+		{"func pair() (int, any) {\nreturn 1, 2\n}\n_, v := pair(); v.(int)", 2},
+		{"x := 1\nif true {\nx, y := 5, 6\nx = x + y\n}\nx", 1},
+		{"x := 1\nfunc f() int {\nx, y := 5, 6\nreturn x + y\n}\nf() + x", 12},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMultipleReturnValueErrors(t *testing.T) {
+	pair := "func pair() (int, string) {\nreturn 1, \"a\"\n}\n"
+
+	tests := []string{
+		pair + `x := pair()`,
+		pair + `a, b, c := pair()`,
+		pair + `var x int = pair()`,
+		pair + `a, b := pair(); a, b = b, a`,
+		pair + `a, b := pair(); a, b := pair()`,
+		"func f() (int, string) {\nreturn 1\n}",
+		"func f() int {\nreturn 1, 2\n}",
+		"func f() (int, string) {\nreturn \"a\", 1\n}",
+		`a, b := 1`,
+		`a := 1; a, c = 1, 2`,
+		`a := 0; a, a := 1, 2`,
+		"ch := make(chan int, 1)\nch <- 1\nselect {\ncase v, v := <-ch:\n}",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+
+	expected := "analyzer error Analyzer error. a repeated on left side of :="
+	if errObj, ok := testEval(`a, a := 1, 2`).(*object.Error); !ok || errObj.Message != expected {
+		t.Errorf("wrong error for repeated name. expected=%q, got=%+v", expected, errObj)
+	}
+
+	testIntegerObject(t, testEval(`_, _, c := 1, 2, 3; c`), 3)
+}
+
+func TestErrorValues(t *testing.T) {
+	find := `
+var ErrNotFound error = errors.New("not found")
+func find(k string) (int, error) {
+	if k == "a" {
+		return 1, nil
+	}
+	return 0, fmt.Errorf("find %s: %w", k, ErrNotFound)
+}
+`
+
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`var err error; err == nil`, true},
+		{`err := errors.New("boom"); err != nil`, true},
+		{`err := errors.New("boom"); err.Error()`, "boom"},
+		{`err := fmt.Errorf("code %d", 7); err.Error()`, "code 7"},
+		{find + `_, err := find("a"); err == nil`, true},
+		{find + `_, err := find("b"); err.Error()`, "find b: not found"},
+		{find + `_, err := find("b"); errors.Is(err, ErrNotFound)`, true},
+		{find + `_, err := find("b"); errors.Unwrap(err) == ErrNotFound`, true},
+		{find + `errors.Is(errors.New("not found"), ErrNotFound)`, false},
+		{"type MyErr struct {\nCode int\n}\nfunc (e MyErr) Error() string {\nreturn \"mine\"\n}\nvar err error = MyErr{1}; err.Error()", "mine"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`out, err := $(echo hi); err == nil`, true},
+		{`out, err := $(false); err != nil`, true},
+		{`out, err := $(false); err.Error()`, "exit status 1"},
+		{`out, err := $(ls /nonexistent-gosha-dir); e, ok := err.(interface { ExitCode() int }); e.ExitCode()`, int64(2)},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case int64:
+			testIntegerObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		}
+	}
+
+	if _, ok := testEval(`out := $(false)`).(*object.Error); !ok {
+		t.Errorf("failed command without error result did not abort")
+	}
+}
+
+func TestForRangeStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"sum := 0\nfor _, v := range []int{1, 2, 3} {\nsum = sum + v\n}\nsum", 6},
+		{"sum := 0\nfor i := range []int{5, 5, 5} {\nsum = sum + i\n}\nsum", 3},
+		{"sum := 0\nfor k, v := range map[int]int{1: 10, 2: 20} {\nsum = sum + k * v\n}\nsum", 50},
+		{"sum := 0\nfor _, r := range \"ab\" {\nsum = sum + int(r)\n}\nsum", 195},
+		{"last := 0\nfor i, _ := range \"héllo\" {\nlast = i\n}\nlast", 5},
+		{"n := 0\nfor range 4 {\nn = n + 1\n}\nn", 4},
+		{"sum := 0\nfor i := range 4 {\nsum = sum + i\n}\nsum", 6},
+		{"ch := make(chan int, 3)\nch <- 1\nch <- 2\nclose(ch)\nsum := 0\nfor v := range ch {\nsum = sum + v\n}\nsum", 3},
+		{"func first(xs []int) int {\nfor _, v := range xs {\nif v > 1 {\nreturn v\n}\n}\nreturn 0\n}\nfirst([]int{1, 7, 9})", 7},
+		{"var fs []func() int\nfor _, v := range []int{1, 2} {\nfs = append(fs, func() int {\nreturn v\n})\n}\nfs[0]()", 1},
+		{"xs := []int{1, 2}\nfor _, v := range xs {\nv = 5\n}\nxs[0]", 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestForRangeErrors(t *testing.T) {
+	tests := []string{
+		"for _, v := range true {\n}",
+		"ch := make(chan int, 1)\nfor a, b := range ch {\n}",
+		"for i, v := range 3 {\n}",
+		"for _, v := range []int{1} {\n}\nv",
+		"for _, v := range []int{1} {\nv = \"a\"\n}",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestForStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"sum := 0\nfor i := 0; i < 4; i = i + 1 {\nsum = sum + i\n}\nsum", 6},
+		{"sum := 0\ni := 0\nfor ; i < 3; {\nsum = sum + i\ni = i + 1\n}\nsum", 3},
+		{"i := 0\nfor i = 5; i < 7; i = i + 1 {\n}\ni", 7},
+		{"n := 0\nfor {\nn = n + 1\nif n == 3 {\nbreak\n}\n}\nn", 3},
+		{"sum := 0\nfor i := 0; i < 5; i = i + 1 {\nif i == 2 {\ncontinue\n}\nsum = sum + i\n}\nsum", 8},
+		{"n := 0\nfor i := 0; i < 5; i = i + 1 {\nif i == 1 {\ni = 3\n}\nn = n + 1\n}\nn", 3},
+		{"n := 0\nouter:\nfor i := 0; i < 3; i = i + 1 {\nfor j := 0; j < 3; j = j + 1 {\nif j == 1 {\ncontinue outer\n}\nn = n + 1\n}\n}\nn", 3},
+		{"n := 0\nouter:\nfor i := 0; i < 3; i = i + 1 {\nfor {\nif i == 1 {\nbreak outer\n}\nn = n + 1\nbreak\n}\n}\nn", 1},
+		{"n := 0\nfound:\nfor _, x := range []int{1, 2, 3} {\nfor y := range 3 {\nif x * y == 4 {\nn = x\nbreak found\n}\n}\n}\nn", 2},
+		{"func f() int {\nfor i := 0; ; i = i + 1 {\nif i > 3 {\nreturn i\n}\n}\nreturn 0\n}\nf()", 4},
+		{"var fs []func() int\nfor i := 0; i < 3; i = i + 1 {\nfs = append(fs, func() int {\nreturn i\n})\n}\nfs[1]()", 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestBranchErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"break", "break is not in a loop, switch, or select"},
+		{"continue", "continue is not in a loop"},
+		{"for {\nbreak outer\n}", "invalid break label outer"},
+		{"inner:\nfor {\n}\nfor {\ncontinue inner\n}", "invalid continue label inner"},
+		{"for {\nfunc() {\nbreak\n}()\n}", "break is not in a loop, switch, or select"},
+		{"switch {\ndefault:\ncontinue\n}", "continue is not in a loop"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+
+	evaluated := testEval("for i := 0; i; i = i + 1 {\n}")
+	if _, ok := evaluated.(*object.Error); !ok {
+		t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+}
+
+func TestSwitchStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"x := 2\nn := 0\nswitch x {\ncase 1:\nn = 10\ncase 2, 3:\nn = 20\ndefault:\nn = 30\n}\nn", 20},
+		{"x := 7\nn := 0\nswitch x {\ndefault:\nn = 30\ncase 1:\nn = 10\n}\nn", 30},
+		{"x := 7\nn := 0\nswitch x {\ncase 1:\nn = 10\n}\nn", 0},
+		{"x := 5\nn := 0\nswitch {\ncase x > 10:\nn = 1\ncase x > 3:\nn = 2\ncase x > 1:\nn = 3\n}\nn", 2},
+		{"n := 0\nswitch \"run\" {\ncase \"stop\":\nn = 1\ncase \"run\":\nn = 2\n}\nn", 2},
+		{"n := 0\nswitch 1 {\ncase 1:\nn = n + 1\nfallthrough\ncase 2:\nn = n + 10\nfallthrough\ndefault:\nn = n + 100\ncase 3:\nn = n + 1000\n}\nn", 111},
+		{"n := 0\nswitch 1 {\ncase 1:\nif n == 0 {\nbreak\n}\nn = 5\n}\nn", 0},
+		{"n := 0\nfor i := 0; i < 5; i = i + 1 {\nswitch i {\ncase 1:\ncontinue\ncase 3:\nbreak\n}\nn = n + i\n}\nn", 9},
+		{"n := 0\nloop:\nfor i := 0; i < 5; i = i + 1 {\nswitch {\ncase i == 2:\nbreak loop\n}\nn = n + 1\n}\nn", 2},
+		{"n := 0\nsw:\nswitch 1 {\ncase 1:\nfor {\nbreak sw\n}\nn = 1\n}\nn", 0},
+		{"func f(x int) int {\nswitch x {\ncase 1:\nreturn 10\n}\nreturn 20\n}\nf(1) + f(2)", 30},
+		{"var a any = 2\nn := 0\nswitch a {\ncase \"2\":\nn = 1\ncase 2:\nn = 2\n}\nn", 2},
+		{"type Port int\nvar a any = Port(2)\nn := 0\nswitch a {\ncase 2:\nn = 1\ncase Port(2):\nn = 2\n}\nn", 2},
+		{"func f() int {\nreturn 2\n}\nn := 0\nswitch x := f(); x {\ncase 2:\nn = x * 10\n}\nn", 20},
+		{"x := 1\nswitch x := 5; x {\ncase 5:\nx = 6\n}\nx", 1},
+		{"n := 0\nswitch x := 3; {\ncase x > 2:\nn = 1\n}\nn", 1},
+		{"n := 0\nswitch n = 4; n {\ncase 4:\nn = n + 1\n}\nn", 5},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestElseIfStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"x := 1\nn := 0\nif x == 1 {\nn = 1\n} else if x == 2 {\nn = 2\n} else {\nn = 3\n}\nn", 1},
+		{"x := 2\nn := 0\nif x == 1 {\nn = 1\n} else if x == 2 {\nn = 2\n} else {\nn = 3\n}\nn", 2},
+		{"x := 5\nn := 0\nif x == 1 {\nn = 1\n} else if x == 2 {\nn = 2\n} else {\nn = 3\n}\nn", 3},
+		{"x := 5\nn := 0\nif x == 1 {\nn = 1\n} else if x == 2 {\nn = 2\n}\nn", 0},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestSwitchErrors(t *testing.T) {
+	tests := []string{
+		"x := 1\nswitch x {\ncase \"a\":\n}",
+		"switch {\ncase 1:\n}",
+		"x := 1\nswitch x {\ncase 1, 2:\ncase 2:\n}",
+		"switch \"a\" {\ncase \"a\", \"a\":\n}",
+		"x := 1\nswitch x {\ndefault:\ndefault:\n}",
+		"switch 1 {\ncase 1:\ny := 1\n}\ny",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+
+	parserTests := []struct {
+		input    string
+		expected string
+	}{
+		{"switch 1 {\ncase 1:\nfallthrough\n}", "cannot fallthrough final case in switch"},
+		{"switch 1 {\ncase 1:\nfallthrough\nprint(1)\ncase 2:\n}", "fallthrough statement out of place"},
+		{"fallthrough", "fallthrough statement out of place"},
+		{"var a any = 1\nswitch a.(type) {\ncase int:\nfallthrough\ncase string:\n}", "cannot fallthrough in type switch"},
+		{"switch x := 5 {\n}", "expected x.(type) in switch statement"},
+		{"x := 1\nswitch x = 5 {\n}", "cannot use x = 5 as value in switch statement"},
+	}
+
+	for _, tt := range parserTests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestSelectStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"a := make(chan int, 1)\nb := make(chan int, 1)\nb <- 7\nn := 0\nselect {\ncase v := <-a:\nn = v\ncase v := <-b:\nn = v * 10\n}\nn", 70},
+		{"a := make(chan int, 1)\nn := 0\nselect {\ncase v := <-a:\nn = v\ndefault:\nn = -1\n}\nn", -1},
+		{"a := make(chan int, 1)\nselect {\ncase a <- 5:\n}\n<-a", 5},
+		{"a := make(chan int, 1)\na <- 3\nn := 0\nselect {\ncase n = <-a:\n}\nn", 3},
+		{"a := make(chan int, 1)\nclose(a)\nn := 0\nselect {\ncase v, ok := <-a:\nif !ok {\nn = v + 1\n}\n}\nn", 1},
+		{"a := make(chan int, 1)\na <- 4\nv, ok := <-a\nif ok {\nv = v * 2\n}\nv", 8},
+		{"a := make(chan int)\nn := 0\nselect {\ncase <-a:\nn = 1\ncase <-time.After(time.Millisecond):\nn = 2\n}\nn", 2},
+		{"a := make(chan int)\nfunc send() {\na <- 9\n}\ngo send()\nn := 0\nselect {\ncase v := <-a:\nn = v\ncase <-time.After(time.Second):\nn = -1\n}\nn", 9},
+		{"timeout := time.After(time.Millisecond)\nn := 0\nloop:\nfor {\nselect {\ncase <-timeout:\nbreak loop\ndefault:\nn = 1\n}\n}\nn", 1},
+		{"a := make(chan int, 1)\na <- 1\nn := 0\nselect {\ncase <-a:\nif n == 0 {\nbreak\n}\nn = 5\n}\nn", 0},
+		{"var c chan int\nn := 0\nselect {\ncase <-c:\nn = 1\ndefault:\nn = 2\n}\nn", 2},
+		{"a := make(chan int, 1)\na <- 3\nv, ok := 0, false\nselect {\ncase v, ok := <-a:\nif ok {\nv = v * 2\n}\n}\nv", 0},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestSelectErrors(t *testing.T) {
+	tests := []string{
+		"x := 1\nselect {\ncase v := <-x:\n}",
+		"a := make(chan int, 1)\nselect {\ncase a <- \"s\":\n}",
+		"a := make(chan int, 1)\nselect {\ncase v := <-a:\nv = \"s\"\n}",
+		"select {\ndefault:\ndefault:\n}",
+		"a := make(chan int, 1)\nselect {\ncase v := <-a:\ndefault:\n}\nv",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+
+	p := parser.New(lexer.New("select {\ncase x := 1:\n}"))
+	p.ParseProgram()
+
+	expected := "select case must be receive, send or assign recv, got x := 1"
+	if errors := p.Errors(); len(errors) == 0 || errors[0] != expected {
+		t.Errorf("wrong parser errors. expected=%q, got=%q", expected, errors)
+	}
+}
+
+func TestDeferStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"n := 0\nfunc f() {\ndefer func() {\nn = n * 10\n}()\ndefer func() {\nn = n + 2\n}()\nn = 1\n}\nf()\nn", 30},
+		{"n := 0\nfunc f() int {\ndefer func() {\nn = 7\n}()\nreturn n\n}\nf()", 0},
+		{"n := 0\nfunc f() int {\ndefer func() {\nn = 7\n}()\nreturn n\n}\nf()\nn", 7},
+		{"n := 0\nfunc add(x int) {\nn = n * 10 + x\n}\nfunc f() {\nfor i := 1; i < 4; i = i + 1 {\ndefer add(i)\n}\n}\nf()\nn", 321},
+		{"n := 0\nfunc add(x int) {\nn = x\n}\nfunc f() {\nx := 1\ndefer add(x)\nx = 2\n}\nf()\nn", 1},
+		{"n := 0\nfunc f() {\ndefer func() {\nn = 5\n}()\nxs := []int{}\nxs[1]\n}\nfunc g() {\ndefer func() {\nrecover()\n}()\nf()\n}\ng()\nn", 5},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestPanicRecover(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"func f() int {\ndefer func() {\nrecover()\n}()\npanic(\"boom\")\nreturn 1\n}\nf()", 0},
+		{"var got any\nfunc f() {\ndefer func() {\ngot = recover()\n}()\npanic(42)\n}\nf()\ngot.(int)", 42},
+		{"n := 0\nfunc f() {\ndefer func() {\nif recover() == nil {\nn = 1\n}\n}()\n}\nf()\nn", 1},
+		{"n := 0\nfunc f() {\ndefer func() {\n_, ok := recover().(error)\nif ok {\nn = 2\n}\n}()\nxs := []int{}\nxs[1]\n}\nf()\nn", 2},
+		{"n := 0\nfunc g() {\nif recover() != nil {\nn = 1\n}\n}\nfunc f() {\ndefer func() {\ng()\nrecover()\n}()\npanic(1)\n}\nf()\nn", 0},
+		{"func inner() {\npanic(\"x\")\n}\nfunc f() (int, error) {\ndefer func() {\nrecover()\n}()\ninner()\nreturn 1, nil\n}\na, _ := f()\na", 0},
+		{"n := 0\nfunc f() {\ndefer func() {\nn = n + 1\n}()\npanic(1)\n}\nfunc g() {\ndefer func() {\nrecover()\n}()\nf()\n}\ng()\nn", 1},
+		{"n := 0\nfunc f() {\ndefer func() {\nif recover() != nil {\nn = 3\n}\n}()\nch := make(chan int)\nclose(ch)\nclose(ch)\n}\nf()\nn", 3},
+		{"n := 0\nfunc f() {\ndefer func() {\nif recover() != nil {\nn = 4\n}\n}()\nch := make(chan int, 1)\nclose(ch)\nch <- 1\n}\nf()\nn", 4},
+		{"n := 0\nfunc f(x int) int {\ndefer func() {\nif recover() != nil {\nn = 5\n}\n}()\nreturn 10 / x\n}\nf(0)\nn", 5},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestPanicErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"panic(\"boom\")", "panic: boom"},
+		{"func f() {\npanic(errors.New(\"bad\"))\n}\nf()", "panic: bad"},
+		{"func f() {\ndefer func() {\npanic(\"second\")\n}()\npanic(\"first\")\n}\nf()", "panic: second"},
+		{"func f() {\ndefer func() {\nrecover()\npanic(\"again\")\n}()\npanic(\"first\")\n}\nf()", "panic: again"},
+		{"ch := make(chan int)\nclose(ch)\nclose(ch)", "close of closed channel"},
+		{"ch := make(chan int, 1)\nclose(ch)\nch <- 1", "send on closed channel"},
+		{"ch := make(chan int, 1)\nclose(ch)\nselect {\ncase ch <- 1:\n}", "send on closed channel"},
+		{"x := 0\n1 / x", "integer divide by zero"},
+		{"x := 0\n1 % x", "integer divide by zero"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+
+	p := parser.New(lexer.New("x := 1\ndefer x"))
+	p.ParseProgram()
+
+	expected := "expression in defer must be function call"
+	if errors := p.Errors(); len(errors) == 0 || errors[0] != expected {
+		t.Errorf("wrong parser errors. expected=%q, got=%q", expected, errors)
+	}
+}
+
+func TestClosures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"func counter() func() int {\nn := 0\nreturn func() int {\nn = n + 1\nreturn n\n}\n}\nc := counter()\nc()\nc()\nc()", 3},
+		{"func counter() func() int {\nn := 0\nreturn func() int {\nn = n + 1\nreturn n\n}\n}\na := counter()\nb := counter()\na()\na()\nb()", 1},
+		{"x := 1\ninc := func() {\nx = x + 1\n}\ninc()\ninc()\nx", 3},
+		{"func(x int) int {\nreturn x * 2\n}(3)", 6},
+		{"func(x int) func() int {\nreturn func() int {\nreturn x + 1\n}\n}(3)()", 4},
+		{"ch := make(chan int)\ngo func(v int) {\nch <- v\n}(4)\n<-ch", 4},
+		{"x := 1\nch := make(chan int, 1)\nfunc send(v int) {\nch <- v\n}\ngo send(x)\nx = 2\n<-ch", 1},
+		{"func apply(x int, f func(int) int) int {\nreturn f(x)\n}\napply(5, func(x int) int {\nreturn x * x\n})", 25},
+		{"func retry(n int, f func() error) int {\nfor i := 1; i < n + 1; i = i + 1 {\nif f() == nil {\nreturn i\n}\n}\nreturn -1\n}\ntries := 0\nretry(3, func() error {\ntries = tries + 1\nif tries < 2 {\nreturn errors.New(\"fail\")\n}\nreturn nil\n})", 2},
+		{"fs := []func() int{}\nfor i := 0; i < 3; i = i + 1 {\nfs = append(fs, func() int {\nreturn i\n})\n}\nsum := 0\nfor _, f := range fs {\nsum = sum * 10 + f()\n}\nsum", 12},
+		{"fs := []func() int{}\nfor _, v := range []int{4, 5} {\nfs = append(fs, func() int {\nreturn v\n})\n}\nfs[0]() + fs[1]()", 9},
+		{"var f func(int) int\nf = func(x int) int {\nreturn x + 1\n}\nf(1)", 2},
+		{"m := map[string]func() int{\"a\": func() int {\nreturn 7\n}}\nm[\"a\"]()", 7},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestNilFunctions(t *testing.T) {
+	testBooleanObject(t, testEval("var f func() int\nf == nil"), true)
+	testBooleanObject(t, testEval("f := func() {\n}\nf != nil"), true)
+	testBooleanObject(t, testEval("type S struct {\ncb func()\n}\nS{}.cb == nil"), true)
+
+	evaluated := testEval("var f func()\nf()")
+	if _, ok := evaluated.(*object.Error); !ok {
+		t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	p := parser.New(lexer.New("x := 1\ngo x"))
+	p.ParseProgram()
+
+	expected := "expression in go must be function call"
+	if errors := p.Errors(); len(errors) == 0 || errors[0] != expected {
+		t.Errorf("wrong parser errors. expected=%q, got=%q", expected, errors)
+	}
+}
+
+func TestGoroutineErrors(t *testing.T) {
+	// The main program is blocked when the goroutine fails.
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ch := make(chan int)\ngo func() {\npanic(\"boom\")\n}()\n<-ch", "panic: boom"},
+		{"ch := make(chan int)\nfunc store() {\nvar m map[string]int\nm[\"a\"] = 1\n}\ngo store()\n<-ch", "assignment to entry in nil map 'm'"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q", tt.input)
+			continue
+		}
+
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+
+	recovered := "ch := make(chan int)\ngo func() {\ndefer func() {\nrecover()\nch <- 2\n}()\npanic(\"boom\")\n}()\n<-ch"
+	testIntegerObject(t, testEval(recovered), 2)
+}
+
+func TestVariadicFunctions(t *testing.T) {
+	sum := "func sum(xs ...int) int {\ntotal := 0\nfor _, x := range xs {\ntotal = total + x\n}\nreturn total\n}\n"
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{sum + "sum()", 0},
+		{sum + "sum(1, 2, 3)", 6},
+		{sum + "xs := []int{4, 5}\nsum(xs...)", 9},
+		{sum + "func two() (int, int) {\nreturn 7, 8\n}\nsum(two())", 15},
+		{"func count(prefix string, args ...any) int {\nreturn len(args)\n}\ncount(\"a\", 1, \"b\", true)", 3},
+		{"func count(prefix string, args ...any) int {\nreturn len(args)\n}\ncount(\"a\")", 0},
+		{"xs := []int{1}\nys := []int{2, 3}\nlen(append(xs, ys...))", 3},
+		{sum + "type S struct {\nn int\n}\nfunc (s S) add(vs ...int) int {\nreturn s.n + sum(vs...)\n}\nS{n: 10}.add(1, 2)", 13},
+		{sum + "var f func(...int) int = sum\nf(4, 5)", 9},
+		{"f := func(xs ...int) int {\nxs[0] = 5\nreturn xs[0]\n}\nf(1)", 5},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestVariadicErrors(t *testing.T) {
+	tests := []string{
+		"func sum(xs ...int) int {\nreturn 0\n}\nsum(1, \"a\")",
+		"func log(prefix string, args ...any) {\n}\nlog()",
+		"func f(a int) {\n}\nxs := []int{1}\nf(xs...)",
+		"func sum(xs ...int) int {\nreturn 0\n}\nxs := []int{1}\nsum(1, xs...)",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+
+	parserTests := []string{
+		"func f(a ...int, b int) {\n}",
+		"func f(a, b ...int) {\n}",
+		"var f func(...int, int)",
+	}
+
+	for _, input := range parserTests {
+		p := parser.New(lexer.New(input))
+		p.ParseProgram()
+
+		expected := "can only use ... with final parameter in list"
+		if errors := p.Errors(); len(errors) == 0 || errors[0] != expected {
+			t.Errorf("wrong parser errors for %q. expected=%q, got=%q", input, expected, errors)
+		}
+	}
+}
+
+func TestFloatExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"1.5", 1.5},
+		{"1e3", 1000},
+		{"2.5e-1", 0.25},
+		{"-0.5", -0.5},
+		{"1.5 + 2.25", 3.75},
+		{"1.5 * 2", 3},
+		{"3 / 2.0", 1.5},
+		{"1 - 0.25", 0.75},
+		{"var f float64\nf", 0},
+		{"var f float64 = 2\nf / 4", 0.5},
+		{"func half(x float64) float64 {\nreturn x / 2\n}\nhalf(3)", 1.5},
+		{"xs := []float64{1, 2.5}\nxs[0] + xs[1]", 3.5},
+		{"used := 30\ntotal := 120\nfloat64(used) / float64(total) * 100", 25},
+	}
+
+	for _, tt := range tests {
+		testFloatObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testBooleanObject(t, testEval("1.5 > 1"), true)
+	testBooleanObject(t, testEval("1.5 < 1.25"), false)
+	testBooleanObject(t, testEval("0.5 == 0.5"), true)
+	testIntegerObject(t, testEval("f := 7.9\nint(f)"), 7)
+	testIntegerObject(t, testEval("f := -7.9\nint(f)"), -7)
+	testStringObject(t, testEval("fmt.Sprintf(\"%.2f\", 2.0 / 3)"), "0.67")
+}
+
+func TestFloatErrors(t *testing.T) {
+	tests := []string{
+		"x := 1\nx + 1.5",
+		"1.5 % 2.0",
+		"var s string = 1.5",
+		"int(\"1\")",
+		"float64(1, 2)",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestSliceExpressions(t *testing.T) {
+	stringTests := []struct {
+		input    string
+		expected string
+	}{
+		{"\"hello\"[1:3]", "el"},
+		{"\"hello\"[:2]", "he"},
+		{"\"hello\"[3:]", "lo"},
+		{"\"hello\"[:]", "hello"},
+		{"line := \"  PID TTY\"\nline[2:5]", "PID"},
+		{"s := \"héllo\"\nstring([]rune(s)[1:3])", "él"},
+		{"string([]byte(\"abc\"))", "abc"},
+		{"s := \"abc\"\nstring(s[1])", "b"},
+		{"r := []rune(\"héllo\")[1]\nstring(r)", "é"},
+	}
+
+	for _, tt := range stringTests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+
+	intTests := []struct {
+		input    string
+		expected int64
+	}{
+		{"\"abc\"[1]", 98},
+		{"len(\"héllo\")", 6},
+		{"len([]rune(\"héllo\"))", 5},
+		{"len([]byte(\"héllo\"))", 6},
+		{"xs := []int{1, 2, 3, 4}\nys := xs[1:3]\nlen(ys)", 2},
+		{"xs := []int{1, 2, 3, 4}\nys := xs[1:3]\nys[0] = 9\nxs[1]", 9},
+		{"s := \"a9\"\nint(s[1] - 48)", 9},
+		{"var b byte = 255\nb = b + 1\nint(b)", 0},
+		{"sum := 0\nfor _, r := range \"aé\" {\nsum = sum + int(r)\n}\nsum", 330},
+	}
+
+	for _, tt := range intTests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testBooleanObject(t, testEval("var a any = \"abc\"[0]\n_, ok := a.(byte)\nok"), true)
+	testBooleanObject(t, testEval("for _, r := range \"a\" {\nvar a any = r\n_, ok := a.(rune)\nreturn ok\n}"), true)
+}
+
+func TestSliceExpressionErrors(t *testing.T) {
+	tests := []string{
+		"s := \"abc\"\ns[0] = s[1]",
+		"x := 1\ns := \"abc\"\ns[0] + x",
+		"1[0:1]",
+		"\"abc\"[1:5]",
+		"xs := []int{1}\nxs[1:0]",
+		"\"abc\"[3]",
+		"string(1.5)",
+		"[]byte(1)",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestOperators(t *testing.T) {
+	intTests := []struct {
+		input    string
+		expected int64
+	}{
+		{"6 & 3", 2},
+		{"6 | 1", 7},
+		{"6 ^ 3", 5},
+		{"6 &^ 2", 4},
+		{"6 << 2", 24},
+		{"6 >> 1", 3},
+		{"^6", -7},
+		{"1 + 2 * 3 % 4", 3},
+		{"1 | 2 << 2", 9},
+		{"7 - 4 & 1", 7},
+		{"var b byte = 4\nint(b << 6)", 0},
+		{"var b byte = 4\nint(^b)", 251},
+	}
+
+	for _, tt := range intTests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	boolTests := []struct {
+		input    string
+		expected bool
+	}{
+		{"3 <= 3", true},
+		{"3 <= 2", false},
+		{"4 >= 5", false},
+		{"5 >= 5", true},
+		{"1.5 <= 2.0", true},
+		{"\"a\" < \"b\"", true},
+		{"\"b\" >= \"c\"", false},
+		{"1 + 1 <= 2 && 3 >= 1 + 1", true},
+	}
+
+	for _, tt := range boolTests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestCompoundAssignments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"sum := 0\nfor i := 0; i <= 3; i++ {\nsum += i\n}\nsum", 6},
+		{"i := 10\nfor i >= 0 {\ni -= 3\n}\ni", -2},
+		{"x := 3\nx *= 4\nx /= 2\nx %= 4\nx", 2},
+		{"x := 1\nx <<= 3\nx |= 1\nx &= 3\nx ^= 2\nx", 3},
+		{"x := 5\nx--\nx--\nx", 3},
+		{"xs := []int{1, 2}\nxs[0] += 10\nxs[1]++\nxs[0] + xs[1]", 14},
+		{"m := map[string]int{}\nm[\"a\"]++\nm[\"a\"] += 2\nm[\"a\"]", 3},
+		{"type C struct {\nn int\n}\nc := C{}\nc.n += 5\nc.n--\nc.n", 4},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testStringObject(t, testEval("s := \"a\"\ns += \"b\"\ns"), "ab")
+	testFloatObject(t, testEval("f := 1.5\nf *= 2\nf"), 3)
+}
+
+func TestOperatorErrors(t *testing.T) {
+	tests := []string{
+		"\"a\" & \"b\"",
+		"1.5 << 1",
+		"s := \"a\"\ns++",
+		"x := 1\nx <= \"a\"",
+		"x := -1\n1 << x",
+		"x := 1\nx += \"a\"",
+		"1 && true",
+		"func f() int {\nreturn 1\n}\nf() += 1",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestAssignTargets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"xs := []int{1, 2, 3}\nxs[1] = 5\nxs[1]", 5},
+		{"xs := []int{1, 2}\nys := xs\nys[0] = 7\nxs[0]", 7},
+		{"xs := []int{1, 2}\nxs[0], xs[1] = xs[1], xs[0]\nxs[0]*10 + xs[1]", 21},
+		{"m := map[string]int{}\nm[\"a\"] = 3\nm[\"a\"]", 3},
+		{"type Host struct {\nPort int\n}\nh := Host{}\nh.Port = 22\nh.Port", 22},
+		{"type Host struct {\nPort int\n}\nh := Host{}\np := &h\np.Port = 22\nh.Port", 22},
+		{"type Host struct {\nPort int\n}\nh := Host{Port: 1}\np := &h\n*p = Host{Port: 8}\nh.Port", 8},
+		{"x := 1\np := &x\n*p = 5\n*p", 5},
+		{"i := 0\nxs := []int{0, 0}\ni, xs[i] = 1, 9\nxs[0]", 9},
+		{"a, b := 1, 2\na, b = b, a\na*10 + b", 21},
+		{"var f float64\nxs := []float64{0}\nxs[0], f = 1, 2\nint(xs[0] + f)", 3},
+		{"type Host struct {\nPort int\n}\nm := map[string]*Host{\"a\": &Host{}}\nm[\"a\"].Port = 6\nm[\"a\"].Port", 6},
+		{"type Host struct {\nPort int\n}\ntype W struct {\nH Host\n}\nws := []W{{}}\nws[0].H.Port = 9\nws[0].H.Port", 9},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestAssignTargetErrors(t *testing.T) {
+	tests := []string{
+		"func f() int {\nreturn 1\n}\nf() = 1",
+		"1 = 2",
+		"xs := []int{1}\nxs[0] = \"a\"",
+		"type Host struct {\nPort int\n}\nh := Host{}\nh.Name = \"a\"",
+		"x := 1\np := &x\n*p = \"a\"",
+		"s := \"ab\"\ns[0] = s[1]",
+		"xs := []int{1}\nxs[0], xs[1] = 1",
+		"xs := []int{1}\nxs[3] = 1",
+		"type Host struct {\nPort int\n}\nm := map[string]Host{\"a\": {Port: 1}}\nm[\"a\"].Port = 5",
+		"type Host struct {\nPort int\n}\nm := map[string]Host{\"a\": {Port: 1}}\nm[\"a\"].Port++",
+		"type Host struct {\nPort int\n}\nfunc mk() Host {\nreturn Host{}\n}\nmk().Port = 5",
+		"type Host struct {\nPort int\n}\nfunc (h *Host) Set(p int) {\nh.Port = p\n}\nm := map[string]Host{\"a\": {Port: 1}}\nm[\"a\"].Set(3)",
+		"type Host struct {\nPort int\n}\nfunc (h *Host) Set(p int) {\nh.Port = p\n}\nfunc mk() Host {\nreturn Host{}\n}\nmk().Set(1)",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestPointers(t *testing.T) {
+	nodeType := "type Node struct {\nnext *Node\nval int\n}\n"
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"x := 1\np := &x\n*p = 5\nx", 5},
+		{"x := 1\np := &x\nx = 3\n*p", 3},
+		{"x := 1\ny := x\np := &y\n*p = 2\nx", 1},
+		{"xs := []int{1, 2, 3}\np := &xs[1]\n*p = 20\nxs[1]", 20},
+		{"p := new(int)\n*p += 4\n*p", 4},
+		{nodeType + "head := &Node{val: 1}\nhead.next = new(Node)\nhead.next.val = 2\nhead.val + head.next.val", 3},
+		{nodeType + "a := Node{val: 1}\nb := a\nb.val = 9\na.val", 1},
+		{nodeType + "a := Node{val: 1}\np := &a\n*p = Node{val: 4}\na.val", 4},
+		{"type C struct {\nn int\n}\nfunc (c *C) Reset() {\n*c = C{n: 100}\n}\nc := C{}\nc.Reset()\nc.n", 100},
+		{nodeType + "a := Node{val: 1}\nxs := []Node{}\nxs = append(xs, a)\na.val = 2\nxs[0].val", 1},
+		{nodeType + "a := Node{val: 1}\nch := make(chan Node, 1)\nch <- a\na.val = 7\nb := <-ch\nb.val", 1},
+		{nodeType + "a := Node{val: 1}\nch := make(chan Node, 1)\nselect {\ncase ch <- a:\n}\na.val = 7\nb := <-ch\nb.val", 1},
+		{nodeType + "n := Node{}\np := &n.val\n*p = 3\nn.val", 3},
+		{nodeType + "n := Node{}\np := &n.val\nn = Node{val: 8}\n*p += 1\nn.val", 9},
+		{nodeType + "head := &Node{}\np := &head.val\n*p = 6\nhead.val", 6},
+		{nodeType + "xs := []Node{{}}\np := &xs[0].val\n*p = 2\nxs[0].val", 2},
+		{nodeType + "n := Node{}\np := &n.val\nm := n\n*p = 4\nm.val", 0},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	boolTests := []struct {
+		input    string
+		expected bool
+	}{
+		{nodeType + "var head *Node\nhead == nil", true},
+		{nodeType + "n := Node{}\nn.next == nil", true},
+		{"x := 1\np := &x\nq := &x\np == q", true},
+		{"x := 1\ny := 1\n&x == &y", false},
+		{"func f() *int {\nreturn nil\n}\nf() == nil", true},
+	}
+
+	for _, tt := range boolTests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestPointerErrors(t *testing.T) {
+	tests := []string{
+		"var p *int\n*p",
+		"var p *int\n*p = 1",
+		"type Node struct {\nval int\n}\nvar p *Node\np.val",
+		"type Node struct {\nval int\n}\nvar p *Node\np.val = 1",
+		"type Node struct {\nval int\n}\nvar p *Node\nq := &p.val",
+		"type Node struct {\nval int\n}\nm := map[string]Node{}\np := &m[\"a\"].val",
+		"m := map[string]int{}\np := &m[\"a\"]",
+		"p := &1",
+		"p := new(5)",
+		"x := 1\np := &x\n*p = \"a\"",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestConstants(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"const Retries = 3\nRetries", 3},
+		{"const (\nA = iota\nB\nC\n)\nA*100 + B*10 + C", 12},
+		{"const (\nA = iota; B; C\n)\nC", 2},
+		{"const (\nKB = 1 << (10 * (iota + 1))\nMB\n)\nMB", 1048576},
+		{"const (\nX = 5\nY\n)\nY", 5},
+		{"const N = 2\nconst M = N * 3 + 1\nM", 7},
+		{"var b byte = 250\nconst N = 10\nint(b + N)", 4},
+		{"const D byte = 10\nconst E = D * 25\nint(E)", 250},
+		{"const C = 1 << 62\nC >> 60", 4},
+		{"const C = 1 << 70\nC >> 68", 4},
+		{"1 << 70 >> 68", 4},
+		{"const Z = 10.0\nvar i int = Z\ni", 10},
+		{"var i int = 2.0\ni", 2},
+		{"x := 3\nx + 2.0", 5},
+		{"func f(n int) int {\nreturn n * 2\n}\nf(2.0)", 4},
+		{"x := -9223372036854775807 - 1\nx / 1000000000000000000", -9},
+		{"func f() int {\nconst (\nA = iota * 10\nB\n)\nreturn B\n}\nf()", 10},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testFloatObject(t, testEval("const N = 3\nvar f float64 = N\nf * N"), 9)
+	testFloatObject(t, testEval("const Half = 3 / 2.0\nHalf"), 1.5)
+	testFloatObject(t, testEval("const Rate float64 = 2\nRate"), 2)
+	testStringObject(t, testEval("const Env = \"prod\"\nconst Name = Env + \"-db\"\nName"), "prod-db")
+	testBooleanObject(t, testEval("const N = 3\nconst Big = N > 2\nBig"), true)
+	testBooleanObject(t, testEval("1e30 / 1e29 == 10"), true)
+	testBooleanObject(t, testEval("const Third = 1.0 / 3\nThird * 3 == 1"), true)
+	testFloatObject(t, testEval("const C = 1 << 70\nvar f float64 = C\nf / (1 << 60)"), 1024)
+}
+
+func TestConstantErrors(t *testing.T) {
+	tests := []string{
+		"const N = 1\nN = 2",
+		"const N = 1\nN++",
+		"const N = 1\np := &N",
+		"func f() {\nconst K = 2\nK += 1\n}",
+		"x := 1\nconst Y = x",
+		"const Z = 1 / 0",
+		"iota",
+		"const S int = \"a\"",
+		"const D byte = 10\nconst E = D * 30",
+		"var b byte = 300",
+		"const C = 1 << 70\nvar x int = C",
+		"x := 1 << 70",
+		"var i int = 2.5",
+		"x := 3\nx + 0.5",
+		"x := 9223372036854775807 + 1",
+		"var b byte\nc := b + 256",
+		"x := byte(256)",
+		"const b byte = 1\nx := -b",
+		"func f(r rune) {\n}\nf(2147483648)",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestNamedTypes(t *testing.T) {
+	portType := "type Port int\nfunc (p Port) Next() Port {\nreturn p + 1\n}\n"
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{portType + "var p Port = 22\nint(p.Next())", 23},
+		{portType + "int(Port(8080).Next())", 8081},
+		{portType + "x := 7\nint(Port(x) * 2)", 14},
+		{portType + "var p Port\nint(p.Next())", 1},
+		{portType + "ports := []Port{80}\nports = append(ports, 443)\nint(ports[1].Next())", 444},
+		{portType + "m := map[Port]int{22: 1}\nvar p Port = 22\nm[p] + m[22]", 2},
+		{"type Count = int\nvar c Count = 3\nvar i int = c\ni + c", 6},
+		{"type Handler func(int) int\nvar h Handler = func(x int) int {\nreturn x * 2\n}\nh(4)", 8},
+		{"type Handler func(int) int\nfunc apply(h Handler) int {\nreturn h(5)\n}\napply(func(x int) int {\nreturn x + 1\n})", 6},
+		{"type IDs []string\nfunc (ids IDs) Len() int {\nreturn len(ids)\n}\nids := IDs{\"a\", \"b\"}\nids = append(ids, \"c\")\nids.Len() + ids[1:].Len()", 5},
+		{"type M map[string]int\nm := M{\"a\": 1, \"b\": 2}\nm[\"c\"] = 3\nlen(m) + m[\"b\"]", 5},
+		{"type IDs []string\nall := []IDs{{\"a\"}, IDs{\"b\", \"c\"}}\nlen(all[0]) + len(all[1])", 3},
+		{"type M map[string]int\nvar x any = M{}\n_, ok := x.(M)\n_, bad := x.(map[string]int)\nif ok && !bad {\n1\n} else {\n0\n}", 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testStringObject(t, testEval("type Name string\nfunc (n Name) Greet() string {\nreturn \"hi \" + string(n)\n}\nvar n Name = \"bob\"\n(n + \"!\").Greet()"), "hi bob!")
+	testFloatObject(t, testEval("type Ratio float64\nvar r Ratio = 1.5\nfloat64(r * 2)"), 3)
+	testBooleanObject(t, testEval("type Port int\nvar p Port = 3\np == 3"), true)
+}
+
+func TestNamedTypeErrors(t *testing.T) {
+	tests := []string{
+		"type Port int\nx := 1\nvar p Port = x",
+		"type Port int\nvar p Port = 1\nvar x int = p",
+		"type Port int\ntype ID int\nvar p Port = 1\nvar d ID = 1\np + d",
+		"type Port int\nvar p Port = 1\nx := 1\np == x",
+		"type Name string\nvar n Name = \"a\"\ns := \"b\"\nn + s",
+		"type Port int\nfunc f(p Port) {\n}\nx := 1\nf(x)",
+		"type Port int\nPort(\"a\")",
+		"type IDs []string\nIDs{1}",
+		"type M map[string]int\nM{\"a\"}",
+		"type M map[string]int\nM{1: 1}",
+		"type P struct {\nA int\n}\nP{\"A\": 1}",
+		"type IDs []string\nvar ids IDs = IDs{}\nvar s []int = ids",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestGenerics(t *testing.T) {
+	mapFn := "func Map[T, U any](xs []T, f func(T) U) []U {\nresult := []U{}\nfor _, x := range xs {\nresult = append(result, f(x))\n}\nreturn result\n}\n"
+	uniqFn := "func Uniq[T comparable](xs []T) []T {\nseen := map[T]bool{}\nresult := []T{}\nfor _, x := range xs {\nif !seen[x] {\nseen[x] = true\nresult = append(result, x)\n}\n}\nreturn result\n}\n"
+	sumFn := "type Number interface {\n~int | ~float64\n}\nfunc Sum[T Number](xs []T) T {\nvar total T\nfor _, x := range xs {\ntotal += x\n}\nreturn total\n}\n"
+	stackType := "type Stack[T any] struct {\nitems []T\n}\nfunc (s *Stack[T]) Push(x T) {\ns.items = append(s.items, x)\n}\nfunc (s *Stack[T]) Pop() T {\nx := s.items[0]\ns.items = s.items[1:]\nreturn x\n}\n"
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{mapFn + "xs := Map([]string{\"a\", \"bc\"}, func(s string) int {\nreturn len(s)\n})\nxs[1]", 2},
+		{mapFn + "xs := Map[int, int]([]int{1, 2}, func(x int) int {\nreturn x * 10\n})\nxs[1]", 20},
+		{uniqFn + "xs := Uniq([]int{1, 1, 2, 1, 3})\nxs[2]", 3},
+		{sumFn + "Sum([]int{1, 2, 3})", 6},
+		{sumFn + "type Port int\nint(Sum([]Port{80, 443}))", 523},
+		{sumFn + "type Countable interface {\nNumber\n}\nfunc Count[T Countable](xs []T) T {\nreturn Sum(xs)\n}\nCount([]int{4, 5})", 9},
+		{stackType + "s := &Stack[int]{}\ns.Push(1)\ns.Push(2)\ns.Pop()", 1},
+		{stackType + "var s Stack[int]\ns.Push(5)\ns.Pop()", 5},
+		{"type Pair[K comparable, V any] struct {\nKey K\nValue V\n}\np := Pair[string, int]{Key: \"a\", Value: 4}\np.Value", 4},
+		{"func Zero[T any]() T {\nvar z T\nreturn z\n}\nZero[int]()", 0},
+		{"func Max[T ~int | ~string](a, b T) T {\nif a > b {\nreturn a\n}\nreturn b\n}\nMax(3, 5)", 5},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testFloatObject(t, testEval(sumFn+"Sum([]float64{1.5, 2})"), 3.5)
+	testStringObject(t, testEval(stackType+"s := &Stack[string]{}\ns.Push(\"x\")\ns.Pop()"), "x")
+}
+
+func TestGenericErrors(t *testing.T) {
+	tests := []string{
+		"func Uniq[T comparable](xs []T) []T {\nreturn xs\n}\nUniq([]func(){})",
+		"func Sum[T ~int | ~float64](x T) T {\nreturn x\n}\nSum(\"a\")",
+		"func Zero[T any]() T {\nvar z T\nreturn z\n}\nZero()",
+		"func Zero[T any]() T {\nvar z T\nreturn z\n}\nZero[int, int]()",
+		"func Add[T any](a, b T) T {\nreturn a + b\n}",
+		"func Eq[T any](a, b T) bool {\nreturn a == b\n}",
+		"func Zero[T any]() T {\nvar z T\nreturn z\n}\nf := Zero",
+		"type Box[T any] struct {\nv T\n}\nvar b Box",
+		"type Box[T any] struct {\nv T\n}\nvar b Box[int]\nvar c Box[string]\nb = c",
+		"type Number interface {\n~int | ~float64\n}\nvar n Number = 1",
+		"type Number interface {\n~int | ~float64\n}\nfunc f(xs []Number) {\n}",
+		"var c comparable",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestImports(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GOSHAPATH", filepath.Join(dir, "path"))
+	writeFiles(t, dir, map[string]string{
+		"lib/deploy.gosha":                  "#!gosha\npackage deploy\nimport \"company/helpers\"\nvar Runs = 0\nconst Version = 3\ntype Config struct {\nReplicas int\n}\nfunc (c Config) Total() int {\nreturn helpers.Double(c.Replicas)\n}\nfunc Run() int {\nRuns = Runs + 1\nreturn Runs\n}\n",
+		"path/company/helpers/double.gosha": "package helpers\nfunc Double(x int) int {\nreturn x * 2\n}\n",
+		"path/company/helpers/triple.gosha": "package helpers\nfunc Triple(x int) int {\nreturn x * 3\n}\n",
+		"a/util.gosha":                      "package util\ntype Config struct {\nN int\n}\nfunc (c Config) Get() int {\nreturn c.N\n}\n",
+		"b/util.gosha":                      "package util\ntype Config struct {\nN int\n}\nfunc (c Config) Get() int {\nreturn -c.N\n}\n",
+		"ifc/ifc.gosha":                     "package ifc\ntype Step interface {\nRun() int\n}\nfunc RunAll(steps []Step) int {\ntotal := 0\nfor _, s := range steps {\ntotal = total + s.Run()\n}\nreturn total\n}\n",
+		"common/common.gosha":               "package common\nvar N = 1\nfunc Get() int {\nreturn N\n}\n",
+	})
+
+	deploy := "import \"" + filepath.Join(dir, "lib", "deploy.gosha") + "\"\n"
+	// Both packages are named util.
+	utils := "import (\na \"" + filepath.Join(dir, "a") + "\"\nb \"" + filepath.Join(dir, "b") + "\"\n)\n"
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{deploy + "deploy.Run()", 1},
+		{deploy + "deploy.Version", 3},
+		{deploy + "c := deploy.Config{Replicas: 2}\nc.Total()", 4},
+		{deploy + "var c deploy.Config\nc.Replicas = 5\nc.Total()", 10},
+		{deploy + "type Config struct {\nReplicas int\n}\nc := Config{Replicas: 1}\nc.Replicas", 1},
+		{"import h \"company/helpers\"\nh.Double(2) + h.Triple(2)", 10},
+		{"import (\n\"company/helpers\"\n)\nhelpers.Triple(3)", 9},
+		{utils + "x := a.Config{N: 2}\ny := b.Config{N: 3}\nx.Get() * 10 + y.Get()", 17},
+		// Package functions call the methods of script types through interfaces.
+		{"import \"" + filepath.Join(dir, "ifc") + "\"\ntype Local struct {\nN int\n}\nfunc (l Local) Run() int {\nreturn l.N\n}\nifc.RunAll([]ifc.Step{Local{N: 2}, &Local{N: 3}})", 5},
+		// Exported package variables are assignable from the importer.
+		{"import \"" + filepath.Join(dir, "common") + "\"\ncommon.N = 10\ncommon.Get() + common.N", 20},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	// Packages are initialised once, however often they are imported.
+	testEval(deploy + "deploy.Run()")
+	testIntegerObject(t, testEval("import d \""+filepath.Join(dir, "lib", "deploy")+"\"\nd.Runs"), 2)
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.gosha":   "package a\nimport \"./b.gosha\"\n",
+		"b.gosha":   "package b\nimport \"./a.gosha\"\n",
+		"lib.gosha": "package lib\nfunc hidden() int {\nreturn 1\n}\nx := undefined\n",
+		"h/h.gosha": "package h\nconst Max = 3\ntype Host struct {\nName string\nsecret int\n}\nfunc (x Host) hidden() int {\nreturn x.secret\n}\n",
+	})
+
+	host := "import \"" + filepath.Join(dir, "h") + "\"\n"
+
+	tests := []string{
+		"import \"" + filepath.Join(dir, "a.gosha") + "\"",
+		"import \"" + filepath.Join(dir, "missing") + "\"",
+		"import \"" + filepath.Join(dir, "lib.gosha") + "\"",
+		"import \"company/missing\"",
+		host + "x := h.Host{Name: \"a\"}\nx.secret",
+		host + "x := h.Host{Name: \"a\"}\nx.hidden()",
+		host + "x := &h.Host{}\nx.secret = 1",
+		host + "h.Host{secret: 3}",
+		host + "h.Max = 4",
+		"import \"strings\"\nstrings.ToUpper = nil",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestTypedBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"xs := []int{1, 2, 3}\nlen(xs) - 1", 2},
+		{"xs := make([]int, 3)\nxs[2] = 4\nlen(xs) + xs[2]", 7},
+		{"xs := make([]int, 0, 8)\nxs = append(xs, 1, 2)\nlen(xs)", 2},
+		{"m := make(map[string]int)\nm[\"a\"] = 1\nlen(m)", 1},
+		{"c := make(chan int, 2)\nc <- 1\nlen(c)", 1},
+		{"var bs []byte\nbs = append(bs, \"abc\"...)\nlen(bs)", 3},
+		{"import \"strconv\"\nn, err := strconv.Atoi(\"42\")\nif err != nil {\nn = -1\n}\nn + 1", 43},
+		{"n, _ := strconv.Atoi(\"x\")\nn", 0},
+		{"f := 2.9\nint(f) + int(float64(3))", 5},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testStringObject(t, testEval("strconv.Itoa(7) + \"!\""), "7!")
+	testStringObject(t, testEval("_, err := strconv.Atoi(\"x\")\nerr.Error()"), "strconv.Atoi: parsing \"x\": invalid syntax")
+	testStringObject(t, testEval("string(65)"), "A")
+	testStringObject(t, testEval("strconv.FormatBool(true)"), "true")
+	testBooleanObject(t, testEval("b, _ := strconv.ParseBool(\"true\")\n!b"), false)
+	testFloatObject(t, testEval("f, _ := strconv.ParseFloat(\"1.5\", 64)\nf * 2.0"), 3)
+}
+
+func TestTypedBuiltinErrors(t *testing.T) {
+	tests := []string{
+		"x := make(int, 3)",
+		"x := make(string, 3)",
+		"x := make([]int)",
+		"x := make(map[string]int, 1, 2)",
+		"x := make(chan int, \"a\")",
+		"xs := []int{}\nxs = append(xs, \"a\")",
+		"x := 3\nx = append(x, 1)",
+		"len(5)",
+		"strconv.Atoi(5)",
+		"strconv.Itoa(\"5\")",
+		"n := strconv.Atoi(\"5\")",
+		"var s string = len(\"abc\")",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a\tb\n"`, "a\tb\n"},
+		{`"say \"hi\"\\"`, "say \"hi\"\\"},
+		{`"\x41é\U0001F600\101"`, "Aé😀A"},
+		{"`raw \\n \"string\"`", "raw \\n \"string\""},
+		{"`line 1\r\nline 2`", "line 1\nline 2"},
+		{"string('a') + string('\\n')", "a\n"},
+		{"s := \"\"\nfor _, r := range \"héllo\" {\nif r == 'é' {\ns = string(r)\n}\n}\ns", "é"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+
+	runeTests := []struct {
+		input    string
+		expected int64
+	}{
+		{"'a'", 97},
+		{"'\\''", 39},
+		{"'\\x7f'", 127},
+		{"'é' - 'a'", 136},
+		{"var b byte = 'b'\nb - 'a'", 1},
+		{"const c = '0' + 5\nc - '0'", 5},
+		{"b := \"x\"[0]\nn := 0\nswitch b {\ncase 'x':\nn = 1\n}\nn", 1},
+	}
+
+	for _, tt := range runeTests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestStringLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x := \"abc\ny := 1", "string literal not terminated"},
+		{"x := \"abc", "string literal not terminated"},
+		{"x := `abc", "raw string literal not terminated"},
+		{`x := "\q"`, `unknown escape sequence in string literal "\q"`},
+		{`x := "\'"`, `unknown escape sequence in string literal "\'"`},
+		{"x := 'ab'", "more than one character in rune literal 'ab'"},
+		{"x := ''", "empty rune literal or unescaped ' in rune literal"},
+		{"x := 'a", "rune literal not terminated"},
+		{`x := '\"'`, `unknown escape sequence in rune literal '\"'`},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if errors := p.Errors(); len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"name := \"web\"\nport := 8080\nf\"host {name}:{port}\"", "host web:8080"},
+		{"m := map[string]int{\"a\": 1}\nf\"a={m[\"a\"]} next={m[\"a\"] + 1}\"", "a=1 next=2"},
+		{"f\"{1.5} {true} {len(\"abc\")}\"", "1.5 true 3"},
+		{"f\"{{literal}} \\t{\"x\"}\"", "{literal} \tx"},
+		{"type P struct {\nX int\n}\np := P{X: 3}\nf\"{p.X * 2}\"", "6"},
+		{"func greet(s string) string {\nreturn f\"hi {s}!\"\n}\ngreet(\"bob\")", "hi bob!"},
+		{"x := 1\nf\"outer {f\"inner {x}\"}\"", "outer inner 1"},
+		{"f\"\"", ""},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestInterpolatedStringErrors(t *testing.T) {
+	tests := []string{
+		"f\"{undefined}\"",
+		"f\"{print(1)}\"",
+		"func two() (int, int) {\nreturn 1, 2\n}\nf\"{two()}\"",
+		"x := 1\nf\"{x}\" + 1",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+		}
+	}
+
+	parserTests := []struct {
+		input    string
+		expected string
+	}{
+		{"x := f\"a {}\"", "empty expression in interpolated string"},
+		{"x := f\"a {1 2}\"", "unexpected 2 in interpolated string expression \"1 2\""},
+		{"x := f\"a } b\"", "single '}' is not allowed in interpolated string"},
+		{"x := f\"a {1 + 2", "expected } in interpolated string"},
+		{"x := f\"abc", "string literal not terminated"},
+	}
+
+	for _, tt := range parserTests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if errors := p.Errors(); len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"kstmc.com/gosha/internal/token"
 )
 
@@ -9,6 +13,7 @@ type Lexer struct {
 	position     int
 	readPosition int
 	ch           byte
+	errors       []string
 }
 
 func New(input string) *Lexer {
@@ -17,6 +22,11 @@ func New(input string) *Lexer {
 	return l
 }
 
+// Errors returns the errors of malformed literals read so far.
+func (l *Lexer) Errors() []string {
+	return l.errors
+}
+
 func (l *Lexer) readCh() {
 	l.ch = 0
 	if l.readPosition < len(l.input) {
@@ -50,37 +60,47 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.BANG, l.ch)
 		}
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
+		tok = l.readOperator(token.SLASH, map[string]token.TokenType{"/=": token.DIVASSIGN})
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		tok = l.readOperator(token.ASTERISK, map[string]token.TokenType{"*=": token.MULASSIGN})
 	case '<':
-		if l.peekChar() == '-' {
-			ch := string(l.ch)
-			l.readCh()
-			tok.Type = token.CHANOPERATOR
-			tok.Literal = ch + string(l.ch)
-		} else {
-			tok = newToken(token.LT, l.ch)
-		}
+		tok = l.readOperator(token.LT, map[string]token.TokenType{
+			"<-":  token.CHANOPERATOR,
+			"<=":  token.LTEQ,
+			"<<":  token.SHL,
+			"<<=": token.SHLASSIGN,
+		})
 	case '"':
 		tok.Literal = l.readString()
 		tok.Type = token.STRING
+	case '`':
+		tok.Literal = l.readRawString()
+		tok.Type = token.STRING
+	case '\'':
+		tok.Literal = l.readRune()
+		tok.Type = token.CHAR
 	case ':':
 		if l.peekChar() == '=' {
 			ch := string(l.ch)
 			l.readCh()
 			tok = token.Token{Type: token.INITASSIGN, Literal: ch + string(l.ch)}
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			tok = newToken(token.COLON, l.ch)
 		}
 	case '[':
 		tok = newToken(token.LBRACKET, l.ch)
 	case ']':
 		tok = newToken(token.RBRACKET, l.ch)
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		tok = l.readOperator(token.GT, map[string]token.TokenType{
+			">=":  token.GTEQ,
+			">>":  token.SHR,
+			">>=": token.SHRASSIGN,
+		})
 	case '%':
-		tok = newToken(token.PERCENT, l.ch)
+		tok = l.readOperator(token.PERCENT, map[string]token.TokenType{"%=": token.MODASSIGN})
+	case '^':
+		tok = l.readOperator(token.XOR, map[string]token.TokenType{"^=": token.XORASSIGN})
 	case ';':
 		tok = newToken(token.NLINE, l.ch)
 	case '(':
@@ -111,32 +131,39 @@ func (l *Lexer) NextToken() token.Token {
 		}
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
-	case '&':
-		if l.peekChar() == '&' {
-			ch := string(l.ch)
+	case '~':
+		tok = newToken(token.TILDE, l.ch)
+	case '.':
+		if strings.HasPrefix(l.input[l.position:], "...") {
 			l.readCh()
-			tok = token.Token{Type: token.AND, Literal: ch + string(l.ch)}
-		} else {
-			tok = newToken(token.REF, l.ch)
-		}
-	case '|':
-		if l.peekChar() == '|' {
-			ch := string(l.ch)
 			l.readCh()
-			tok = token.Token{Type: token.OR, Literal: ch + string(l.ch)}
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
 		} else {
-			tok = newToken(token.PIPE, l.ch)
+			tok = newToken(token.DOT, l.ch)
 		}
+	case '&':
+		tok = l.readOperator(token.REF, map[string]token.TokenType{
+			"&&":  token.AND,
+			"&=":  token.ANDASSIGN,
+			"&^":  token.ANDNOT,
+			"&^=": token.ANDNOTASSIGN,
+		})
+	case '|':
+		tok = l.readOperator(token.PIPE, map[string]token.TokenType{
+			"||": token.OR,
+			"|=": token.ORASSIGN,
+		})
 	case '-':
-		if l.peekChar() == 'f' {
-			ch := string(l.ch)
-			l.readCh()
-			tok = token.Token{Type: token.FOPER, Literal: ch + string(l.ch)}
-		} else {
-			tok = newToken(token.MINUS, l.ch)
-		}
+		tok = l.readOperator(token.MINUS, map[string]token.TokenType{
+			"-f": token.FOPER,
+			"--": token.DEC,
+			"-=": token.MINUSASSIGN,
+		})
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		tok = l.readOperator(token.PLUS, map[string]token.TokenType{
+			"++": token.INC,
+			"+=": token.PLUSASSIGN,
+		})
 	case '{':
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
@@ -145,13 +172,16 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Literal = ""
 		tok.Type = token.EOF
 	default:
-		if isLetter(l.ch) {
+		if l.ch == 'f' && l.peekChar() == '"' {
+			l.readCh()
+			tok.Literal = l.readInterpolatedString()
+			tok.Type = token.FSTRING
+		} else if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.FindIdent(tok.Literal)
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
+			tok.Literal, tok.Type = l.readNumericLiteral()
 			return tok
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
@@ -162,6 +192,23 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
+// readOperator reads the longest operator in operators that starts at the current
+// character, or the single character operator of type single.
+func (l *Lexer) readOperator(single token.TokenType, operators map[string]token.TokenType) token.Token {
+	tok := newToken(single, l.ch)
+	for literal, tokenType := range operators {
+		if len(literal) > len(tok.Literal) && strings.HasPrefix(l.input[l.position:], literal) {
+			tok = token.Token{Type: tokenType, Literal: literal}
+		}
+	}
+
+	for i := 1; i < len(tok.Literal); i++ {
+		l.readCh()
+	}
+
+	return tok
+}
+
 func (l *Lexer) readBash() string {
 	position := l.position + 1
 	l.readCh()
@@ -188,16 +235,186 @@ func newToken(tokenType token.TokenType, ch byte) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
+// readString reads an interpreted string literal and returns its value with the
+// escape sequences decoded.
 func (l *Lexer) readString() string {
 	position := l.position + 1
 	l.readCh()
-	for l.ch != '"' && l.ch != 0 {
+	for l.ch != '"' {
+		if l.ch == 0 || l.ch == '\n' {
+			l.errors = append(l.errors, "string literal not terminated")
+			break
+		}
+
+		if l.ch == '\\' && l.peekChar() != 0 {
+			l.readCh()
+		}
+
 		l.readCh()
 	}
 
+	return l.unquote(l.input[position:l.position])
+}
+
+// unquote decodes the escape sequences of the body of an interpreted string literal.
+func (l *Lexer) unquote(body string) string {
+	var out strings.Builder
+	for rest := body; rest != ""; {
+		value, multibyte, tail, err := strconv.UnquoteChar(rest, '"')
+		if err != nil {
+			l.errors = append(l.errors, fmt.Sprintf(`unknown escape sequence in string literal "%s"`, body))
+			return body
+		}
+
+		if multibyte {
+			out.WriteRune(value)
+		} else {
+			out.WriteByte(byte(value))
+		}
+
+		rest = tail
+	}
+
+	return out.String()
+}
+
+// readInterpolatedString reads an interpolated string literal f"..." with expressions
+// embedded in braces, and returns its body undecoded. SplitInterpolated splits the
+// body into its parts.
+func (l *Lexer) readInterpolatedString() string {
+	position := l.position + 1
+	l.readInterpolated()
 	return l.input[position:l.position]
 }
 
+// SplitInterpolated splits the body of an interpolated string literal into its
+// decoded text segments and the sources of the expressions embedded between them.
+// There is one more text segment than there are expressions. It reports false for
+// a malformed body, whose errors were reported when the literal was read.
+func SplitInterpolated(body string) ([]string, []string, bool) {
+	l := New(`"` + body + `"`)
+	texts, sources := l.readInterpolated()
+	return texts, sources, len(l.errors) == 0
+}
+
+// readInterpolated reads the parts of an interpolated string literal up to its
+// closing quote. {{ and }} stand for single braces in the text segments.
+func (l *Lexer) readInterpolated() ([]string, []string) {
+	var texts, sources []string
+	var text strings.Builder
+	l.readCh()
+	for l.ch != '"' {
+		switch {
+		case l.ch == 0 || l.ch == '\n':
+			l.errors = append(l.errors, "string literal not terminated")
+			return append(texts, l.unquote(text.String())), sources
+		case l.ch == '\\' && l.peekChar() != 0:
+			text.WriteByte(l.ch)
+			l.readCh()
+			text.WriteByte(l.ch)
+		case (l.ch == '{' || l.ch == '}') && l.peekChar() == l.ch:
+			text.WriteByte(l.ch)
+			l.readCh()
+		case l.ch == '{':
+			texts = append(texts, l.unquote(text.String()))
+			text.Reset()
+			sources = append(sources, l.readEmbeddedExpression())
+			if l.ch != '}' {
+				return append(texts, ""), sources
+			}
+		case l.ch == '}':
+			l.errors = append(l.errors, "single '}' is not allowed in interpolated string")
+		default:
+			text.WriteByte(l.ch)
+		}
+
+		l.readCh()
+	}
+
+	return append(texts, l.unquote(text.String())), sources
+}
+
+// readEmbeddedExpression reads the source of an expression embedded in braces in an
+// interpolated string, up to the closing brace.
+func (l *Lexer) readEmbeddedExpression() string {
+	position := l.position + 1
+	depth := 1
+	for {
+		l.readCh()
+		switch l.ch {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return l.input[position:l.position]
+			}
+		case '"':
+			l.readString()
+		case '`':
+			l.readRawString()
+		case '\'':
+			l.readRune()
+		}
+
+		if l.ch == 0 || l.ch == '\n' {
+			l.errors = append(l.errors, "expected } in interpolated string")
+			return l.input[position:l.position]
+		}
+	}
+}
+
+// readRawString reads a raw string literal, which may span lines and has no escape
+// sequences. Carriage returns are dropped from it like in Go.
+func (l *Lexer) readRawString() string {
+	position := l.position + 1
+	l.readCh()
+	for l.ch != '`' {
+		if l.ch == 0 {
+			l.errors = append(l.errors, "raw string literal not terminated")
+			break
+		}
+
+		l.readCh()
+	}
+
+	return strings.ReplaceAll(l.input[position:l.position], "\r", "")
+}
+
+// readRune reads a rune literal such as 'a' or '\n' and returns the rune it denotes.
+func (l *Lexer) readRune() string {
+	position := l.position + 1
+	l.readCh()
+	for l.ch != '\'' {
+		if l.ch == 0 || l.ch == '\n' {
+			l.errors = append(l.errors, "rune literal not terminated")
+			return ""
+		}
+
+		if l.ch == '\\' && l.peekChar() != 0 {
+			l.readCh()
+		}
+
+		l.readCh()
+	}
+
+	body := l.input[position:l.position]
+	if body == "" {
+		l.errors = append(l.errors, "empty rune literal or unescaped ' in rune literal")
+		return ""
+	}
+
+	value, _, tail, err := strconv.UnquoteChar(body, '\'')
+	switch {
+	case err != nil:
+		l.errors = append(l.errors, fmt.Sprintf("unknown escape sequence in rune literal '%s'", body))
+	case tail != "":
+		l.errors = append(l.errors, fmt.Sprintf("more than one character in rune literal '%s'", body))
+	}
+
+	return string(value)
+}
+
 func (l *Lexer) readIdentifier() string {
 	position := l.position
 	for isLetter(l.ch) || isDigit(l.ch) {
@@ -224,6 +441,43 @@ func (l *Lexer) readNumber() string {
 	return l.input[position:l.position]
 }
 
+// readNumericLiteral reads an integer literal or a floating-point literal such as
+// 1.5, 1e9 or 2.5e-3.
+func (l *Lexer) readNumericLiteral() (string, token.TokenType) {
+	position := l.position
+	var tokenType token.TokenType = token.INT
+
+	l.readNumber()
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readCh()
+		l.readNumber()
+	}
+
+	if (l.ch == 'e' || l.ch == 'E') && l.isExponent() {
+		tokenType = token.FLOAT
+		l.readCh()
+		if l.ch == '+' || l.ch == '-' {
+			l.readCh()
+		}
+
+		l.readNumber()
+	}
+
+	return l.input[position:l.position], tokenType
+}
+
+// isExponent reports whether the 'e' at the current position starts the exponent
+// of a floating-point literal.
+func (l *Lexer) isExponent() bool {
+	next := l.readPosition
+	if next < len(l.input) && (l.input[next] == '+' || l.input[next] == '-') {
+		next++
+	}
+
+	return next < len(l.input) && isDigit(l.input[next])
+}
+
 func isDigit(ch byte) bool {
 	return '0' <= ch && ch <= '9'
 }
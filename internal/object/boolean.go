@@ -11,6 +11,21 @@ type Boolean struct {
 	Value bool
 }
 
+// TRUE and FALSE are the only boolean objects, the evaluator compares booleans by identity.
+var (
+	TRUE  = &Boolean{Value: true}
+	FALSE = &Boolean{Value: false}
+)
+
+// NativeBoolean returns the boolean object of value.
+func NativeBoolean(value bool) *Boolean {
+	if value {
+		return TRUE
+	}
+
+	return FALSE
+}
+
 func (b *Boolean) Type() ast.DataType {
 	return parser.BOOLEAN
 }
@@ -18,3 +33,7 @@ func (b *Boolean) Type() ast.DataType {
 func (b *Boolean) Inspect() string {
 	return fmt.Sprintf("%t", b.Value)
 }
+
+func (b *Boolean) HashKey() HashKey {
+	return HashKey{Type: b.Type().Name(), Value: b.Inspect()}
+}
@@ -5,13 +5,36 @@ import (
 	"kstmc.com/gosha/internal/parser"
 )
 
+// BreakObject is produced by a break statement. Label is empty for an unlabeled break.
 type BreakObject struct {
+	Label string
 }
 
 func (bo *BreakObject) Inspect() string {
+	if bo.Label != "" {
+		return "break " + bo.Label
+	}
+
 	return "break"
 }
 
 func (bo *BreakObject) Type() ast.DataType {
 	return parser.BREAK
 }
+
+// ContinueObject is produced by a continue statement. Label is empty for an unlabeled continue.
+type ContinueObject struct {
+	Label string
+}
+
+func (co *ContinueObject) Inspect() string {
+	if co.Label != "" {
+		return "continue " + co.Label
+	}
+
+	return "continue"
+}
+
+func (co *ContinueObject) Type() ast.DataType {
+	return parser.CONTINUE
+}
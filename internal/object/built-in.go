@@ -2,7 +2,6 @@ package object
 
 import (
 	"fmt"
-	"strconv"
 
 	"kstmc.com/gosha/internal/ast"
 	"kstmc.com/gosha/internal/parser"
@@ -14,8 +13,13 @@ type Builtin struct {
 	Name string
 	Fn   BuiltinFunc
 	//FnName     string
-	//Parameters []*ast.Identifier
-	//ReturnType ast.DataType
+
+	// Parameters are the static types of the builtin arguments. The arguments of
+	// builtins without them are not checked by the analyzer.
+	Parameters []ast.DataType
+	// ReturnType is the static type of the builtin result. Results of builtins
+	// without it are analyzed as any.
+	ReturnType ast.DataType
 }
 
 func (bi *Builtin) Inspect() string {
@@ -23,12 +27,50 @@ func (bi *Builtin) Inspect() string {
 }
 
 func (bi *Builtin) Type() ast.DataType {
+	if bi.ReturnType != nil {
+		return &ast.BuiltinDataType{Parameters: bi.Parameters, ReturnType: bi.ReturnType}
+	}
+
 	return parser.BUILTIN
 }
 
+// BuiltinMethod is a method of a type implemented by the interpreter itself.
+type BuiltinMethod struct {
+	Type *ast.FunctionDataType
+	Fn   func(receiver Object, args ...Object) Object
+}
+
+// Bind returns the method as a builtin with receiver bound to it.
+func (bm *BuiltinMethod) Bind(name string, receiver Object) *Builtin {
+	return &Builtin{
+		Name: name,
+		Fn: func(args ...Object) Object {
+			return bm.Fn(receiver, args...)
+		},
+		ReturnType: bm.Type.ReturnType,
+	}
+}
+
+// BuiltinMethods holds the methods of interpreter types by type name.
+var BuiltinMethods = map[string]map[string]*BuiltinMethod{
+	ErrorStringType.Name(): {
+		"Error": {Type: &ast.FunctionDataType{ReturnType: parser.STRING}, Fn: errorMethod},
+	},
+	WrapErrorType.Name(): {
+		"Error":  {Type: &ast.FunctionDataType{ReturnType: parser.STRING}, Fn: errorMethod},
+		"Unwrap": {Type: &ast.FunctionDataType{ReturnType: parser.ERROR_INTERFACE}, Fn: unwrapMethod},
+	},
+	CommandErrorType.Name(): {
+		"Error":    {Type: &ast.FunctionDataType{ReturnType: parser.STRING}, Fn: errorMethod},
+		"ExitCode": {Type: &ast.FunctionDataType{ReturnType: parser.INT}, Fn: exitCodeMethod},
+		"Stderr":   {Type: &ast.FunctionDataType{ReturnType: parser.STRING}, Fn: stderrMethod},
+	},
+}
+
 var Builtins = map[string]*Builtin{
 	"print": {
-		Name: "print",
+		Name:       "print",
+		ReturnType: parser.NIL,
 		Fn: func(args ...Object) Object {
 			for _, arg := range args {
 				fmt.Print(arg.Inspect() + " ")
@@ -38,16 +80,42 @@ var Builtins = map[string]*Builtin{
 			return &Nil{}
 		},
 	},
+	"panic": {
+		Name:       "panic",
+		ReturnType: parser.NIL,
+		Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 1, provided %d", len(args))}
+			}
+
+			return &Error{Message: "panic: " + Sprintf("%v", args[0]), Value: args[0]}
+		},
+	},
+	// recover is evaluated by the evaluator, which knows the panicking function call.
+	"recover": {
+		Name:       "recover",
+		ReturnType: parser.ANY,
+		Fn: func(args ...Object) Object {
+			return &Nil{}
+		},
+	},
 	"len": {
-		Name: "len",
+		Name:       "len",
+		ReturnType: parser.INT,
 		Fn: func(args ...Object) Object {
 			if len(args) != 1 {
 				return &Nil{}
 			}
 
-			switch arg := args[0].(type) {
+			switch arg := Unwrap(args[0]).(type) {
 			case *SliceObject:
 				return &Integer{Value: int64(len(arg.Values))}
+			case *MapObject:
+				return &Integer{Value: int64(len(arg.Pairs))}
+			case *String:
+				return &Integer{Value: int64(len(arg.Value))}
+			case *ChanObject:
+				return &Integer{Value: int64(len(arg.Chan))}
 			default:
 				return &Nil{}
 			}
@@ -65,111 +133,130 @@ var Builtins = map[string]*Builtin{
 				return &Nil{}
 			}
 
+			values := slice.Values
 			for _, arg := range args[1:] {
-				if arg.Type().Name() != slice.Type().(*ast.SliceDataType).Type.Name() {
+				// structs are appended by value
+				if structObj, ok := arg.(*StructObject); ok {
+					arg = structObj.Copy()
+				}
+
+				value := Convert(arg, slice.ValueType)
+				if !IsInterfaceType(slice.ValueType) && value.Type().Name() != slice.ValueType.Name() {
 					return &Nil{}
 				}
+
+				values = append(values, value)
 			}
 
 			newSlice := &SliceObject{
 				ValueType: slice.ValueType,
-				Values:    append(slice.Values, args[1:]...),
+				Values:    values,
+				SliceType: slice.SliceType,
 			}
 
 			return newSlice
 		},
 	},
+	"delete": {
+		Name:       "delete",
+		ReturnType: parser.NIL,
+		Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 2, provided %d", len(args))}
+			}
+
+			mapObj, ok := args[0].(*MapObject)
+			if !ok {
+				return &Error{Message: fmt.Sprintf("expected map argument to delete, got=%T", args[0])}
+			}
+
+			key := args[1]
+			if !IsInterfaceType(mapObj.KeyType) {
+				key = Convert(key, mapObj.KeyType)
+			}
+
+			hashable, ok := HashableKey(key)
+			if !ok {
+				return &Error{Message: fmt.Sprintf("unusable as map key: %s", Unwrap(args[1]).Type().Name())}
+			}
+
+			delete(mapObj.Pairs, hashable.HashKey())
+			return &Nil{}
+		},
+	},
+	"close": {
+		Name:       "close",
+		ReturnType: parser.NIL,
+		Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 1, provided %d", len(args))}
+			}
+
+			chn, ok := args[0].(*ChanObject)
+			if !ok {
+				return &Error{Message: fmt.Sprintf("expected chan argument to close, got=%T", args[0])}
+			}
+
+			if chn.Chan == nil {
+				return &Error{Message: "close of nil channel"}
+			}
+
+			if !chn.Close() {
+				return &Error{Message: "close of closed channel"}
+			}
+
+			return &Nil{}
+		},
+	},
 	"read": {
-		Name: "read",
+		Name:       "read",
+		ReturnType: parser.NIL,
 		Fn: func(args ...Object) Object {
 			if len(args) != 1 {
 				return &Nil{}
 			}
 
 			ref, ok := args[0].(*ReferenceObject)
-			if !ok {
+			if !ok || ref.Value == nil {
 				return &Nil{}
 			}
 
-			arg := ref.Value
-			switch arg := (*arg).(type) {
+			// The scanned value replaces the referenced one, which other
+			// variables may share.
+			switch arg := (*ref.Value).(type) {
 			case *Integer:
-				fmt.Scan(&arg.Value)
+				value := *arg
+				fmt.Scan(&value.Value)
+				*ref.Value = &value
+			case *Float:
+				value := *arg
+				fmt.Scan(&value.Value)
+				*ref.Value = &value
 			case *String:
-				fmt.Scan(&arg.Value)
+				value := *arg
+				fmt.Scan(&value.Value)
+				*ref.Value = &value
 			case *Boolean:
-				fmt.Scan(&arg.Value)
+				value := arg.Value
+				fmt.Scan(&value)
+				*ref.Value = NativeBoolean(value)
 			}
 
 			return &Nil{}
 		},
 	},
+	// new is evaluated by the evaluator, which knows the zero values of types.
+	"new": {
+		Name: "new",
+		Fn: func(args ...Object) Object {
+			return &Nil{}
+		},
+	},
+	// make is evaluated by the evaluator, which knows the zero values of types.
 	"make": {
 		Name: "make",
 		Fn: func(args ...Object) Object {
-			arg, ok := args[0].(*DataTypeObject)
-			if !ok {
-				return &Error{Message: fmt.Sprintf("cannot make %s", arg)}
-			}
-			switch arg := arg.DataType.(type) {
-			case *ast.IntegerDataType:
-				return makeIntegerObject(args[1:])
-			case *ast.StringDataType:
-				return makeStringObject(args[1:])
-			case *ast.ChanDataType:
-				return makeChanObject(args)
-			default:
-				return &Error{Message: fmt.Sprintf("cannot make %s", arg)}
-			}
+			return &Nil{}
 		},
 	},
 }
-
-func makeChanObject(objects []Object) Object {
-	if len(objects) != 2 {
-		return &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 2, provided %d", len(objects)+1)}
-	}
-
-	val, ok := objects[1].(*Integer)
-	if !ok {
-		return &Error{Message: fmt.Sprintf("expected Integer argument to make chan, got=%T", objects[0])}
-	}
-
-	ch := make(chan Object, val.Value)
-	return &ChanObject{Chan: ch, ChanType: objects[0].(*DataTypeObject).DataType.(*ast.ChanDataType).ValueType}
-}
-
-func makeStringObject(objects []Object) Object {
-	if len(objects) != 1 {
-		return &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 2, provided %d", len(objects)+1)}
-	}
-
-	switch obj := objects[0].(type) {
-	case *Integer:
-		return &String{Value: strconv.FormatInt(obj.Value, 10)}
-	case *String:
-		return &String{Value: obj.Value}
-	default:
-		return &Error{Message: fmt.Sprintf("cannot make Integer from %s", obj.Inspect())}
-	}
-}
-
-func makeIntegerObject(objects []Object) Object {
-	if len(objects) != 1 {
-		return &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 2, provided %d", len(objects)+1)}
-	}
-
-	switch obj := objects[0].(type) {
-	case *Integer:
-		return &Integer{Value: obj.Value}
-	case *String:
-		val, err := strconv.Atoi(obj.Value)
-		if err != nil {
-			return &Error{Message: err.Error()}
-		}
-
-		return &Integer{Value: int64(val)}
-	default:
-		return &Error{Message: fmt.Sprintf("cannot make Integer from %s", obj.Inspect())}
-	}
-}
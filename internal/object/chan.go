@@ -2,6 +2,7 @@ package object
 
 import "kstmc.com/gosha/internal/ast"
 
+// ChanObject is a channel value. Chan is nil for nil channels.
 type ChanObject struct {
 	Chan     chan Object
 	ChanType ast.DataType
@@ -14,3 +15,29 @@ func (co *ChanObject) Inspect() string {
 func (co *ChanObject) Type() ast.DataType {
 	return &ast.ChanDataType{ValueType: co.ChanType}
 }
+
+// Send sends val on the channel. It reports false instead of panicking if the
+// channel is closed.
+func (co *ChanObject) Send(val Object) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	co.Chan <- val
+	return true
+}
+
+// Close closes the channel. It reports false instead of panicking if the channel
+// is already closed.
+func (co *ChanObject) Close() (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	close(co.Chan)
+	return true
+}
@@ -0,0 +1,69 @@
+package object
+
+import (
+	"math/big"
+
+	"kstmc.com/gosha/internal/ast"
+	"kstmc.com/gosha/internal/parser"
+)
+
+// Constant is a named constant known to the analyzer. DataType is nil for untyped
+// constants, which take the type their context requires.
+type Constant struct {
+	Value    Object
+	DataType ast.DataType
+}
+
+func (c *Constant) Inspect() string {
+	return c.Value.Inspect()
+}
+
+func (c *Constant) Type() ast.DataType {
+	if c.DataType != nil {
+		return c.DataType
+	}
+
+	return c.Value.Type()
+}
+
+// Default returns the value of the constant in its default type, e.g. an untyped
+// integer constant as an int.
+func (c *Constant) Default() Object {
+	switch value := c.Value.(type) {
+	case *UntypedInteger:
+		return &Integer{Value: value.Value.Int64()}
+	case *UntypedFloat:
+		f, _ := value.Value.Float64()
+		return &Float{Value: f}
+	default:
+		return c.Value
+	}
+}
+
+// UntypedInteger and UntypedFloat are the exact values of untyped numeric constants.
+// They are folded with arbitrary precision and only need to be representable by the
+// type they take where they are used.
+type UntypedInteger struct {
+	Value *big.Int
+}
+
+func (u *UntypedInteger) Inspect() string {
+	return u.Value.String()
+}
+
+func (u *UntypedInteger) Type() ast.DataType {
+	return parser.INT
+}
+
+type UntypedFloat struct {
+	Value *big.Rat
+}
+
+func (u *UntypedFloat) Inspect() string {
+	f, _ := u.Value.Float64()
+	return (&Float{Value: f}).Inspect()
+}
+
+func (u *UntypedFloat) Type() ast.DataType {
+	return parser.FLOAT64
+}
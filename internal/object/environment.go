@@ -1,13 +1,47 @@
 package object
 
+import (
+	"sync"
+
+	"kstmc.com/gosha/internal/ast"
+)
+
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	// store holds each variable in its own cell, which pointers to the variable share.
+	store   map[string]*Object
+	methods map[string]map[string]Object
+	outer   *Environment
+
+	// sameScope is set for environments that belong to the scope of their outer
+	// environment, like the one a top-level statement is analyzed in.
+	sameScope bool
+
+	// dir is the directory of the file evaluated in a top-level environment.
+	// Relative imports are resolved against it.
+	dir string
+
+	// path is the import path of the package whose files are evaluated in a
+	// top-level environment, and empty for the main script.
+	path string
+
+	// function is set for the outermost scope of a function call, which holds
+	// the calls deferred by the function and the panic it may recover.
+	function bool
+	deferred []func(*Panic) Object
+	panic    *Panic
+}
+
+// Panic is the state of a panicking function call. Functions deferred by the call
+// may recover it.
+type Panic struct {
+	Error     *Error
+	Recovered bool
 }
 
 func NewEnvironment() *Environment {
-	s := make(map[string]Object)
-	return &Environment{store: s, outer: nil}
+	s := make(map[string]*Object)
+	m := make(map[string]map[string]Object)
+	return &Environment{store: s, methods: m, outer: nil}
 }
 
 func NewEnclosedEnvironment(outer *Environment) *Environment {
@@ -16,22 +50,63 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	return env
 }
 
+// NewSameScopeEnvironment creates an environment whose declarations belong to the
+// scope of outer, but are kept apart from it.
+func NewSameScopeEnvironment(outer *Environment) *Environment {
+	env := NewEnclosedEnvironment(outer)
+	env.sameScope = true
+	return env
+}
+
+// NewFunctionEnvironment creates the scope of a function call.
+func NewFunctionEnvironment(outer *Environment) *Environment {
+	env := NewEnclosedEnvironment(outer)
+	env.function = true
+	return env
+}
+
 func UnwrapEnvironment(env *Environment) *Environment {
 	return env.outer
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
-	obj, ok := e.store[name]
+	cell, ok := e.Ref(name)
+	if !ok {
+		return nil, false
+	}
+
+	return *cell, true
+}
+
+// Ref returns the cell holding the variable name. Updates of the variable are
+// visible through the cell.
+func (e *Environment) Ref(name string) (*Object, bool) {
+	cell, ok := e.store[name]
 	if !ok && e.outer != nil {
-		obj, ok = e.outer.Get(name)
+		return e.outer.Ref(name)
 	}
 
-	return obj, ok
+	return cell, ok
+}
+
+// GetLocal returns the variable name declared in the scope of e, ignoring the
+// enclosing scopes.
+func (e *Environment) GetLocal(name string) (Object, bool) {
+	cell, ok := e.store[name]
+	if !ok && e.sameScope && e.outer != nil {
+		return e.outer.GetLocal(name)
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	return *cell, true
 }
 
 func (e *Environment) Update(name string, value Object) Object {
-	if _, ok := e.store[name]; ok {
-		return e.Set(name, value)
+	if cell, ok := e.store[name]; ok {
+		*cell = value
 	} else if e.outer != nil {
 		return e.outer.Update(name, value)
 	}
@@ -39,8 +114,9 @@ func (e *Environment) Update(name string, value Object) Object {
 	return value
 }
 
+// Set declares the variable name in e with a new cell.
 func (e *Environment) Set(name string, value Object) Object {
-	e.store[name] = value
+	e.store[name] = &value
 	return value
 }
 
@@ -51,3 +127,141 @@ func (e *Environment) Contains(name string) bool {
 	}
 	return ok
 }
+
+func (e *Environment) SetMethod(typeName string, name string, fn Object) Object {
+	if _, ok := e.methods[typeName]; !ok {
+		e.methods[typeName] = make(map[string]Object)
+	}
+
+	e.methods[typeName][name] = fn
+	return fn
+}
+
+func (e *Environment) GetMethod(typeName string, name string) (Object, bool) {
+	fn, ok := e.methods[typeName][name]
+	if !ok && e.outer != nil {
+		fn, ok = e.outer.GetMethod(typeName, name)
+	}
+
+	return fn, ok
+}
+
+// typeMethods holds the methods of named types by the declaration of the type, so
+// that values find their methods wherever they are used, e.g. in the functions of a
+// package they are passed to.
+var (
+	typeMethods   = map[*ast.NamedDataType]map[string]Object{}
+	typeMethodsMu sync.RWMutex
+)
+
+// SetTypeMethod declares the method name of the named type dType.
+func SetTypeMethod(dType ast.DataType, name string, fn Object) {
+	decl, ok := ast.Declaration(dType)
+	if !ok {
+		return
+	}
+
+	typeMethodsMu.Lock()
+	defer typeMethodsMu.Unlock()
+
+	if _, ok := typeMethods[decl]; !ok {
+		typeMethods[decl] = make(map[string]Object)
+	}
+
+	typeMethods[decl][name] = fn
+}
+
+// LookupMethod returns the method name of values of type dType. Methods are found by
+// the declaration of the type, or else by its name in e.
+func (e *Environment) LookupMethod(dType ast.DataType, name string) (Object, bool) {
+	if decl, ok := ast.Declaration(dType); ok {
+		typeMethodsMu.RLock()
+		fn, ok := typeMethods[decl][name]
+		typeMethodsMu.RUnlock()
+
+		if ok {
+			return fn, true
+		}
+	}
+
+	return e.GetMethod(ast.TypeName(dType), name)
+}
+
+// ImportMethods makes the methods declared in the environment of an imported
+// package callable in e. Methods of package types are declared under type names
+// qualified with the import path of the package, e.g. /opt/lib/deploy.Config, and
+// don't clash with those of e or of other packages with the same name.
+func (e *Environment) ImportMethods(pkg *Environment) {
+	for typeName, methods := range pkg.methods {
+		for name, fn := range methods {
+			e.SetMethod(typeName, name, fn)
+		}
+	}
+}
+
+// SetDir sets the directory of the file evaluated in e.
+func (e *Environment) SetDir(dir string) {
+	e.dir = dir
+}
+
+// Dir returns the directory of the file evaluated in e or its enclosing environments.
+func (e *Environment) Dir() string {
+	if e.dir == "" && e.outer != nil {
+		return e.outer.Dir()
+	}
+
+	return e.dir
+}
+
+// SetPackagePath sets the import path of the package evaluated in e.
+func (e *Environment) SetPackagePath(path string) {
+	e.path = path
+}
+
+// PackagePath returns the import path of the package evaluated in e or its enclosing
+// environments.
+func (e *Environment) PackagePath() string {
+	if e.path == "" && e.outer != nil {
+		return e.outer.PackagePath()
+	}
+
+	return e.path
+}
+
+// functionScope returns the scope of the innermost function call enclosing e,
+// or the outermost scope outside of functions.
+func (e *Environment) functionScope() *Environment {
+	scope := e
+	for !scope.function && scope.outer != nil {
+		scope = scope.outer
+	}
+
+	return scope
+}
+
+// Defer registers fn to run when the innermost enclosing function returns.
+// fn receives the panic of that function, if any.
+func (e *Environment) Defer(fn func(*Panic) Object) {
+	scope := e.functionScope()
+	scope.deferred = append(scope.deferred, fn)
+}
+
+// TakeDeferred removes and returns the calls deferred in the function scope of e,
+// in the order they were deferred.
+func (e *Environment) TakeDeferred() []func(*Panic) Object {
+	scope := e.functionScope()
+	deferred := scope.deferred
+	scope.deferred = nil
+
+	return deferred
+}
+
+// SetPanic makes p recoverable by the function call that e is the scope of.
+func (e *Environment) SetPanic(p *Panic) {
+	e.panic = p
+}
+
+// Panic returns the panic recoverable in the innermost function enclosing e, or nil.
+func (e *Environment) Panic() *Panic {
+	return e.functionScope().panic
+}
@@ -0,0 +1,76 @@
+package object
+
+import (
+	"strconv"
+
+	"kstmc.com/gosha/internal/ast"
+)
+
+var (
+	ErrorStringType  = &ast.NamedDataType{TypeName: "*errors.errorString"}
+	WrapErrorType    = &ast.NamedDataType{TypeName: "*fmt.wrapError"}
+	CommandErrorType = &ast.NamedDataType{TypeName: "*exec.ExitError"}
+)
+
+// ErrorValue is a value of the error type created by errors.New or fmt.Errorf.
+// Unlike Error, it is an ordinary value that does not abort the program.
+type ErrorValue struct {
+	Message string
+	Wrapped Object
+}
+
+func (ev *ErrorValue) Type() ast.DataType {
+	if ev.Wrapped != nil {
+		return WrapErrorType
+	}
+
+	return ErrorStringType
+}
+
+func (ev *ErrorValue) Inspect() string {
+	return ev.Message
+}
+
+// CommandError is returned by a shell command that exited with a nonzero status.
+type CommandError struct {
+	ExitCode int
+	Stderr   string
+}
+
+func (ce *CommandError) Type() ast.DataType {
+	return CommandErrorType
+}
+
+func (ce *CommandError) Inspect() string {
+	if ce.Stderr == "" {
+		return "exit status " + strconv.Itoa(ce.ExitCode)
+	}
+
+	return "exit status " + strconv.Itoa(ce.ExitCode) + ": " + ce.Stderr
+}
+
+func errorMethod(receiver Object, args ...Object) Object {
+	return &String{Value: receiver.Inspect()}
+}
+
+func unwrapMethod(receiver Object, args ...Object) Object {
+	return unwrapError(receiver)
+}
+
+func exitCodeMethod(receiver Object, args ...Object) Object {
+	return &Integer{Value: int64(receiver.(*CommandError).ExitCode)}
+}
+
+func stderrMethod(receiver Object, args ...Object) Object {
+	return &String{Value: receiver.(*CommandError).Stderr}
+}
+
+// unwrapError returns the error wrapped by err, or a nil error.
+func unwrapError(err Object) Object {
+	errValue, ok := Unwrap(err).(*ErrorValue)
+	if !ok || errValue.Wrapped == nil {
+		return &Nil{}
+	}
+
+	return errValue.Wrapped
+}
@@ -5,8 +5,11 @@ import (
 	"kstmc.com/gosha/internal/parser"
 )
 
+// Error is a fatal error that aborts the program unless recovered. Value holds the
+// argument of panic and is nil for runtime errors.
 type Error struct {
 	Message string
+	Value   Object
 }
 
 func (e *Error) Type() ast.DataType {
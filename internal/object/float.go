@@ -0,0 +1,40 @@
+package object
+
+import (
+	"fmt"
+	"strconv"
+
+	"kstmc.com/gosha/internal/ast"
+	"kstmc.com/gosha/internal/parser"
+)
+
+type Float struct {
+	Value float64
+	// FloatType is the type of named float values, and nil for float64.
+	FloatType ast.DataType
+}
+
+func (f *Float) Inspect() string {
+	return fmt.Sprintf("%v", f.Value)
+}
+
+func (f *Float) Type() ast.DataType {
+	if f.FloatType != nil {
+		return f.FloatType
+	}
+
+	return parser.FLOAT64
+}
+
+func (f *Float) HashKey() HashKey {
+	return HashKey{Type: f.Type().Name(), Value: strconv.FormatFloat(f.Value, 'g', -1, 64)}
+}
+
+// NewFloat returns a float of type dType.
+func NewFloat(value float64, dType ast.DataType) *Float {
+	if named, ok := ast.Unalias(dType).(*ast.NamedDataType); ok {
+		return &Float{Value: value, FloatType: named}
+	}
+
+	return &Float{Value: value}
+}
@@ -8,15 +8,21 @@ import (
 )
 
 type Function struct {
+	Receiver   *ast.Identifier
 	Name       *ast.Identifier
+	TypeParams []*ast.TypeParameterDataType
+	// TypeArgs are the type arguments a generic function is called with.
+	TypeArgs   []ast.DataType
 	Parameters []*ast.Identifier
 	ReturnType ast.DataType
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Variadic   bool
 }
 
 func (f *Function) Type() ast.DataType {
 	return &ast.FunctionDataType{
+		TypeParams: f.TypeParams,
 		Parameters: func() []ast.DataType {
 			var params []ast.DataType
 			for _, param := range f.Parameters {
@@ -26,9 +32,56 @@ func (f *Function) Type() ast.DataType {
 			return params
 		}(),
 		ReturnType: f.ReturnType,
+		Variadic:   f.Variadic,
 	}
 }
 
+// MethodType returns the type of the method f called on a value of receiverType.
+// Methods of generic types take the type arguments of the receiver.
+func (f *Function) MethodType(receiverType ast.DataType) ast.DataType {
+	params := ReceiverTypeParameters(f)
+	if refType, ok := receiverType.(*ast.ReferenceDataType); ok {
+		receiverType = refType.ValueType
+	}
+
+	named, ok := ast.Unalias(receiverType).(*ast.NamedDataType)
+	if len(params) == 0 || !ok || len(named.TypeArgs) != len(params) {
+		return f.Type()
+	}
+
+	return ast.Substitute(f.Type(), params, named.TypeArgs)
+}
+
+// ReceiverTypeParameters returns the type parameters of a method of a generic type,
+// which are given as the type arguments of its receiver type, as T in (s *Stack[T]).
+func ReceiverTypeParameters(method *Function) []*ast.TypeParameterDataType {
+	if method.Receiver == nil {
+		return nil
+	}
+
+	receiverType := *method.Receiver.DataType
+	if refType, ok := receiverType.(*ast.ReferenceDataType); ok {
+		receiverType = refType.ValueType
+	}
+
+	named, ok := receiverType.(*ast.NamedDataType)
+	if !ok {
+		return nil
+	}
+
+	var params []*ast.TypeParameterDataType
+	for _, arg := range named.TypeArgs {
+		param, ok := ast.Unalias(arg).(*ast.TypeParameterDataType)
+		if !ok {
+			return nil
+		}
+
+		params = append(params, param)
+	}
+
+	return params
+}
+
 func (f *Function) Inspect() string {
 	var out bytes.Buffer
 
@@ -38,10 +91,15 @@ func (f *Function) Inspect() string {
 	}
 
 	out.WriteString("func")
+	if f.Receiver != nil {
+		out.WriteString(" (" + f.Receiver.Value + " " + (*f.Receiver.DataType).Name() + ")")
+	}
+
 	if f.Name != nil {
 		out.WriteString(" " + f.Name.Value)
 	}
 
+	out.WriteString(ast.TypeParametersString(f.TypeParams))
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
@@ -52,3 +110,16 @@ func (f *Function) Inspect() string {
 
 	return out.String()
 }
+
+// NilFunction is the zero value of a function type.
+type NilFunction struct {
+	FunctionType *ast.FunctionDataType
+}
+
+func (nf *NilFunction) Type() ast.DataType {
+	return nf.FunctionType
+}
+
+func (nf *NilFunction) Inspect() string {
+	return "<nil>"
+}
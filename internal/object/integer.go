@@ -2,6 +2,7 @@ package object
 
 import (
 	"fmt"
+	"strconv"
 
 	"kstmc.com/gosha/internal/ast"
 	"kstmc.com/gosha/internal/parser"
@@ -9,6 +10,8 @@ import (
 
 type Integer struct {
 	Value int64
+	// IntegerType is the type of byte, rune and named integer values, and nil for int.
+	IntegerType ast.DataType
 }
 
 func (i *Integer) Inspect() string {
@@ -16,5 +19,31 @@ func (i *Integer) Inspect() string {
 }
 
 func (i *Integer) Type() ast.DataType {
+	if i.IntegerType != nil {
+		return i.IntegerType
+	}
+
 	return parser.INT
 }
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type().Name(), Value: strconv.FormatInt(i.Value, 10)}
+}
+
+// NewInteger returns an integer of type dType. Values of the sized types byte and rune
+// wrap around like in Go.
+func NewInteger(value int64, dType ast.DataType) *Integer {
+	dType = ast.Unalias(dType)
+	switch ast.Underlying(dType).(type) {
+	case *ast.ByteDataType:
+		return &Integer{Value: int64(uint8(value)), IntegerType: dType}
+	case *ast.RuneDataType:
+		return &Integer{Value: int64(int32(value)), IntegerType: dType}
+	}
+
+	if _, ok := dType.(*ast.NamedDataType); ok {
+		return &Integer{Value: value, IntegerType: dType}
+	}
+
+	return &Integer{Value: value}
+}
@@ -0,0 +1,187 @@
+package object
+
+import (
+	"kstmc.com/gosha/internal/ast"
+)
+
+// Interface holds a value stored in a variable of interface type together with
+// that interface type. Value is nil for nil interfaces.
+type Interface struct {
+	InterfaceType ast.DataType
+	Value         Object
+}
+
+func (i *Interface) Inspect() string {
+	if i.Value == nil {
+		return "<nil>"
+	}
+
+	return i.Value.Inspect()
+}
+
+func (i *Interface) Type() ast.DataType {
+	return i.InterfaceType
+}
+
+// HashKey is the key of the dynamic value, which must be hashable, since interfaces
+// are equal if their dynamic types and values are.
+func (i *Interface) HashKey() HashKey {
+	if i.Value == nil {
+		return HashKey{Type: "nil"}
+	}
+
+	return i.Value.(Hashable).HashKey()
+}
+
+func IsInterfaceType(dType ast.DataType) bool {
+	switch ast.Underlying(dType).(type) {
+	case *ast.AnyDataType, *ast.InterfaceDataType:
+		return true
+	default:
+		return false
+	}
+}
+
+// Unwrap returns the dynamic value stored in an interface, or obj itself if it is not an interface.
+func Unwrap(obj Object) Object {
+	iface, ok := obj.(*Interface)
+	if !ok {
+		return obj
+	}
+
+	if iface.Value == nil {
+		return &Nil{}
+	}
+
+	return iface.Value
+}
+
+// Convert returns obj in the form it is stored in a variable of type dType:
+// wrapped into an interface for interface types and unwrapped otherwise.
+func Convert(obj Object, dType ast.DataType) Object {
+	if dType == nil {
+		return obj
+	}
+
+	if tupleType, ok := dType.(*ast.TupleDataType); ok {
+		tuple, ok := obj.(*Tuple)
+		if !ok || len(tuple.Values) != len(tupleType.Types) {
+			return obj
+		}
+
+		values := make([]Object, len(tuple.Values))
+		for i, value := range tuple.Values {
+			values[i] = Convert(value, tupleType.Types[i])
+		}
+
+		return &Tuple{Values: values}
+	}
+
+	obj = Unwrap(obj)
+	if !IsInterfaceType(dType) {
+		if _, ok := obj.(*Nil); ok {
+			switch underlying := ast.Underlying(dType).(type) {
+			case *ast.ReferenceDataType:
+				return &ReferenceObject{ValueType: underlying.ValueType}
+			case *ast.SliceDataType:
+				return &SliceObject{ValueType: underlying.Type, SliceType: namedType(dType)}
+			case *ast.MapDataType:
+				return &MapObject{KeyType: underlying.KeyType, ValueType: underlying.ValueType, MapType: namedType(dType)}
+			case *ast.ChanDataType:
+				return &ChanObject{ChanType: underlying.ValueType}
+			}
+		}
+
+		// Constants take the numeric type they are assigned to, and values of a named
+		// type the name they are stored under.
+		switch value := obj.(type) {
+		case *Integer:
+			switch ast.Underlying(dType).(type) {
+			case *ast.FloatDataType:
+				return NewFloat(float64(value.Value), dType)
+			case *ast.IntegerDataType, *ast.ByteDataType, *ast.RuneDataType:
+				return NewInteger(value.Value, dType)
+			}
+		case *Float:
+			if _, ok := ast.Underlying(dType).(*ast.FloatDataType); ok {
+				return NewFloat(value.Value, dType)
+			}
+		case *String:
+			if _, ok := ast.Underlying(dType).(*ast.StringDataType); ok {
+				return NewString(value.Value, dType)
+			}
+		case *SliceObject:
+			if _, ok := ast.Underlying(dType).(*ast.SliceDataType); ok && !ast.Identical(value.Type(), dType) {
+				return &SliceObject{Values: value.Values, ValueType: value.ValueType, SliceType: namedType(dType)}
+			}
+		case *MapObject:
+			if _, ok := ast.Underlying(dType).(*ast.MapDataType); ok && !ast.Identical(value.Type(), dType) {
+				return &MapObject{Pairs: value.Pairs, KeyType: value.KeyType, ValueType: value.ValueType, MapType: namedType(dType)}
+			}
+		}
+
+		return obj
+	}
+
+	if _, ok := obj.(*Nil); ok {
+		return &Interface{InterfaceType: dType}
+	}
+
+	return &Interface{InterfaceType: dType, Value: obj}
+}
+
+// namedType returns dType if it is a named type, and nil otherwise.
+func namedType(dType ast.DataType) ast.DataType {
+	if named, ok := ast.Unalias(dType).(*ast.NamedDataType); ok {
+		return named
+	}
+
+	return nil
+}
+
+// MissingMethod returns the name of the first method of iface that is not in the
+// method set of dType. Methods with pointer receivers belong only to pointer types.
+func MissingMethod(dType ast.DataType, iface *ast.InterfaceDataType, env *Environment) (string, bool) {
+	if other, ok := ast.Underlying(dType).(*ast.InterfaceDataType); ok {
+		for _, method := range iface.Methods {
+			otherMethod, ok := other.Method(method.Name)
+			if !ok || otherMethod.Type.Name() != method.Type.Name() {
+				return method.Name, true
+			}
+		}
+
+		return "", false
+	}
+
+	isPointer := false
+	if refType, ok := dType.(*ast.ReferenceDataType); ok {
+		dType = refType.ValueType
+		isPointer = true
+	}
+
+	for _, method := range iface.Methods {
+		if builtinMethod, ok := BuiltinMethods[dType.Name()][method.Name]; ok {
+			if builtinMethod.Type.Name() != method.Type.Name() {
+				return method.Name, true
+			}
+
+			continue
+		}
+
+		obj, ok := env.LookupMethod(dType, method.Name)
+		if !ok {
+			return method.Name, true
+		}
+
+		fn, ok := obj.(*Function)
+		if !ok || fn.MethodType(dType).Name() != method.Type.Name() {
+			return method.Name, true
+		}
+
+		if _, ok := (*fn.Receiver.DataType).(*ast.ReferenceDataType); ok && !isPointer {
+			return method.Name, true
+		}
+	}
+
+	return "", false
+}
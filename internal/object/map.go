@@ -0,0 +1,104 @@
+package object
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"kstmc.com/gosha/internal/ast"
+)
+
+type HashKey struct {
+	Type  string
+	Value string
+}
+
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+// HashableKey returns obj as a map key. It reports false if obj is not hashable, or
+// is an interface or struct holding a value that is not, like a slice.
+func HashableKey(obj Object) (Hashable, bool) {
+	switch obj := obj.(type) {
+	case *Interface:
+		if obj.Value != nil {
+			if _, ok := HashableKey(obj.Value); !ok {
+				return nil, false
+			}
+		}
+	case *StructObject:
+		for _, cell := range obj.Fields {
+			if _, ok := HashableKey(*cell); !ok {
+				return nil, false
+			}
+		}
+	}
+
+	hashable, ok := obj.(Hashable)
+	return hashable, ok
+}
+
+type MapPair struct {
+	Key   Object
+	Value Object
+}
+
+// MapObject is a map value. Pairs is nil for nil maps.
+type MapObject struct {
+	Pairs     map[HashKey]MapPair
+	KeyType   ast.DataType
+	ValueType ast.DataType
+	// MapType is the type of named map values, and nil for unnamed maps.
+	MapType ast.DataType
+}
+
+func (mo *MapObject) Type() ast.DataType {
+	if mo.MapType != nil {
+		return mo.MapType
+	}
+
+	return &ast.MapDataType{KeyType: mo.KeyType, ValueType: mo.ValueType}
+}
+
+func (mo *MapObject) Inspect() string {
+	var out bytes.Buffer
+
+	var values []string
+	for _, pair := range mo.SortedPairs() {
+		values = append(values, pair.Key.Inspect()+":"+pair.Value.Inspect())
+	}
+
+	out.WriteString("map[")
+	out.WriteString(strings.Join(values, " "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// SortedPairs returns the pairs of the map ordered by key.
+func (mo *MapObject) SortedPairs() []MapPair {
+	pairs := make([]MapPair, 0, len(mo.Pairs))
+	for _, pair := range mo.Pairs {
+		pairs = append(pairs, pair)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		left, lok := pairs[i].Key.(*Integer)
+		right, rok := pairs[j].Key.(*Integer)
+		if lok && rok {
+			return left.Value < right.Value
+		}
+
+		leftFloat, lok := pairs[i].Key.(*Float)
+		rightFloat, rok := pairs[j].Key.(*Float)
+		if lok && rok {
+			return leftFloat.Value < rightFloat.Value
+		}
+
+		return pairs[i].Key.Inspect() < pairs[j].Key.Inspect()
+	})
+
+	return pairs
+}
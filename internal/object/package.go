@@ -0,0 +1,370 @@
+package object
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"kstmc.com/gosha/internal/ast"
+	"kstmc.com/gosha/internal/parser"
+)
+
+// Package is a predeclared package of builtins and constants, e.g. errors, or a
+// package imported from gosha files. The members of an imported package are the
+// exported identifiers declared in its environment Env.
+type Package struct {
+	Name    string
+	Members map[string]Object
+	Env     *Environment
+}
+
+// Member returns the member name of the package.
+func (p *Package) Member(name string) (Object, bool) {
+	if p.Env == nil {
+		member, ok := p.Members[name]
+		return member, ok
+	}
+
+	if !ast.IsExported(name) {
+		return nil, false
+	}
+
+	return p.Env.Get(name)
+}
+
+// Ref returns the variable name of an imported package, which may be assigned.
+func (p *Package) Ref(name string) (*Object, bool) {
+	if p.Env == nil || !ast.IsExported(name) {
+		return nil, false
+	}
+
+	return p.Env.Ref(name)
+}
+
+func (p *Package) Type() ast.DataType {
+	return &ast.PackageDataType{PackageName: p.Name}
+}
+
+func (p *Package) Inspect() string {
+	return "package " + p.Name
+}
+
+var Packages = map[string]*Package{
+	"errors": {
+		Name: "errors",
+		Members: map[string]Object{
+			"New": &Builtin{
+				Name:       "errors.New",
+				ReturnType: parser.ERROR_INTERFACE,
+				Fn: func(args ...Object) Object {
+					if len(args) != 1 {
+						return &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 1, provided %d", len(args))}
+					}
+
+					text, ok := args[0].(*String)
+					if !ok {
+						return &Error{Message: fmt.Sprintf("expected string argument to errors.New, got=%s", args[0].Type().Name())}
+					}
+
+					return &ErrorValue{Message: text.Value}
+				},
+			},
+			"Is": &Builtin{
+				Name:       "errors.Is",
+				ReturnType: parser.BOOLEAN,
+				Fn: func(args ...Object) Object {
+					if len(args) != 2 {
+						return &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 2, provided %d", len(args))}
+					}
+
+					target := Unwrap(args[1])
+					for err := Unwrap(args[0]); err.Type() != parser.NIL; err = unwrapError(err) {
+						if err == target {
+							return TRUE
+						}
+					}
+
+					return NativeBoolean(target.Type() == parser.NIL && Unwrap(args[0]).Type() == parser.NIL)
+				},
+			},
+			"Unwrap": &Builtin{
+				Name:       "errors.Unwrap",
+				ReturnType: parser.ERROR_INTERFACE,
+				Fn: func(args ...Object) Object {
+					if len(args) != 1 {
+						return &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 1, provided %d", len(args))}
+					}
+
+					return unwrapError(args[0])
+				},
+			},
+		},
+	},
+	"fmt": {
+		Name: "fmt",
+		Members: map[string]Object{
+			"Errorf": &Builtin{
+				Name:       "fmt.Errorf",
+				ReturnType: parser.ERROR_INTERFACE,
+				Fn: func(args ...Object) Object {
+					if len(args) < 1 {
+						return &Error{Message: "unexpected amount of arguments. expected at least 1, provided 0"}
+					}
+
+					format, ok := args[0].(*String)
+					if !ok {
+						return &Error{Message: fmt.Sprintf("expected string format argument to fmt.Errorf, got=%s", args[0].Type().Name())}
+					}
+
+					errValue := &ErrorValue{Message: Sprintf(format.Value, args[1:]...)}
+					if i := wrappedArgIndex(format.Value); 0 <= i && i < len(args)-1 {
+						if wrapped := Unwrap(args[i+1]); wrapped.Type() != parser.NIL {
+							errValue.Wrapped = wrapped
+						}
+					}
+
+					return errValue
+				},
+			},
+			"Sprintf": &Builtin{
+				Name:       "fmt.Sprintf",
+				ReturnType: parser.STRING,
+				Fn: func(args ...Object) Object {
+					if len(args) < 1 {
+						return &Error{Message: "unexpected amount of arguments. expected at least 1, provided 0"}
+					}
+
+					format, ok := args[0].(*String)
+					if !ok {
+						return &Error{Message: fmt.Sprintf("expected string format argument to fmt.Sprintf, got=%s", args[0].Type().Name())}
+					}
+
+					return &String{Value: Sprintf(format.Value, args[1:]...)}
+				},
+			},
+		},
+	},
+	"strconv": {
+		Name: "strconv",
+		Members: map[string]Object{
+			"Atoi": &Builtin{
+				Name:       "strconv.Atoi",
+				Parameters: []ast.DataType{parser.STRING},
+				ReturnType: &ast.TupleDataType{Types: []ast.DataType{parser.INT, parser.ERROR_INTERFACE}},
+				Fn: func(args ...Object) Object {
+					s, errObj := stringArg("strconv.Atoi", args)
+					if errObj != nil {
+						return errObj
+					}
+
+					value, err := strconv.Atoi(s)
+					return &Tuple{Values: []Object{&Integer{Value: int64(value)}, errorValue(err)}}
+				},
+			},
+			"Itoa": &Builtin{
+				Name:       "strconv.Itoa",
+				Parameters: []ast.DataType{parser.INT},
+				ReturnType: parser.STRING,
+				Fn: func(args ...Object) Object {
+					if len(args) != 1 {
+						return &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 1, provided %d", len(args))}
+					}
+
+					i, ok := Unwrap(args[0]).(*Integer)
+					if !ok {
+						return &Error{Message: fmt.Sprintf("expected integer argument to strconv.Itoa, got=%s", args[0].Type().Name())}
+					}
+
+					return &String{Value: strconv.FormatInt(i.Value, 10)}
+				},
+			},
+			"ParseBool": &Builtin{
+				Name:       "strconv.ParseBool",
+				Parameters: []ast.DataType{parser.STRING},
+				ReturnType: &ast.TupleDataType{Types: []ast.DataType{parser.BOOLEAN, parser.ERROR_INTERFACE}},
+				Fn: func(args ...Object) Object {
+					s, errObj := stringArg("strconv.ParseBool", args)
+					if errObj != nil {
+						return errObj
+					}
+
+					value, err := strconv.ParseBool(s)
+					return &Tuple{Values: []Object{NativeBoolean(value), errorValue(err)}}
+				},
+			},
+			"FormatBool": &Builtin{
+				Name:       "strconv.FormatBool",
+				Parameters: []ast.DataType{parser.BOOLEAN},
+				ReturnType: parser.STRING,
+				Fn: func(args ...Object) Object {
+					if len(args) != 1 {
+						return &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 1, provided %d", len(args))}
+					}
+
+					b, ok := Unwrap(args[0]).(*Boolean)
+					if !ok {
+						return &Error{Message: fmt.Sprintf("expected boolean argument to strconv.FormatBool, got=%s", args[0].Type().Name())}
+					}
+
+					return &String{Value: strconv.FormatBool(b.Value)}
+				},
+			},
+			"ParseFloat": &Builtin{
+				Name:       "strconv.ParseFloat",
+				Parameters: []ast.DataType{parser.STRING, parser.INT},
+				ReturnType: &ast.TupleDataType{Types: []ast.DataType{parser.FLOAT64, parser.ERROR_INTERFACE}},
+				Fn: func(args ...Object) Object {
+					if len(args) != 2 {
+						return &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 2, provided %d", len(args))}
+					}
+
+					s, errObj := stringArg("strconv.ParseFloat", args[:1])
+					if errObj != nil {
+						return errObj
+					}
+
+					bitSize, ok := Unwrap(args[1]).(*Integer)
+					if !ok {
+						return &Error{Message: fmt.Sprintf("expected integer bit size argument to strconv.ParseFloat, got=%s", args[1].Type().Name())}
+					}
+
+					value, err := strconv.ParseFloat(s, int(bitSize.Value))
+					return &Tuple{Values: []Object{&Float{Value: value}, errorValue(err)}}
+				},
+			},
+		},
+	},
+	"time": {
+		Name: "time",
+		Members: map[string]Object{
+			"Nanosecond":  &Integer{Value: int64(time.Nanosecond)},
+			"Microsecond": &Integer{Value: int64(time.Microsecond)},
+			"Millisecond": &Integer{Value: int64(time.Millisecond)},
+			"Second":      &Integer{Value: int64(time.Second)},
+			"Minute":      &Integer{Value: int64(time.Minute)},
+			"Hour":        &Integer{Value: int64(time.Hour)},
+			"After": &Builtin{
+				Name:       "time.After",
+				ReturnType: &ast.ChanDataType{ValueType: parser.INT},
+				Fn: func(args ...Object) Object {
+					d, errObj := durationArg("time.After", args)
+					if errObj != nil {
+						return errObj
+					}
+
+					// the channel receives the current time in Unix nanoseconds
+					ch := make(chan Object, 1)
+					time.AfterFunc(d, func() {
+						ch <- &Integer{Value: time.Now().UnixNano()}
+					})
+
+					return &ChanObject{Chan: ch, ChanType: parser.INT}
+				},
+			},
+			"Sleep": &Builtin{
+				Name:       "time.Sleep",
+				ReturnType: parser.NIL,
+				Fn: func(args ...Object) Object {
+					d, errObj := durationArg("time.Sleep", args)
+					if errObj != nil {
+						return errObj
+					}
+
+					time.Sleep(d)
+					return &Nil{}
+				},
+			},
+		},
+	},
+}
+
+// durationArg returns the single duration argument of a time function. Durations
+// are integers counting nanoseconds, as in Go.
+func durationArg(name string, args []Object) (time.Duration, Object) {
+	if len(args) != 1 {
+		return 0, &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 1, provided %d", len(args))}
+	}
+
+	d, ok := Unwrap(args[0]).(*Integer)
+	if !ok {
+		return 0, &Error{Message: fmt.Sprintf("expected integer duration argument to %s, got=%s", name, args[0].Type().Name())}
+	}
+
+	return time.Duration(d.Value), nil
+}
+
+// stringArg returns the single string argument of a function.
+func stringArg(name string, args []Object) (string, Object) {
+	if len(args) != 1 {
+		return "", &Error{Message: fmt.Sprintf("unexpected amount of arguments. expected 1, provided %d", len(args))}
+	}
+
+	s, ok := Unwrap(args[0]).(*String)
+	if !ok {
+		return "", &Error{Message: fmt.Sprintf("expected string argument to %s, got=%s", name, args[0].Type().Name())}
+	}
+
+	return s.Value, nil
+}
+
+// errorValue converts a Go error into an error value, or nil if there is no error.
+func errorValue(err error) Object {
+	if err == nil {
+		return &Nil{}
+	}
+
+	return &ErrorValue{Message: err.Error()}
+}
+
+// Sprintf formats objects according to a Go format string. The %w verb formats
+// like %v.
+func Sprintf(format string, args ...Object) string {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		switch arg := Unwrap(arg).(type) {
+		case *Integer:
+			values[i] = arg.Value
+		case *Float:
+			values[i] = arg.Value
+		case *String:
+			values[i] = arg.Value
+		case *Boolean:
+			values[i] = arg.Value
+		case *Nil:
+			values[i] = nil
+		default:
+			values[i] = arg.Inspect()
+		}
+	}
+
+	return fmt.Sprintf(strings.ReplaceAll(format, "%w", "%v"), values...)
+}
+
+// wrappedArgIndex returns the index of the argument formatted with the first %w verb,
+// or -1 if there is no such verb.
+func wrappedArgIndex(format string) int {
+	arg := 0
+	for i := 0; i < len(format)-1; i++ {
+		if format[i] != '%' {
+			continue
+		}
+
+		i++
+		for i < len(format) && strings.ContainsRune("+-# 0123456789.", rune(format[i])) {
+			i++
+		}
+
+		if i == len(format) || format[i] == '%' {
+			continue
+		}
+
+		if format[i] == 'w' {
+			return arg
+		}
+
+		arg++
+	}
+
+	return -1
+}
@@ -1,17 +1,38 @@
 package object
 
-import "kstmc.com/gosha/internal/ast"
+import (
+	"fmt"
 
+	"kstmc.com/gosha/internal/ast"
+)
+
+// ReferenceObject is a pointer to the cell of a variable or slice element. Value is
+// nil for a nil pointer, whose target type is ValueType.
 type ReferenceObject struct {
-	Value *Object
+	Value     *Object
+	ValueType ast.DataType
 }
 
 func (ro *ReferenceObject) Inspect() string {
+	if ro.Value == nil {
+		return "nil"
+	}
+
 	return "&" + (*ro.Value).Inspect()
 }
 
 func (ro *ReferenceObject) Type() ast.DataType {
+	if ro.Value == nil {
+		return &ast.ReferenceDataType{ValueType: ro.ValueType}
+	}
+
 	return &ast.ReferenceDataType{
 		ValueType: (*ro.Value).Type(),
 	}
 }
+
+// HashKey is the address of the cell, since pointers are equal if they point to the
+// same cell.
+func (ro *ReferenceObject) HashKey() HashKey {
+	return HashKey{Type: ro.Type().Name(), Value: fmt.Sprintf("%p", ro.Value)}
+}
@@ -7,12 +7,19 @@ import (
 	"kstmc.com/gosha/internal/ast"
 )
 
+// SliceObject is a slice value. Values is nil for nil slices.
 type SliceObject struct {
 	Values    []Object
 	ValueType ast.DataType
+	// SliceType is the type of named slice values, and nil for unnamed slices.
+	SliceType ast.DataType
 }
 
 func (so *SliceObject) Type() ast.DataType {
+	if so.SliceType != nil {
+		return so.SliceType
+	}
+
 	return &ast.SliceDataType{Type: so.ValueType}
 }
 
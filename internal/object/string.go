@@ -7,6 +7,8 @@ import (
 
 type String struct {
 	Value string
+	// StringType is the type of named string values, and nil for string.
+	StringType ast.DataType
 }
 
 func (s *String) Inspect() string {
@@ -14,5 +16,22 @@ func (s *String) Inspect() string {
 }
 
 func (s *String) Type() ast.DataType {
+	if s.StringType != nil {
+		return s.StringType
+	}
+
 	return parser.STRING
 }
+
+func (s *String) HashKey() HashKey {
+	return HashKey{Type: s.Type().Name(), Value: s.Value}
+}
+
+// NewString returns a string of type dType.
+func NewString(value string, dType ast.DataType) *String {
+	if named, ok := ast.Unalias(dType).(*ast.NamedDataType); ok {
+		return &String{Value: value, StringType: named}
+	}
+
+	return &String{Value: value}
+}
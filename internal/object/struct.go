@@ -0,0 +1,85 @@
+package object
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"kstmc.com/gosha/internal/ast"
+)
+
+type StructObject struct {
+	StructType ast.DataType
+	// Fields holds each field in its own cell, which pointers to the field share.
+	Fields map[string]*Object
+}
+
+// Field returns the value of the field name.
+func (so *StructObject) Field(name string) (Object, bool) {
+	cell, ok := so.Fields[name]
+	if !ok {
+		return nil, false
+	}
+
+	return *cell, true
+}
+
+// SetField stores value in the cell of the field name.
+func (so *StructObject) SetField(name string, value Object) {
+	if cell, ok := so.Fields[name]; ok {
+		*cell = value
+		return
+	}
+
+	so.Fields[name] = &value
+}
+
+func (so *StructObject) Type() ast.DataType {
+	return so.StructType
+}
+
+func (so *StructObject) Inspect() string {
+	var out bytes.Buffer
+
+	var values []string
+	for _, field := range so.structDataType().Fields {
+		values = append(values, (*so.Fields[field.Name]).Inspect())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(values, " "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// Copy returns a copy of the struct, since structs are assigned by value.
+func (so *StructObject) Copy() *StructObject {
+	fields := make(map[string]*Object, len(so.Fields))
+	for name, cell := range so.Fields {
+		value := *cell
+		if structObj, ok := value.(*StructObject); ok {
+			value = structObj.Copy()
+		}
+
+		fields[name] = &value
+	}
+
+	return &StructObject{StructType: so.StructType, Fields: fields}
+}
+
+// HashKey combines the keys of the fields, since structs are equal if their fields are.
+// The fields must be hashable.
+func (so *StructObject) HashKey() HashKey {
+	var values []string
+	for _, field := range so.structDataType().Fields {
+		key := (*so.Fields[field.Name]).(Hashable).HashKey()
+		values = append(values, strconv.Quote(key.Type)+":"+strconv.Quote(key.Value))
+	}
+
+	return HashKey{Type: so.Type().Name(), Value: strings.Join(values, " ")}
+}
+
+func (so *StructObject) structDataType() *ast.StructDataType {
+	return ast.Underlying(so.StructType).(*ast.StructDataType)
+}
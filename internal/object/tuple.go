@@ -0,0 +1,30 @@
+package object
+
+import (
+	"strings"
+
+	"kstmc.com/gosha/internal/ast"
+)
+
+// Tuple holds the results of a function with multiple return values.
+type Tuple struct {
+	Values []Object
+}
+
+func (t *Tuple) Type() ast.DataType {
+	tupleType := &ast.TupleDataType{}
+	for _, value := range t.Values {
+		tupleType.Types = append(tupleType.Types, value.Type())
+	}
+
+	return tupleType
+}
+
+func (t *Tuple) Inspect() string {
+	var values []string
+	for _, value := range t.Values {
+		values = append(values, value.Inspect())
+	}
+
+	return strings.Join(values, " ")
+}
@@ -5,8 +5,10 @@ import (
 	"kstmc.com/gosha/internal/token"
 )
 
+// Errors returns the errors of malformed literals found by the lexer followed by
+// the parse errors.
 func (p *Parser) Errors() []string {
-	return p.errors
+	return append(append([]string{}, p.l.Errors()...), p.errors...)
 }
 
 func (p *Parser) peekError(t token.TokenType) {
@@ -3,8 +3,11 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"kstmc.com/gosha/internal/ast"
+	"kstmc.com/gosha/internal/lexer"
 	"kstmc.com/gosha/internal/token"
 )
 
@@ -22,6 +25,33 @@ func (p *Parser) parseDataTypeLiteral() ast.DataType {
 		return p.parseFunctionDataType()
 	case token.LBRACKET:
 		return p.parseSliceDataType()
+	case token.MAP:
+		return p.parseMapDataType()
+	case token.STRUCT:
+		return p.parseStructDataType()
+	case token.INTERFACE:
+		return p.parseInterfaceDataType()
+	case token.IDENT:
+		named := &ast.NamedDataType{TypeName: p.curToken.Literal}
+		if p.peekTokenIs(token.DOT) {
+			// deploy.Config refers to the type Config of the package deploy.
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+
+			named.Package, named.TypeName = named.TypeName, p.curToken.Literal
+		}
+
+		if p.peekTokenIs(token.LBRACKET) {
+			p.nextToken()
+			named.TypeArgs = p.parseTypeArguments()
+			if named.TypeArgs == nil {
+				return nil
+			}
+		}
+
+		return named
 	default:
 		msg := fmt.Sprintf("unknown data type that starts with %s token type", p.curToken.Type)
 		p.errors = append(p.errors, msg)
@@ -29,6 +59,121 @@ func (p *Parser) parseDataTypeLiteral() ast.DataType {
 	}
 }
 
+// parseTypeArguments parses the type arguments [int, string] of an instance of a
+// generic type. Current token must be '['.
+func (p *Parser) parseTypeArguments() []ast.DataType {
+	var args []ast.DataType
+	for {
+		p.nextToken()
+		arg := p.parseDataTypeLiteral()
+		if arg == nil {
+			return nil
+		}
+
+		args = append(args, arg)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return args
+}
+
+// parseTypeParameters parses the type parameters [K comparable, V any] of a generic
+// function or type. Parameters listed together share a constraint, as in [T, U any].
+// Current token must be '['.
+func (p *Parser) parseTypeParameters() []*ast.TypeParameterDataType {
+	var params, group []*ast.TypeParameterDataType
+	for {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		param := &ast.TypeParameterDataType{TypeName: p.curToken.Literal}
+		params = append(params, param)
+		group = append(group, param)
+		if p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			continue
+		}
+
+		if p.peekTokenIs(token.RBRACKET) {
+			msg := fmt.Sprintf("missing type constraint for %s", param.TypeName)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+
+		p.nextToken()
+		constraint := p.parseConstraint()
+		if constraint == nil {
+			return nil
+		}
+
+		for _, param := range group {
+			param.Constraint = constraint
+		}
+
+		group = nil
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return params
+}
+
+// parseConstraint parses the constraint of a type parameter. A union of type terms
+// such as ~int | ~string stands for an interface with that type set.
+func (p *Parser) parseConstraint() ast.DataType {
+	terms := p.parseTypeTerms()
+	if terms == nil {
+		return nil
+	}
+
+	if len(terms) == 1 && !terms[0].Tilde {
+		return terms[0].Type
+	}
+
+	return &ast.InterfaceDataType{Union: terms}
+}
+
+// parseTypeTerms parses a union of type terms T | ~U.
+func (p *Parser) parseTypeTerms() []*ast.TypeTerm {
+	var terms []*ast.TypeTerm
+	for {
+		term := &ast.TypeTerm{}
+		if p.curTokenIs(token.TILDE) {
+			term.Tilde = true
+			p.nextToken()
+		}
+
+		term.Type = p.parseDataTypeLiteral()
+		if term.Type == nil {
+			return nil
+		}
+
+		terms = append(terms, term)
+		if !p.peekTokenIs(token.PIPE) {
+			return terms
+		}
+
+		p.nextToken()
+		p.nextToken()
+	}
+}
+
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	lit := &ast.FunctionLiteral{
 		Token: p.curToken,
@@ -37,29 +182,123 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	if p.peekTokenIs(token.IDENT) {
 		p.nextToken()
 		lit.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+		if p.peekTokenIs(token.LBRACKET) {
+			p.nextToken()
+			lit.TypeParams = p.parseTypeParameters()
+			if lit.TypeParams == nil {
+				return nil
+			}
+		}
 	}
 
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	lit.Parameters, lit.Variadic = p.parseFunctionParameters()
 	lit.ReturnType = NIL
 
 	if !p.peekTokenIs(token.LBRACE) {
 		p.nextToken()
-		lit.ReturnType = p.parseDataTypeLiteral()
+
+		// func (r T) name(...) declares a method with receiver r.
+		if lit.Name == nil && p.curTokenIs(token.IDENT) && p.peekTokenIs(token.LPAREN) {
+			if len(lit.Parameters) != 1 {
+				p.errors = append(p.errors, "method has multiple receivers")
+				return nil
+			}
+
+			if lit.Variadic {
+				p.errors = append(p.errors, "can not use ... with receiver")
+				return nil
+			}
+
+			lit.Receiver = lit.Parameters[0]
+			lit.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+			p.nextToken()
+			lit.Parameters, lit.Variadic = p.parseFunctionParameters()
+
+			if p.peekTokenIs(token.LBRACE) {
+				return p.parseFunctionBody(lit)
+			}
+
+			p.nextToken()
+		}
+
+		lit.ReturnType = p.parseReturnDataType()
 	}
 
+	return p.parseFunctionBody(lit)
+}
+
+func (p *Parser) parseFunctionBody(lit *ast.FunctionLiteral) ast.Expression {
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
 
+	// break and continue cannot leave the function body.
+	branchTargets := p.branchTargets
+	p.branchTargets = nil
 	lit.Body = p.parseBlockStatement()
+	p.branchTargets = branchTargets
 
 	return lit
 }
 
+func (p *Parser) parseStructLiteral() ast.Expression {
+	tok := p.curToken
+
+	dType := p.parseDataTypeLiteral()
+	if dType == nil {
+		return nil
+	}
+
+	if !p.peekTokenIs(token.LBRACE) {
+		return &ast.DataTypeExpression{Token: tok, Type: dType}
+	}
+
+	p.nextToken()
+
+	return p.parseStructLiteralValues(tok, dType)
+}
+
+func (p *Parser) parseStructLiteralValues(tok token.Token, dType ast.DataType) ast.Expression {
+	lit := &ast.StructLiteral{
+		Token: tok,
+		Type:  dType,
+	}
+
+	lit.Values = p.parseCompositeElements(func() ast.Expression {
+		value := p.parseExpression(LOWEST)
+		if !p.peekTokenIs(token.COLON) {
+			return value
+		}
+
+		pair := &ast.KeyValueExpression{Key: value}
+		p.nextToken()
+		pair.Token = p.curToken
+		p.nextToken()
+		pair.Value = p.parseExpression(LOWEST)
+		if pair.Value == nil {
+			return nil
+		}
+
+		return pair
+	})
+
+	if lit.Values == nil {
+		return nil
+	}
+
+	return lit
+}
+
+func (p *Parser) parseNilLiteral() ast.Expression {
+	return &ast.NilLiteral{Token: p.curToken}
+}
+
 func (p *Parser) parseBooleanLiteral() ast.Expression {
 	expression := &ast.Boolean{
 		Token: p.curToken,
@@ -84,6 +323,26 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return lit
 }
 
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	lit.Value = value
+
+	return lit
+}
+
+func (p *Parser) parseRuneLiteral() ast.Expression {
+	value, _ := utf8.DecodeRuneInString(p.curToken.Literal)
+	return &ast.RuneLiteral{Token: p.curToken, Value: value}
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	expression := &ast.StringLiteral{
 		Token: p.curToken,
@@ -92,3 +351,193 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 
 	return expression
 }
+
+// parseInterpolatedString parses f"...{expr}..." into its text segments and the
+// expressions embedded between them.
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	lit := &ast.InterpolatedString{Token: p.curToken}
+	texts, sources, ok := lexer.SplitInterpolated(p.curToken.Literal)
+	if !ok {
+		return nil
+	}
+
+	for i, text := range texts {
+		if text != "" {
+			lit.Parts = append(lit.Parts, &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: text}, Value: text})
+		}
+
+		if i == len(sources) {
+			break
+		}
+
+		expr := p.parseEmbeddedExpression(sources[i])
+		if expr == nil {
+			return nil
+		}
+
+		lit.Parts = append(lit.Parts, expr)
+	}
+
+	return lit
+}
+
+// parseEmbeddedExpression parses the source of an expression embedded in an
+// interpolated string.
+func (p *Parser) parseEmbeddedExpression(source string) ast.Expression {
+	if strings.TrimSpace(source) == "" {
+		p.errors = append(p.errors, "empty expression in interpolated string")
+		return nil
+	}
+
+	embedded := New(lexer.New(source))
+	expr := embedded.parseExpression(LOWEST)
+	if errors := embedded.Errors(); len(errors) != 0 {
+		p.errors = append(p.errors, errors...)
+		return nil
+	}
+
+	if !embedded.peekTokenIs(token.EOF) {
+		msg := fmt.Sprintf("unexpected %s in interpolated string expression %q", embedded.peekToken.Literal, source)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	return expr
+}
+
+func (p *Parser) parseSliceLiteral() ast.Expression {
+	tok := p.curToken
+
+	dType, ok := p.parseDataTypeLiteral().(*ast.SliceDataType)
+	if !ok {
+		return nil
+	}
+
+	if !p.peekTokenIs(token.LBRACE) {
+		return &ast.DataTypeExpression{Token: tok, Type: dType}
+	}
+
+	p.nextToken()
+
+	return p.parseSliceLiteralValues(tok, dType)
+}
+
+func (p *Parser) parseSliceLiteralValues(tok token.Token, dType *ast.SliceDataType) ast.Expression {
+	lit := &ast.SliceLiteral{
+		Token: tok,
+		Type:  dType.Type,
+	}
+
+	lit.Values = p.parseCompositeElements(func() ast.Expression {
+		return p.parseCompositeValue(dType.Type)
+	})
+
+	if lit.Values == nil {
+		return nil
+	}
+
+	return lit
+}
+
+func (p *Parser) parseMapLiteral() ast.Expression {
+	tok := p.curToken
+
+	dType, ok := p.parseDataTypeLiteral().(*ast.MapDataType)
+	if !ok {
+		return nil
+	}
+
+	if !p.peekTokenIs(token.LBRACE) {
+		return &ast.DataTypeExpression{Token: tok, Type: dType}
+	}
+
+	p.nextToken()
+
+	return p.parseMapLiteralPairs(tok, dType)
+}
+
+func (p *Parser) parseMapLiteralPairs(tok token.Token, dType *ast.MapDataType) ast.Expression {
+	lit := &ast.MapLiteral{
+		Token: tok,
+		Type:  dType,
+	}
+
+	values := p.parseCompositeElements(func() ast.Expression {
+		pair := &ast.KeyValueExpression{}
+		pair.Key = p.parseCompositeValue(dType.KeyType)
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		pair.Token = p.curToken
+		p.nextToken()
+		pair.Value = p.parseCompositeValue(dType.ValueType)
+		if pair.Key == nil || pair.Value == nil {
+			return nil
+		}
+
+		return pair
+	})
+
+	if values == nil {
+		return nil
+	}
+
+	for _, value := range values {
+		lit.Pairs = append(lit.Pairs, value.(*ast.KeyValueExpression))
+	}
+
+	return lit
+}
+
+// parseCompositeValue parses an element of a composite literal. The type of nested
+// slice and map literals may be elided, as in [][]int{{1}, {2}}.
+func (p *Parser) parseCompositeValue(dType ast.DataType) ast.Expression {
+	if p.curTokenIs(token.LBRACE) {
+		switch dType := dType.(type) {
+		case *ast.SliceDataType:
+			return p.parseSliceLiteralValues(p.curToken, dType)
+		case *ast.MapDataType:
+			return p.parseMapLiteralPairs(p.curToken, dType)
+		case *ast.NamedDataType:
+			return p.parseStructLiteralValues(p.curToken, dType)
+		}
+	}
+
+	return p.parseExpression(LOWEST)
+}
+
+// parseCompositeElements parses a comma separated list of elements enclosed in braces.
+// Current token must be '{'. Newlines between elements are ignored.
+func (p *Parser) parseCompositeElements(parseElement func() ast.Expression) []ast.Expression {
+	values := []ast.Expression{}
+
+	p.nextToken()
+	p.skipNewLines()
+	for !p.curTokenIs(token.RBRACE) {
+		if p.curTokenIs(token.EOF) {
+			p.errors = append(p.errors, "unexpected EOF in composite literal")
+			return nil
+		}
+
+		value := parseElement()
+		if value == nil {
+			return nil
+		}
+
+		values = append(values, value)
+
+		p.nextToken()
+		p.skipNewLines()
+		if p.curTokenIs(token.COMMA) {
+			p.nextToken()
+			p.skipNewLines()
+		} else if !p.curTokenIs(token.RBRACE) {
+			msg := fmt.Sprintf("expected ',' or '}' in composite literal, got %s instead", p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+	}
+
+	return values
+}
@@ -23,15 +23,36 @@ const (
 )
 
 var (
-	NIL     = &ast.NilDataType{}
-	ANY     = &ast.AnyDataType{}
-	INT     = &ast.IntegerDataType{}
-	STRING  = &ast.StringDataType{}
-	BOOLEAN = &ast.BooleanDataType{}
-	RETURN  = &ast.ReturnDataType{}
-	ERROR   = &ast.ErrorDataType{}
-	BUILTIN = &ast.BuiltinDataType{}
-	BREAK   = &ast.BreakDataType{}
+	NIL      = &ast.NilDataType{}
+	ANY      = &ast.AnyDataType{}
+	INT      = &ast.IntegerDataType{}
+	FLOAT64  = &ast.FloatDataType{}
+	BYTE     = &ast.ByteDataType{}
+	RUNE     = &ast.RuneDataType{}
+	STRING   = &ast.StringDataType{}
+	BOOLEAN  = &ast.BooleanDataType{}
+	RETURN   = &ast.ReturnDataType{}
+	ERROR    = &ast.ErrorDataType{}
+	BUILTIN  = &ast.BuiltinDataType{}
+	BREAK    = &ast.BreakDataType{}
+	CONTINUE = &ast.ContinueDataType{}
+
+	// ERROR_INTERFACE is the predeclared error type. Unlike ERROR, values of this
+	// type are ordinary values and do not abort the program.
+	ERROR_INTERFACE = &ast.NamedDataType{
+		TypeName: "error",
+		Underlying: &ast.InterfaceDataType{
+			Methods: []*ast.InterfaceMethod{
+				{Name: "Error", Type: &ast.FunctionDataType{ReturnType: STRING}},
+			},
+		},
+	}
+
+	// COMPARABLE is the predeclared constraint satisfied by types supporting == and !=.
+	COMPARABLE = &ast.NamedDataType{
+		TypeName:   "comparable",
+		Underlying: &ast.InterfaceDataType{Comparable: true},
+	}
 )
 
 var precedences = map[token.TokenType]int{
@@ -41,13 +62,44 @@ var precedences = map[token.TokenType]int{
 	token.NEQ:      EQUALS,
 	token.LT:       LESSGREATER,
 	token.GT:       LESSGREATER,
+	token.LTEQ:     LESSGREATER,
+	token.GTEQ:     LESSGREATER,
 	token.PLUS:     SUM,
 	token.MINUS:    SUM,
-	token.PERCENT:  SUM,
+	token.PIPE:     SUM,
+	token.XOR:      SUM,
+	token.PERCENT:  PRODUCT,
 	token.SLASH:    PRODUCT,
 	token.ASTERISK: PRODUCT,
+	token.REF:      PRODUCT,
+	token.ANDNOT:   PRODUCT,
+	token.SHL:      PRODUCT,
+	token.SHR:      PRODUCT,
 	token.LPAREN:   CALL,
 	token.LBRACKET: INDEX,
+	token.DOT:      INDEX,
+}
+
+// compoundAssignOperators maps the assignment operators op= to the operator op.
+var compoundAssignOperators = map[token.TokenType]token.TokenType{
+	token.PLUSASSIGN:   token.PLUS,
+	token.MINUSASSIGN:  token.MINUS,
+	token.MULASSIGN:    token.ASTERISK,
+	token.DIVASSIGN:    token.SLASH,
+	token.MODASSIGN:    token.PERCENT,
+	token.ANDASSIGN:    token.REF,
+	token.ORASSIGN:     token.PIPE,
+	token.XORASSIGN:    token.XOR,
+	token.ANDNOTASSIGN: token.ANDNOT,
+	token.SHLASSIGN:    token.SHL,
+	token.SHRASSIGN:    token.SHR,
+}
+
+// branchTarget is a statement that break, and for loops also continue, may leave.
+// Unlabeled statements have an empty label.
+type branchTarget struct {
+	label string
+	loop  bool
 }
 
 type Parser struct {
@@ -58,6 +110,16 @@ type Parser struct {
 	curToken  token.Token
 	peekToken token.Token
 
+	// noCompositeLiteral is set while parsing if and for headers,
+	// where '{' after an identifier opens the statement body.
+	noCompositeLiteral bool
+
+	// branchTargets holds the loops and switches enclosing the current statement,
+	// innermost last.
+	branchTargets []branchTarget
+	// nextLabel is the label of the statement being parsed.
+	nextLabel string
+
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
@@ -71,9 +133,13 @@ func New(l *lexer.Lexer) *Parser {
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.CHAR, p.parseRuneLiteral)
+	p.registerPrefix(token.FSTRING, p.parseInterpolatedString)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.FOPER, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.XOR, p.parsePrefixExpression)
 	p.registerPrefix(token.FALSE, p.parseBooleanLiteral)
 	p.registerPrefix(token.TRUE, p.parseBooleanLiteral)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
@@ -86,6 +152,10 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.CHANOPERATOR, p.parseChanOperator)
 	p.registerPrefix(token.DTYPE, p.parseDataType)
 	p.registerPrefix(token.CHAN, p.parseDataType)
+	p.registerPrefix(token.LBRACKET, p.parseSliceLiteral)
+	p.registerPrefix(token.MAP, p.parseMapLiteral)
+	p.registerPrefix(token.STRUCT, p.parseStructLiteral)
+	p.registerPrefix(token.NIL, p.parseNilLiteral)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.OR, p.parseInfixExpression)
@@ -100,7 +170,16 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.GT, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.LTEQ, p.parseInfixExpression)
+	p.registerInfix(token.GTEQ, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.XOR, p.parseInfixExpression)
+	p.registerInfix(token.REF, p.parseInfixExpression)
+	p.registerInfix(token.ANDNOT, p.parseInfixExpression)
+	p.registerInfix(token.SHL, p.parseInfixExpression)
+	p.registerInfix(token.SHR, p.parseInfixExpression)
 	p.registerInfix(token.LBRACKET, p.parseSliceExpression)
+	p.registerInfix(token.DOT, p.parseSelectorExpression)
 
 	p.nextToken()
 	p.nextToken()
@@ -108,12 +187,16 @@ func New(l *lexer.Lexer) *Parser {
 	return p
 }
 
-func (p *Parser) parseCallArguments() []ast.Expression {
+// parseCallArguments parses the arguments of a call and reports whether the last
+// one is spread with '...'.
+func (p *Parser) parseCallArguments() ([]ast.Expression, bool) {
+	defer p.allowCompositeLiterals(true)()
+
 	var args []ast.Expression
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return args
+		return args, false
 	}
 
 	p.nextToken()
@@ -125,19 +208,28 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 		args = append(args, p.parseExpression(LOWEST))
 	}
 
+	ellipsis := false
+	if p.peekTokenIs(token.ELLIPSIS) {
+		p.nextToken()
+		ellipsis = true
+	}
+
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return nil, false
 	}
 
-	return args
+	return args, ellipsis
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+// parseFunctionParameters parses a parameter list and reports whether its final
+// parameter is variadic. A variadic parameter ...T is a []T inside the function.
+func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, bool) {
 	var idents []*ast.Identifier
+	variadic := false
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return idents
+		return idents, false
 	}
 
 	p.nextToken()
@@ -152,15 +244,20 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 
 	if !p.peekTokenIs(token.COMMA) {
 		p.nextToken()
-		*dataType = p.parseDataTypeLiteral()
+		*dataType, variadic = p.parseParameterDataType()
 		dataType = new(ast.DataType)
 	}
 
 	idents = append(idents, ident)
 	for p.peekTokenIs(token.COMMA) {
+		if variadic {
+			p.errors = append(p.errors, "can only use ... with final parameter in list")
+			variadic = false
+		}
+
 		p.nextToken()
 		if !p.expectPeek(token.IDENT) {
-			return nil
+			return nil, false
 		}
 
 		ident := &ast.Identifier{
@@ -169,9 +266,9 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 			DataType: dataType,
 		}
 
-		if p.peekTokenIs(token.DTYPE) {
+		if !p.peekTokenIs(token.COMMA) && !p.peekTokenIs(token.RPAREN) {
 			p.nextToken()
-			*dataType = p.parseDataTypeLiteral()
+			*dataType, variadic = p.parseParameterDataType()
 			dataType = new(ast.DataType)
 		}
 
@@ -179,23 +276,45 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	}
 
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return nil, false
 	}
 
 	if idents[len(idents)-1].DataType == nil {
 		msg := fmt.Sprintf("expected parameter type for %s", idents[len(idents)-1].Value)
 		p.errors = append(p.errors, msg)
-		return nil
+		return nil, false
+	}
+
+	// In a, b ...T the names share the variadic type.
+	if variadic && len(idents) > 1 && idents[len(idents)-2].DataType == idents[len(idents)-1].DataType {
+		p.errors = append(p.errors, "can only use ... with final parameter in list")
+		return nil, false
+	}
+
+	return idents, variadic
+}
+
+// parseParameterDataType parses the type of a parameter and reports whether it is
+// variadic.
+func (p *Parser) parseParameterDataType() (ast.DataType, bool) {
+	if !p.curTokenIs(token.ELLIPSIS) {
+		return p.parseDataTypeLiteral(), false
+	}
+
+	p.nextToken()
+	elemType := p.parseDataTypeLiteral()
+	if elemType == nil {
+		return nil, true
 	}
 
-	return idents
+	return &ast.SliceDataType{Type: elemType}, true
 }
 
 func (p *Parser) parseIfStatement() ast.Statement {
 	expression := &ast.IfStatement{Token: p.curToken}
 
 	p.nextToken()
-	expression.Condition = p.parseExpression(LOWEST)
+	expression.Condition = p.parseControlClauseExpression()
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -206,6 +325,20 @@ func (p *Parser) parseIfStatement() ast.Statement {
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
 
+		// else if is parsed as an else block holding the nested if statement
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			block := &ast.BlockStatement{Token: p.curToken}
+			nested := p.parseIfStatement()
+			if nested == nil {
+				return nil
+			}
+
+			block.Statements = []ast.Statement{nested}
+			expression.Alternative = block
+			return expression
+		}
+
 		if !p.expectPeek(token.LBRACE) {
 			return nil
 		}
@@ -217,6 +350,8 @@ func (p *Parser) parseIfStatement() ast.Statement {
 }
 
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	defer p.allowCompositeLiterals(true)()
+
 	block := &ast.BlockStatement{
 		Token: p.curToken,
 	}
@@ -237,15 +372,49 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
+	if p.peekTokenIs(token.LBRACE) && !p.noCompositeLiteral {
+		return p.parseStructLiteral()
+	}
+
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// parseControlClauseExpression parses the header of if and for statements.
+func (p *Parser) parseControlClauseExpression() ast.Expression {
+	defer p.allowCompositeLiterals(false)()
+
+	return p.parseExpression(LOWEST)
+}
+
+// allowCompositeLiterals changes whether '{' may start a composite literal
+// and returns a function restoring the previous state.
+func (p *Parser) allowCompositeLiterals(allowed bool) func() {
+	prev := p.noCompositeLiteral
+	p.noCompositeLiteral = !allowed
+
+	return func() {
+		p.noCompositeLiteral = prev
+	}
+}
+
 func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 
+	// The package clause comes first, followed by the imports.
+	imports := true
 	for p.curToken.Type != token.EOF {
-		stmt := p.parseStatement()
+		var stmt ast.Statement
+		switch {
+		case p.curTokenIs(token.PACKAGE) && len(program.Statements) == 0:
+			stmt = p.parsePackageStatement()
+		case p.curTokenIs(token.IMPORT) && imports:
+			stmt = p.parseImportStatement()
+		default:
+			stmt = p.parseStatement()
+			imports = imports && stmt == nil
+		}
+
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
@@ -256,6 +425,72 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
+func (p *Parser) parsePackageStatement() ast.Statement {
+	stmt := &ast.PackageStatement{Token: p.curToken}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	return stmt
+}
+
+func (p *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	if !p.peekTokenIs(token.LPAREN) {
+		p.nextToken()
+		spec := p.parseImportSpec()
+		if spec == nil {
+			return nil
+		}
+
+		stmt.Specs = append(stmt.Specs, spec)
+		return stmt
+	}
+
+	p.nextToken()
+	p.nextToken()
+	p.skipNewLines()
+
+	for !p.curTokenIs(token.RPAREN) {
+		spec := p.parseImportSpec()
+		if spec == nil {
+			for !p.curTokenIs(token.RPAREN) && !p.curTokenIs(token.EOF) {
+				p.nextToken()
+			}
+
+			return nil
+		}
+
+		stmt.Specs = append(stmt.Specs, spec)
+
+		p.nextToken()
+		p.skipNewLines()
+	}
+
+	return stmt
+}
+
+// parseImportSpec parses the path of an imported package, optionally preceded by
+// the name the package is imported as.
+func (p *Parser) parseImportSpec() *ast.ImportSpec {
+	spec := &ast.ImportSpec{}
+	if p.curTokenIs(token.IDENT) {
+		spec.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		p.nextToken()
+	}
+
+	if !p.curTokenIs(token.STRING) {
+		msg := fmt.Sprintf("expected import path, got %s instead", p.curToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	spec.Path = p.curToken.Literal
+	return spec
+}
+
 func (p *Parser) parseVarStatement() *ast.VarStatement {
 	stmt := &ast.VarStatement{
 		Token: p.curToken,
@@ -289,16 +524,103 @@ func (p *Parser) parseVarStatement() *ast.VarStatement {
 	return stmt
 }
 
+// parseConstStatement parses a single constant declaration or a group of them in
+// parentheses. In a group, a spec without a value repeats the type and value of the
+// previous spec, with iota counting the specs.
+func (p *Parser) parseConstStatement() ast.Statement {
+	stmt := &ast.ConstStatement{Token: p.curToken}
+
+	if !p.peekTokenIs(token.LPAREN) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		spec := p.parseConstSpec(nil, 0)
+		if spec == nil {
+			return nil
+		}
+
+		stmt.Specs = append(stmt.Specs, spec)
+		return stmt
+	}
+
+	p.nextToken()
+	p.nextToken()
+	p.skipNewLines()
+
+	var prev *ast.ConstSpec
+	for !p.curTokenIs(token.RPAREN) {
+		if !p.curTokenIs(token.IDENT) {
+			msg := fmt.Sprintf("expected constant name, got %s instead", p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+
+		spec := p.parseConstSpec(prev, len(stmt.Specs))
+		if spec == nil {
+			for !p.curTokenIs(token.RPAREN) && !p.curTokenIs(token.EOF) {
+				p.nextToken()
+			}
+
+			return nil
+		}
+
+		stmt.Specs = append(stmt.Specs, spec)
+		prev = spec
+
+		p.nextToken()
+		p.skipNewLines()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseConstSpec(prev *ast.ConstSpec, index int) *ast.ConstSpec {
+	spec := &ast.ConstSpec{
+		Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+		Iota: int64(index),
+	}
+
+	if !p.peekTokenIs(token.ASSIGN) && !p.peekTokenIs(token.NLINE) && !p.peekTokenIs(token.RPAREN) && !p.peekTokenIs(token.EOF) {
+		p.nextToken()
+
+		dType := p.parseDataTypeLiteral()
+		spec.Name.DataType = &dType
+	}
+
+	if p.peekTokenIs(token.ASSIGN) {
+		p.nextToken()
+		p.nextToken()
+		spec.Value = p.parseExpression(LOWEST)
+
+		return spec
+	}
+
+	if prev == nil || spec.Name.DataType != nil {
+		p.errors = append(p.errors, "missing init expr for const declaration")
+		return nil
+	}
+
+	spec.Name.DataType = prev.Name.DataType
+	spec.Value = prev.Value
+
+	return spec
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case token.HASH:
 		return p.parseCommentStatement()
 	case token.VAR:
 		return p.parseVarStatement()
+	case token.CONST:
+		return p.parseConstStatement()
 	case token.FOR:
 		return p.parseForStatement()
 	case token.GO:
 		return p.parseGoStatement()
+	case token.DEFER:
+		return p.parseDeferStatement()
 	case token.IF:
 		return p.parseIfStatement()
 	case token.NLINE:
@@ -307,11 +629,28 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseReturnStatement()
 	case token.BREAK:
 		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.TYPE:
+		return p.parseTypeStatement()
+	case token.SWITCH:
+		return p.parseSwitchStatement()
+	case token.SELECT:
+		return p.parseSelectStatement()
+	case token.FALLTHROUGH:
+		p.errors = append(p.errors, "fallthrough statement out of place")
+		return nil
+	case token.PACKAGE:
+		p.errors = append(p.errors, "package clause must be the first statement")
+		return nil
+	case token.IMPORT:
+		p.errors = append(p.errors, "imports must appear before other declarations")
+		return nil
 	case token.IDENT:
-		if p.peekTokenIs(token.INITASSIGN) {
+		if p.peekTokenIs(token.COLON) {
+			return p.parseLabeledStatement()
+		} else if p.peekTokenIs(token.INITASSIGN) {
 			return p.parseInitAssignStatement()
-		} else if p.peekTokenIs(token.ASSIGN) {
-			return p.parseAssignStatement()
 		} else if p.peekTokenIs(token.CHANOPERATOR) {
 			return p.parseSendChanOperator()
 		} else {
@@ -327,18 +666,156 @@ func (p *Parser) parseForStatement() ast.Statement {
 		Token: p.curToken,
 	}
 
+	defer p.enterBranchTarget(true)()
+
 	p.nextToken()
-	forStmt.Condition = p.parseExpression(LOWEST)
+	switch {
+	case p.curTokenIs(token.LBRACE):
+		forStmt.Consequence = p.parseBlockStatement()
+		return forStmt
+	case p.curTokenIs(token.RANGE):
+		return p.parseForRangeStatement(forStmt.Token, nil)
+	case p.curTokenIs(token.NLINE):
+		return p.parseForClauses(forStmt)
+	case p.curTokenIs(token.IDENT) && (p.peekTokenIs(token.COMMA) || p.peekTokenIs(token.INITASSIGN)):
+		names := p.parseIdentifierList()
+		if names == nil || !p.expectPeek(token.INITASSIGN) {
+			return nil
+		}
+
+		if p.peekTokenIs(token.RANGE) {
+			p.nextToken()
+			return p.parseForRangeStatement(forStmt.Token, names)
+		}
+
+		init := &ast.InitAssignStatement{Token: p.curToken, Names: names}
+		p.nextToken()
+		init.Value = p.parseTupleExpression()
+		forStmt.Init = init
+
+		return p.parseForClauses(forStmt)
+	case p.curTokenIs(token.IDENT) && p.peekTokenIs(token.ASSIGN):
+		forStmt.Init = p.parseAssignStatement(&ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		return p.parseForClauses(forStmt)
+	}
+
+	condition := p.parseControlClauseExpression()
+	if p.peekTokenIs(token.NLINE) {
+		forStmt.Init = &ast.ExpressionStatement{Token: forStmt.Token, Expression: condition}
+		return p.parseForClauses(forStmt)
+	}
+
+	forStmt.Condition = condition
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	forStmt.Consequence = p.parseBlockStatement()
+	return forStmt
+}
+
+// parseForClauses parses the condition, post statement and body of a three-clause
+// for statement. Current token must be the last token of the init statement or the
+// ';' after an empty one.
+func (p *Parser) parseForClauses(forStmt *ast.ForStatement) ast.Statement {
+	if !p.curTokenIs(token.NLINE) && !p.expectPeek(token.NLINE) {
+		return nil
+	}
+
+	if !p.peekTokenIs(token.NLINE) {
+		p.nextToken()
+		forStmt.Condition = p.parseControlClauseExpression()
+	}
+
+	if !p.expectPeek(token.NLINE) {
+		return nil
+	}
+
+	if !p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+
+		restore := p.allowCompositeLiterals(false)
+		forStmt.Post = p.parseStatement()
+		restore()
+
+		if forStmt.Post == nil {
+			return nil
+		}
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
 
 	forStmt.Consequence = p.parseBlockStatement()
-	p.nextToken()
 	return forStmt
 }
 
+// parseIdentifierList parses a comma separated list of identifiers. Current token
+// must be the first identifier.
+func (p *Parser) parseIdentifierList() []*ast.Identifier {
+	names := []*ast.Identifier{{Token: p.curToken, Value: p.curToken.Literal}}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		names = append(names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	}
+
+	return names
+}
+
+func (p *Parser) parseForRangeStatement(tok token.Token, names []*ast.Identifier) ast.Statement {
+	stmt := &ast.ForRangeStatement{Token: tok}
+
+	switch len(names) {
+	case 0:
+	case 1:
+		stmt.Key = names[0]
+	case 2:
+		stmt.Key, stmt.Value = names[0], names[1]
+	default:
+		p.errors = append(p.errors, "range clause permits at most two iteration variables")
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Iterable = p.parseControlClauseExpression()
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	return stmt
+}
+
+func (p *Parser) parseLabeledStatement() ast.Statement {
+	stmt := &ast.LabeledStatement{
+		Token: p.curToken,
+		Label: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+	}
+
+	p.nextToken()
+	p.nextToken()
+	p.skipNewLines()
+
+	if p.curTokenIs(token.FOR) || p.curTokenIs(token.SWITCH) || p.curTokenIs(token.SELECT) {
+		p.nextLabel = stmt.Label.Value
+	}
+
+	stmt.Statement = p.parseStatement()
+
+	if stmt.Statement == nil {
+		return nil
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseCommentStatement() ast.Statement {
 	if p.curTokenIs(token.HASH) {
 		for !p.curTokenIs(token.NLINE) && !p.curTokenIs(token.EOF) {
@@ -350,31 +827,57 @@ func (p *Parser) parseCommentStatement() ast.Statement {
 	return nil
 }
 
-func (p *Parser) parseInitAssignStatement() *ast.InitAssignStatement {
-	if !p.peekTokenIs(token.INITASSIGN) {
-		p.peekError(token.INITASSIGN)
+func (p *Parser) parseInitAssignStatement() ast.Statement {
+	names := p.parseIdentifierList()
+	if names == nil {
 		return nil
 	}
 
-	stmt := &ast.InitAssignStatement{
-		Token: p.peekToken,
+	if !p.expectPeek(token.INITASSIGN) {
+		return nil
 	}
 
-	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	stmt := &ast.InitAssignStatement{Token: p.curToken, Names: names}
 
 	p.nextToken()
-	p.nextToken()
-	stmt.Value = p.parseExpression(LOWEST)
+	stmt.Value = p.parseTupleExpression()
 
 	return stmt
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+// parseTupleExpression parses a comma separated list of expressions. A list of one
+// expression is returned as the expression itself.
+func (p *Parser) parseTupleExpression() ast.Expression {
+	tuple := &ast.TupleExpression{Token: p.curToken}
+
+	tuple.Values = append(tuple.Values, p.parseExpression(LOWEST))
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		tuple.Values = append(tuple.Values, p.parseExpression(LOWEST))
+	}
+
+	if len(tuple.Values) == 1 {
+		return tuple.Values[0]
+	}
+
+	return tuple
+}
+
+func (p *Parser) parseExpressionStatement() ast.Statement {
 	//defer untrace(trace("parseExpressionStatement"))
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 
 	stmt.Expression = p.parseExpression(LOWEST)
 
+	if p.peekTokenIs(token.COMMA) || p.peekTokenIs(token.ASSIGN) {
+		return p.parseAssignStatement(stmt.Expression)
+	}
+
+	if _, ok := compoundAssignOperators[p.peekToken.Type]; ok || p.peekTokenIs(token.INC) || p.peekTokenIs(token.DEC) {
+		return p.parseCompoundAssignStatement(stmt.Expression)
+	}
+
 	if p.peekTokenIs(token.NLINE) {
 		p.nextToken()
 	}
@@ -385,55 +888,214 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
+	if p.peekTokenIs(token.NLINE) || p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.EOF) {
+		return stmt
+	}
+
 	p.nextToken()
 
-	stmt.ReturnValue = p.parseExpression(LOWEST)
+	stmt.ReturnValue = p.parseTupleExpression()
 
 	return stmt
 }
 
-func (p *Parser) parseAssignStatement() *ast.AssignStatement {
-	if !p.peekTokenIs(token.ASSIGN) {
-		p.peekError(token.ASSIGN)
-		return nil
+// parseAssignStatement parses the comma separated targets starting with first, followed
+// by '=' or ':=' and the assigned values. Only identifiers may be declared with ':='.
+func (p *Parser) parseAssignStatement(first ast.Expression) ast.Statement {
+	targets := []ast.Expression{first}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		targets = append(targets, p.parseExpression(LOWEST))
 	}
 
-	stmt := &ast.AssignStatement{Token: p.peekToken}
+	if p.peekTokenIs(token.INITASSIGN) {
+		p.nextToken()
+		stmt := &ast.InitAssignStatement{Token: p.curToken}
+
+		valid := true
+		for _, target := range targets {
+			name, ok := target.(*ast.Identifier)
+			if !ok {
+				msg := fmt.Sprintf("non-name %s on left side of :=", target.String())
+				p.errors = append(p.errors, msg)
+				valid = false
+				continue
+			}
+
+			stmt.Names = append(stmt.Names, name)
+		}
 
-	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		p.nextToken()
+		stmt.Value = p.parseTupleExpression()
 
-	p.nextToken()
-	p.nextToken()
-	stmt.Value = p.parseExpression(LOWEST)
+		if !valid {
+			return nil
+		}
 
-	return stmt
-}
+		return stmt
+	}
 
-func (p *Parser) parseFunctionDataType() ast.DataType {
-	dType := &ast.FunctionDataType{}
-	if !p.expectPeek(token.LPAREN) {
+	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
 
-	if !p.peekTokenIs(token.RPAREN) {
-		p.nextToken()
-		param := p.parseDataTypeLiteral()
-		if param == nil {
-			return nil
-		}
+	stmt := &ast.AssignStatement{Token: p.curToken, Targets: targets}
 
-		dType.Parameters = append(dType.Parameters, param)
-	}
+	p.nextToken()
+	stmt.Value = p.parseTupleExpression()
 
-	for p.peekTokenIs(token.COMMA) {
+	return stmt
+}
+
+// parseCompoundAssignStatement parses x op= y, x++ and x-- as the assignment
+// x = x op y, where x++ adds and x-- subtracts 1.
+func (p *Parser) parseCompoundAssignStatement(target ast.Expression) ast.Statement {
+	p.nextToken()
+	tok := p.curToken
+
+	value := &ast.InfixExpression{Left: target}
+	switch tok.Type {
+	case token.INC, token.DEC:
+		var operator token.TokenType = token.PLUS
+		if tok.Type == token.DEC {
+			operator = token.MINUS
+		}
+
+		value.Token = token.Token{Type: operator, Literal: string(operator)}
+		value.Right = &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1}
+	default:
+		operator := compoundAssignOperators[tok.Type]
+		value.Token = token.Token{Type: operator, Literal: string(operator)}
+
+		p.nextToken()
+		value.Right = p.parseExpression(LOWEST)
+	}
+
+	value.Operator = value.Token.Literal
+	assignTok := token.Token{Type: token.ASSIGN, Literal: tok.Literal}
+
+	return &ast.AssignStatement{Token: assignTok, Targets: []ast.Expression{target}, Value: value}
+}
+
+func (p *Parser) parseTypeStatement() *ast.TypeStatement {
+	stmt := &ast.TypeStatement{
+		Token: p.curToken,
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	alias := false
+	if p.peekTokenIs(token.ASSIGN) {
 		p.nextToken()
+		alias = true
+	}
+
+	p.nextToken()
+
+	// '[' opens the type parameters of a generic type unless it starts a slice type.
+	var typeParams []*ast.TypeParameterDataType
+	if !alias && p.curTokenIs(token.LBRACKET) && !p.peekTokenIs(token.RBRACKET) {
+		typeParams = p.parseTypeParameters()
+		if typeParams == nil {
+			return nil
+		}
+
 		p.nextToken()
-		param := p.parseDataTypeLiteral()
+	}
+
+	underlying := p.parseDataTypeLiteral()
+	if underlying == nil {
+		return nil
+	}
+
+	stmt.Type = &ast.NamedDataType{TypeName: stmt.Name.Value, Underlying: underlying, Alias: alias, TypeParams: typeParams}
+
+	return stmt
+}
+
+func (p *Parser) parseStructDataType() ast.DataType {
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	structDataType := &ast.StructDataType{}
+
+	p.nextToken()
+	p.skipNewLines()
+	for !p.curTokenIs(token.RBRACE) {
+		if !p.curTokenIs(token.IDENT) {
+			msg := fmt.Sprintf("expected field name, got %s instead", p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+
+		names := []string{p.curToken.Literal}
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+
+			names = append(names, p.curToken.Literal)
+		}
+
+		p.nextToken()
+		fieldType := p.parseDataTypeLiteral()
+		if fieldType == nil {
+			return nil
+		}
+
+		for _, name := range names {
+			structDataType.Fields = append(structDataType.Fields, &ast.StructField{Name: name, Type: fieldType})
+		}
+
+		if !p.peekTokenIs(token.NLINE) && !p.peekTokenIs(token.RBRACE) {
+			p.peekError(token.NLINE)
+			return nil
+		}
+
+		p.nextToken()
+		p.skipNewLines()
+	}
+
+	return structDataType
+}
+
+func (p *Parser) parseFunctionDataType() ast.DataType {
+	dType := &ast.FunctionDataType{ReturnType: NIL}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+
+		// Parameter names are optional in function types.
+		if p.curTokenIs(token.IDENT) && !p.peekTokenIs(token.COMMA) && !p.peekTokenIs(token.RPAREN) {
+			p.nextToken()
+		}
+
+		if dType.Variadic {
+			p.errors = append(p.errors, "can only use ... with final parameter in list")
+			return nil
+		}
+
+		param, variadic := p.parseParameterDataType()
 		if param == nil {
 			return nil
 		}
 
 		dType.Parameters = append(dType.Parameters, param)
+		dType.Variadic = variadic
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+
 		p.nextToken()
 	}
 
@@ -441,19 +1103,399 @@ func (p *Parser) parseFunctionDataType() ast.DataType {
 		return nil
 	}
 
-	if !p.peekTokenIs(token.LBRACE) {
+	if p.peekTokenIsDataType() || p.peekTokenIs(token.LPAREN) {
 		p.nextToken()
-		returnType := p.parseDataTypeLiteral()
-		if returnType == nil {
-			dType.ReturnType = NIL
-		} else {
-			dType.ReturnType = returnType
+		dType.ReturnType = p.parseReturnDataType()
+		if dType.ReturnType == nil {
+			return nil
 		}
 	}
 
 	return dType
 }
 
+// parseReturnDataType parses the result type of a function. Multiple results are
+// enclosed in parentheses and form a tuple.
+func (p *Parser) parseReturnDataType() ast.DataType {
+	if !p.curTokenIs(token.LPAREN) {
+		return p.parseDataTypeLiteral()
+	}
+
+	tupleDataType := &ast.TupleDataType{}
+	for !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		dType := p.parseDataTypeLiteral()
+		if dType == nil {
+			return nil
+		}
+
+		tupleDataType.Types = append(tupleDataType.Types, dType)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	switch len(tupleDataType.Types) {
+	case 0:
+		return NIL
+	case 1:
+		return tupleDataType.Types[0]
+	default:
+		return tupleDataType
+	}
+}
+
+func (p *Parser) parseInterfaceDataType() ast.DataType {
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	interfaceDataType := &ast.InterfaceDataType{}
+
+	p.nextToken()
+	p.skipNewLines()
+	for !p.curTokenIs(token.RBRACE) {
+		// Lines other than methods embed interfaces or restrict the type set of
+		// constraint interfaces.
+		if !p.curTokenIs(token.IDENT) || !p.peekTokenIs(token.LPAREN) {
+			terms := p.parseTypeTerms()
+			if terms == nil {
+				return nil
+			}
+
+			_, isNamed := terms[0].Type.(*ast.NamedDataType)
+			switch {
+			case len(terms) == 1 && terms[0].Type == COMPARABLE:
+				interfaceDataType.Comparable = true
+			case len(terms) == 1 && !terms[0].Tilde && isNamed:
+				// The analyzer decides whether a named type is an embedded interface.
+				interfaceDataType.Embeds = append(interfaceDataType.Embeds, terms[0].Type)
+			default:
+				interfaceDataType.Union = append(interfaceDataType.Union, terms...)
+			}
+		} else {
+			method := &ast.InterfaceMethod{Name: p.curToken.Literal}
+			fnType, ok := p.parseFunctionDataType().(*ast.FunctionDataType)
+			if !ok {
+				return nil
+			}
+
+			method.Type = fnType
+			interfaceDataType.Methods = append(interfaceDataType.Methods, method)
+		}
+
+		if !p.peekTokenIs(token.NLINE) && !p.peekTokenIs(token.RBRACE) {
+			p.peekError(token.NLINE)
+			return nil
+		}
+
+		p.nextToken()
+		p.skipNewLines()
+	}
+
+	return interfaceDataType
+}
+
+func (p *Parser) parseSwitchStatement() ast.Statement {
+	tok := p.curToken
+	defer p.enterBranchTarget(false)()
+
+	p.nextToken()
+	var init, header ast.Statement
+	if !p.curTokenIs(token.LBRACE) {
+		header = p.parseSwitchHeader()
+		if header == nil {
+			return nil
+		}
+	}
+
+	// The statement before a ';' is the init statement.
+	if header != nil && p.peekTokenIs(token.NLINE) {
+		init, header = header, nil
+		p.nextToken()
+		p.nextToken()
+
+		if !p.curTokenIs(token.LBRACE) {
+			header = p.parseSwitchHeader()
+			if header == nil {
+				return nil
+			}
+		}
+	}
+
+	switch header := header.(type) {
+	case nil:
+		return p.parseExpressionSwitchStatement(tok, init, nil)
+	case *ast.ExpressionStatement:
+		if assertion, ok := header.Expression.(*ast.TypeAssertionExpression); ok && assertion.Type == nil {
+			return p.parseTypeSwitchStatement(tok, init, nil, assertion.Left)
+		}
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		return p.parseExpressionSwitchStatement(tok, init, header.Expression)
+	case *ast.InitAssignStatement:
+		assertion, ok := header.Value.(*ast.TypeAssertionExpression)
+		if !ok || assertion.Type != nil || len(header.Names) != 1 {
+			p.errors = append(p.errors, "expected x.(type) in switch statement")
+			return nil
+		}
+
+		return p.parseTypeSwitchStatement(tok, init, header.Names[0], assertion.Left)
+	default:
+		p.errors = append(p.errors, fmt.Sprintf("cannot use %s as value in switch statement", header.String()))
+		return nil
+	}
+}
+
+// parseSwitchHeader parses a simple statement in the header of a switch statement:
+// the init statement, the tag or the guard of a type switch.
+func (p *Parser) parseSwitchHeader() ast.Statement {
+	defer p.allowCompositeLiterals(false)()
+
+	stmt := &ast.ExpressionStatement{Token: p.curToken}
+	stmt.Expression = p.parseExpression(LOWEST)
+	if stmt.Expression == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.COMMA) || p.peekTokenIs(token.ASSIGN) || p.peekTokenIs(token.INITASSIGN) {
+		return p.parseAssignStatement(stmt.Expression)
+	}
+
+	if _, ok := compoundAssignOperators[p.peekToken.Type]; ok || p.peekTokenIs(token.INC) || p.peekTokenIs(token.DEC) {
+		return p.parseCompoundAssignStatement(stmt.Expression)
+	}
+
+	return stmt
+}
+
+// parseExpressionSwitchStatement parses the clauses of an expression switch.
+// Current token must be '{'.
+func (p *Parser) parseExpressionSwitchStatement(tok token.Token, init ast.Statement, tag ast.Expression) ast.Statement {
+	stmt := &ast.SwitchStatement{Token: tok, Init: init, Tag: tag}
+
+	p.nextToken()
+	for {
+		p.skipNewLines()
+
+		switch p.curToken.Type {
+		case token.RBRACE:
+			if len(stmt.Cases) != 0 && stmt.Cases[len(stmt.Cases)-1].Fallthrough() {
+				p.errors = append(p.errors, "cannot fallthrough final case in switch")
+				return nil
+			}
+
+			return stmt
+		case token.CASE, token.DEFAULT:
+			clause := &ast.CaseClause{Token: p.curToken}
+			if p.curTokenIs(token.CASE) {
+				clause.Values = p.parseCaseValues()
+				if clause.Values == nil {
+					return nil
+				}
+			}
+
+			if !p.expectPeek(token.COLON) {
+				return nil
+			}
+
+			clause.Body = p.parseCaseBody()
+			stmt.Cases = append(stmt.Cases, clause)
+		default:
+			msg := fmt.Sprintf("expected case or default, got %s instead", p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+	}
+}
+
+func (p *Parser) parseSelectStatement() ast.Statement {
+	stmt := &ast.SelectStatement{Token: p.curToken}
+	defer p.enterBranchTarget(false)()
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	p.nextToken()
+	for {
+		p.skipNewLines()
+
+		switch p.curToken.Type {
+		case token.RBRACE:
+			return stmt
+		case token.CASE, token.DEFAULT:
+			clause := &ast.CommClause{Token: p.curToken}
+			if p.curTokenIs(token.CASE) {
+				p.nextToken()
+				clause.Comm = p.parseStatement()
+				if clause.Comm == nil {
+					return nil
+				}
+
+				if _, ok := clause.Comm.(*ast.SendChanStatement); !ok && clause.Receive() == nil {
+					msg := fmt.Sprintf("select case must be receive, send or assign recv, got %s", clause.Comm.String())
+					p.errors = append(p.errors, msg)
+				}
+			}
+
+			if !p.expectPeek(token.COLON) {
+				return nil
+			}
+
+			clause.Body = p.parseCaseBody()
+			for _, s := range clause.Body.Statements {
+				if _, ok := s.(*ast.FallthroughStatement); ok {
+					p.errors = append(p.errors, "fallthrough statement out of place")
+				}
+			}
+
+			stmt.Cases = append(stmt.Cases, clause)
+		default:
+			msg := fmt.Sprintf("expected case or default, got %s instead", p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+	}
+}
+
+func (p *Parser) parseCaseValues() []ast.Expression {
+	var values []ast.Expression
+
+	for {
+		p.nextToken()
+
+		value := p.parseExpression(LOWEST)
+		if value == nil {
+			return nil
+		}
+
+		values = append(values, value)
+		if !p.peekTokenIs(token.COMMA) {
+			return values
+		}
+
+		p.nextToken()
+	}
+}
+
+// parseTypeSwitchStatement parses the clauses of a type switch on subject.
+// Current token must be the end of the x.(type) guard.
+func (p *Parser) parseTypeSwitchStatement(tok token.Token, init ast.Statement, binding *ast.Identifier, subject ast.Expression) ast.Statement {
+	stmt := &ast.TypeSwitchStatement{Token: tok, Init: init, Binding: binding, Subject: subject}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	p.nextToken()
+	for {
+		p.skipNewLines()
+
+		switch p.curToken.Type {
+		case token.RBRACE:
+			return stmt
+		case token.CASE, token.DEFAULT:
+			clause := &ast.TypeCaseClause{Token: p.curToken}
+			if p.curTokenIs(token.CASE) {
+				clause.Types = p.parseCaseTypes()
+				if clause.Types == nil {
+					return nil
+				}
+			}
+
+			if !p.expectPeek(token.COLON) {
+				return nil
+			}
+
+			clause.Body = p.parseCaseBody()
+			for _, s := range clause.Body.Statements {
+				if _, ok := s.(*ast.FallthroughStatement); ok {
+					p.errors = append(p.errors, "cannot fallthrough in type switch")
+				}
+			}
+
+			stmt.Cases = append(stmt.Cases, clause)
+		default:
+			msg := fmt.Sprintf("expected case or default, got %s instead", p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+	}
+}
+
+func (p *Parser) parseCaseTypes() []ast.DataType {
+	var types []ast.DataType
+
+	for {
+		p.nextToken()
+
+		var dType ast.DataType
+		if p.curTokenIs(token.NIL) {
+			dType = NIL
+		} else {
+			dType = p.parseDataTypeLiteral()
+		}
+
+		if dType == nil {
+			return nil
+		}
+
+		types = append(types, dType)
+		if !p.peekTokenIs(token.COMMA) {
+			return types
+		}
+
+		p.nextToken()
+	}
+}
+
+// parseCaseBody parses statements of a case clause up to the next case, default or
+// the end of the switch statement. Current token must be ':'. A fallthrough
+// statement is only accepted as the last statement of the clause.
+func (p *Parser) parseCaseBody() *ast.BlockStatement {
+	defer p.allowCompositeLiterals(true)()
+
+	block := &ast.BlockStatement{
+		Token: p.curToken,
+	}
+
+	block.Statements = []ast.Statement{}
+
+	p.nextToken()
+	for !p.curTokenIs(token.CASE) && !p.curTokenIs(token.DEFAULT) && !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		var stmt ast.Statement
+		if p.curTokenIs(token.FALLTHROUGH) {
+			stmt = &ast.FallthroughStatement{Token: p.curToken}
+		} else {
+			stmt = p.parseStatement()
+		}
+
+		if stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
+
+		p.nextToken()
+	}
+
+	for i, stmt := range block.Statements {
+		if _, ok := stmt.(*ast.FallthroughStatement); ok && i != len(block.Statements)-1 {
+			p.errors = append(p.errors, "fallthrough statement out of place")
+		}
+	}
+
+	return block
+}
+
 func (p *Parser) parseSliceDataType() ast.DataType {
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
@@ -469,6 +1511,31 @@ func (p *Parser) parseSliceDataType() ast.DataType {
 	return arrayDataType
 }
 
+func (p *Parser) parseMapDataType() ast.DataType {
+	if !p.expectPeek(token.LBRACKET) {
+		return nil
+	}
+
+	p.nextToken()
+	mapDataType := &ast.MapDataType{}
+	mapDataType.KeyType = p.parseDataTypeLiteral()
+	if mapDataType.KeyType == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	p.nextToken()
+	mapDataType.ValueType = p.parseDataTypeLiteral()
+	if mapDataType.ValueType == nil {
+		return nil
+	}
+
+	return mapDataType
+}
+
 func (p *Parser) parseBashVarExpression() ast.Expression {
 	expr := &ast.BashVarExpression{
 		Token: p.curToken,
@@ -486,7 +1553,34 @@ func (p *Parser) parseGoStatement() ast.Statement {
 	p.nextToken()
 	stmt.Expr = p.parseExpression(LOWEST)
 
-	return stmt
+	switch stmt.Expr.(type) {
+	case nil:
+		return nil
+	case *ast.CallExpression, *ast.BashExpression:
+		return stmt
+	default:
+		p.errors = append(p.errors, "expression in go must be function call")
+		return nil
+	}
+}
+
+func (p *Parser) parseDeferStatement() ast.Statement {
+	stmt := &ast.DeferStatement{
+		Token: p.curToken,
+	}
+
+	p.nextToken()
+	stmt.Call = p.parseExpression(LOWEST)
+
+	switch stmt.Call.(type) {
+	case nil:
+		return nil
+	case *ast.CallExpression, *ast.BashExpression:
+		return stmt
+	default:
+		p.errors = append(p.errors, "expression in defer must be function call")
+		return nil
+	}
 }
 
 func (p *Parser) parseChanOperator() ast.Expression {
@@ -494,19 +1588,19 @@ func (p *Parser) parseChanOperator() ast.Expression {
 		Token: p.curToken,
 	}
 
-	if !p.expectPeek(token.IDENT) {
+	p.nextToken()
+	expr.Source = p.parseExpression(PREFIX)
+	if expr.Source == nil {
 		return nil
 	}
 
-	expr.Source = p.parseIdentifier().(*ast.Identifier)
-
 	return expr
 }
 
 func (p *Parser) parseSendChanOperator() ast.Statement {
 	stmt := &ast.SendChanStatement{
 		Token:       p.peekToken,
-		Destination: p.parseIdentifier().(*ast.Identifier),
+		Destination: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
 	}
 
 	p.nextToken()
@@ -530,9 +1624,78 @@ func (p *Parser) parseBreakStatement() ast.Statement {
 		Token: p.curToken,
 	}
 
-	if !p.expectPeek(token.NLINE) {
+	stmt.Label = p.parseBranchLabel()
+	if !p.checkBranchTarget(stmt.Token.Literal, stmt.Label, false) {
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() ast.Statement {
+	stmt := &ast.ContinueStatement{
+		Token: p.curToken,
+	}
+
+	stmt.Label = p.parseBranchLabel()
+	if !p.checkBranchTarget(stmt.Token.Literal, stmt.Label, true) {
 		return nil
 	}
 
 	return stmt
 }
+
+func (p *Parser) parseBranchLabel() *ast.Identifier {
+	if !p.peekTokenIs(token.IDENT) {
+		return nil
+	}
+
+	p.nextToken()
+	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+}
+
+// enterBranchTarget registers the loop or switch being parsed, labeled with
+// nextLabel, and returns a function unregistering it.
+func (p *Parser) enterBranchTarget(loop bool) func() {
+	p.branchTargets = append(p.branchTargets, branchTarget{label: p.nextLabel, loop: loop})
+	p.nextLabel = ""
+
+	return func() {
+		p.branchTargets = p.branchTargets[:len(p.branchTargets)-1]
+	}
+}
+
+// checkBranchTarget reports whether a break or continue statement with the given
+// label has an enclosing statement to branch to. Continue may only leave loops.
+func (p *Parser) checkBranchTarget(keyword string, label *ast.Identifier, loopOnly bool) bool {
+	var targets []branchTarget
+	for _, target := range p.branchTargets {
+		if target.loop || !loopOnly {
+			targets = append(targets, target)
+		}
+	}
+
+	if len(targets) == 0 {
+		msg := fmt.Sprintf("%s is not in a loop", keyword)
+		if !loopOnly {
+			msg += ", switch, or select"
+		}
+
+		p.errors = append(p.errors, msg)
+		return false
+	}
+
+	if label == nil {
+		return true
+	}
+
+	for _, target := range targets {
+		if target.label == label.Value {
+			return true
+		}
+	}
+
+	msg := fmt.Sprintf("invalid %s label %s", keyword, label.Value)
+	p.errors = append(p.errors, msg)
+	return false
+}
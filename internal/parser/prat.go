@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"strings"
 
 	"kstmc.com/gosha/internal/ast"
@@ -54,7 +55,7 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 		Function: function,
 	}
 
-	expression.Arguments = p.parseCallArguments()
+	expression.Arguments, expression.Ellipsis = p.parseCallArguments()
 	return expression
 }
 
@@ -92,6 +93,8 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	defer p.allowCompositeLiterals(true)()
+
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
@@ -103,17 +106,107 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 	return exp
 }
 
+// parseSliceExpression parses the index expression a[i], the slice expression
+// a[low:high] and the instantiation f[T, U] of a generic function. G[T]{...} is a
+// composite literal of an instance of the generic type G.
 func (p *Parser) parseSliceExpression(left ast.Expression) ast.Expression {
-	expr := &ast.IndexExpression{
+	compositeLiteral := !p.noCompositeLiteral
+	defer p.allowCompositeLiterals(true)()
+
+	tok := p.curToken
+	p.nextToken()
+
+	var index ast.Expression
+	if !p.curTokenIs(token.COLON) {
+		index = p.parseExpression(LOWEST)
+		if !p.peekTokenIs(token.COLON) {
+			var expr ast.Expression = &ast.IndexExpression{Token: tok, Left: left, Index: index}
+			if p.peekTokenIs(token.COMMA) {
+				instantiation := &ast.InstantiationExpression{Token: tok, Function: left, TypeArgs: []ast.Expression{index}}
+				for p.peekTokenIs(token.COMMA) {
+					p.nextToken()
+					p.nextToken()
+					instantiation.TypeArgs = append(instantiation.TypeArgs, p.parseExpression(LOWEST))
+				}
+
+				expr = instantiation
+			}
+
+			if !p.expectPeek(token.RBRACKET) {
+				return nil
+			}
+
+			if isTypeName(left) && compositeLiteral && p.peekTokenIs(token.LBRACE) {
+				dType := ExpressionDataType(expr)
+				if dType == nil {
+					p.errors = append(p.errors, fmt.Sprintf("%s is not a type", expr.String()))
+					return nil
+				}
+
+				p.nextToken()
+				return p.parseStructLiteralValues(tok, dType)
+			}
+
+			return expr
+		}
+
+		p.nextToken()
+	}
+
+	expr := &ast.SliceExpression{Token: tok, Left: left, Low: index}
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		expr.High = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return expr
+}
+
+func (p *Parser) parseSelectorExpression(left ast.Expression) ast.Expression {
+	if p.peekTokenIs(token.LPAREN) {
+		return p.parseTypeAssertionExpression(left)
+	}
+
+	expr := &ast.SelectorExpression{
 		Token: p.curToken,
 		Left:  left,
 	}
 
-	p.nextToken()
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
 
-	expr.Index = p.parseExpression(LOWEST)
+	expr.Field = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
-	if !p.expectPeek(token.RBRACKET) {
+	// deploy.Config{...} is a composite literal of a type declared in a package.
+	if pkg, ok := left.(*ast.Identifier); ok && ast.IsExported(expr.Field.Value) && p.peekTokenIs(token.LBRACE) && !p.noCompositeLiteral {
+		p.nextToken()
+		return p.parseStructLiteralValues(expr.Token, &ast.NamedDataType{TypeName: expr.Field.Value, Package: pkg.Value})
+	}
+
+	return expr
+}
+
+func (p *Parser) parseTypeAssertionExpression(left ast.Expression) ast.Expression {
+	expr := &ast.TypeAssertionExpression{
+		Token: p.curToken,
+		Left:  left,
+	}
+
+	p.nextToken()
+	p.nextToken()
+	if !p.curTokenIs(token.TYPE) {
+		expr.Type = p.parseDataTypeLiteral()
+		if expr.Type == nil {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
 
@@ -114,13 +114,24 @@ func testAssignStatement(t *testing.T, s ast.Statement, name string, assign stri
 		return false
 	}
 
-	if assignStmt.Name.Value != name {
-		t.Errorf("assignStmt.Name.Value not '%s'. got=%s", name, assignStmt.Name.Value)
+	if len(assignStmt.Targets) != 1 {
+		t.Errorf("assignStmt.Targets does not contain 1 target. got=%d", len(assignStmt.Targets))
 		return false
 	}
 
-	if assignStmt.Name.TokenLiteral() != name {
-		t.Errorf("s.Name not '%s'. got=%s", name, assignStmt.Name)
+	target, ok := assignStmt.Targets[0].(*ast.Identifier)
+	if !ok {
+		t.Errorf("assignStmt.Targets[0] not *ast.Identifier. got=%T", assignStmt.Targets[0])
+		return false
+	}
+
+	if target.Value != name {
+		t.Errorf("target.Value not '%s'. got=%s", name, target.Value)
+		return false
+	}
+
+	if target.TokenLiteral() != name {
+		t.Errorf("target not '%s'. got=%s", name, target)
 		return false
 	}
 
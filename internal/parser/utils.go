@@ -13,6 +13,17 @@ func (p *Parser) peekTokenIs(t token.TokenType) bool {
 	return p.peekToken.Type == t
 }
 
+// peekTokenIsDataType reports whether the next token may start a data type.
+func (p *Parser) peekTokenIsDataType() bool {
+	switch p.peekToken.Type {
+	case token.DTYPE, token.CHAN, token.ASTERISK, token.FUNCTION, token.LBRACKET,
+		token.MAP, token.STRUCT, token.INTERFACE, token.IDENT:
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *Parser) expectPeek(t token.TokenType) bool {
 	if p.peekTokenIs(t) {
 		p.nextToken()
@@ -34,15 +45,83 @@ func tokenTypeToDataType(raw string) ast.DataType {
 		return STRING
 	case "int":
 		return INT
+	case "float64":
+		return FLOAT64
+	case "byte":
+		return BYTE
+	case "rune":
+		return RUNE
 	case "bool":
 		return BOOLEAN
 	case "any":
 		return ANY
+	case "error":
+		return ERROR_INTERFACE
+	case "comparable":
+		return COMPARABLE
 	default:
 		return NIL
 	}
 }
 
+// ExpressionDataType returns the type an expression denotes if it is parsed in a
+// position where a type may be given, such as the type arguments in f[T], and nil if
+// it cannot be a type. Identifiers are not checked to name a type.
+func ExpressionDataType(expr ast.Expression) ast.DataType {
+	switch expr := expr.(type) {
+	case *ast.DataTypeExpression:
+		return expr.Type
+	case *ast.Identifier:
+		return &ast.NamedDataType{TypeName: expr.Value}
+	case *ast.SelectorExpression:
+		if pkg, ok := expr.Left.(*ast.Identifier); ok {
+			return &ast.NamedDataType{TypeName: expr.Field.Value, Package: pkg.Value}
+		}
+	case *ast.PrefixExpression:
+		if valueType := ExpressionDataType(expr.Right); expr.Operator == token.ASTERISK && valueType != nil {
+			return &ast.ReferenceDataType{ValueType: valueType}
+		}
+	case *ast.IndexExpression:
+		return genericDataType(expr.Left, []ast.Expression{expr.Index})
+	case *ast.InstantiationExpression:
+		return genericDataType(expr.Function, expr.TypeArgs)
+	}
+
+	return nil
+}
+
+// isTypeName reports whether expr may name a type: an identifier, or an identifier
+// qualified by a package name.
+func isTypeName(expr ast.Expression) bool {
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		return true
+	case *ast.SelectorExpression:
+		_, ok := expr.Left.(*ast.Identifier)
+		return ok
+	default:
+		return false
+	}
+}
+
+func genericDataType(generic ast.Expression, typeArgs []ast.Expression) ast.DataType {
+	named, ok := ExpressionDataType(generic).(*ast.NamedDataType)
+	if !ok || len(named.TypeArgs) != 0 {
+		return nil
+	}
+
+	for _, arg := range typeArgs {
+		dType := ExpressionDataType(arg)
+		if dType == nil {
+			return nil
+		}
+
+		named.TypeArgs = append(named.TypeArgs, dType)
+	}
+
+	return named
+}
+
 func (p *Parser) peekPrecedence() int {
 	if p, ok := precedences[p.peekToken.Type]; ok {
 		return p
@@ -58,3 +137,9 @@ func (p *Parser) curPrecedence() int {
 
 	return LOWEST
 }
+
+func (p *Parser) skipNewLines() {
+	for p.curTokenIs(token.NLINE) {
+		p.nextToken()
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 
 	"kstmc.com/gosha/internal/evaluator"
 	"kstmc.com/gosha/internal/lexer"
@@ -24,6 +25,10 @@ func Start(in io.Reader, out io.Writer) {
 	env := object.NewEnvironment()
 
 	if !(ok && file == os.Stdin) {
+		if ok {
+			env.SetDir(filepath.Dir(file.Name()))
+		}
+
 		data, err := io.ReadAll(in)
 		if err != nil {
 			fmt.Errorf("unable to read data from file: %s", err.Error())
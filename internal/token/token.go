@@ -17,29 +17,54 @@ const (
 
 	IDENT    = "IDENT"
 	INT      = "INT"
+	FLOAT    = "FLOAT"
 	BANG     = "!"
 	ASTERISK = "*"
 	SLASH    = "/"
 	HASH     = "#"
 
-	LT  = "<"
-	GT  = ">"
-	EQ  = "=="
-	NEQ = "!="
-	AND = "&&"
-	OR  = "||"
-
-	REF = "&"
-
-	STRING = `"`
+	LT   = "<"
+	GT   = ">"
+	LTEQ = "<="
+	GTEQ = ">="
+	EQ   = "=="
+	NEQ  = "!="
+	AND  = "&&"
+	OR   = "||"
+
+	REF    = "&"
+	XOR    = "^"
+	ANDNOT = "&^"
+	SHL    = "<<"
+	SHR    = ">>"
+	INC    = "++"
+	DEC    = "--"
+
+	STRING  = `"`
+	CHAR    = "CHAR"
+	FSTRING = "FSTRING"
 
 	ASSIGN       = "="
 	INITASSIGN   = ":="
+	PLUSASSIGN   = "+="
+	MINUSASSIGN  = "-="
+	MULASSIGN    = "*="
+	DIVASSIGN    = "/="
+	MODASSIGN    = "%="
+	ANDASSIGN    = "&="
+	ORASSIGN     = "|="
+	XORASSIGN    = "^="
+	ANDNOTASSIGN = "&^="
+	SHLASSIGN    = "<<="
+	SHRASSIGN    = ">>="
 	FOPER        = "-f"
 	PLUS         = "+"
 	MINUS        = "-"
 	PERCENT      = "%"
 	COMMA        = ","
+	COLON        = ":"
+	DOT          = "."
+	ELLIPSIS     = "..."
 	CHANOPERATOR = "<-"
 
 	LBRACKET = "["
@@ -51,6 +76,7 @@ const (
 	LBRACE = "{"
 	RBRACE = "}"
 	PIPE   = "|"
+	TILDE  = "~"
 
 	NLINE = "\n"
 
@@ -59,38 +85,75 @@ const (
 
 	// Keywords
 
-	FUNCTION = "FUNC"
-	VAR      = "VAR"
-	TRUE     = "TRUE"
-	FALSE    = "FALSE"
-	IF       = "IF"
-	ELSE     = "ELSE"
-	RETURN   = "RETURN"
-	DTYPE    = "DTYPE"
-	CALL     = "CALL"
-	BUILDIN  = "BUILDIN"
-	FOR      = "FOR"
-	GO       = "GO"
-	CHAN     = "CHAN"
-	BREAK    = "BREAK"
+	FUNCTION    = "FUNC"
+	VAR         = "VAR"
+	CONST       = "CONST"
+	TRUE        = "TRUE"
+	FALSE       = "FALSE"
+	IF          = "IF"
+	ELSE        = "ELSE"
+	RETURN      = "RETURN"
+	DTYPE       = "DTYPE"
+	CALL        = "CALL"
+	BUILDIN     = "BUILDIN"
+	FOR         = "FOR"
+	GO          = "GO"
+	CHAN        = "CHAN"
+	BREAK       = "BREAK"
+	MAP         = "MAP"
+	TYPE        = "TYPE"
+	STRUCT      = "STRUCT"
+	INTERFACE   = "INTERFACE"
+	SWITCH      = "SWITCH"
+	CASE        = "CASE"
+	DEFAULT     = "DEFAULT"
+	NIL         = "NIL"
+	RANGE       = "RANGE"
+	CONTINUE    = "CONTINUE"
+	FALLTHROUGH = "FALLTHROUGH"
+	SELECT      = "SELECT"
+	DEFER       = "DEFER"
+	IMPORT      = "IMPORT"
+	PACKAGE     = "PACKAGE"
 )
 
 var keywords = map[string]TokenType{
-	"go":     GO,
-	"func":   FUNCTION,
-	"var":    VAR,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
-	"string": DTYPE,
-	"int":    DTYPE,
-	"bool":   DTYPE,
-	"any":    DTYPE,
-	"for":    FOR,
-	"chan":   CHAN,
-	"break":  BREAK,
+	"go":          GO,
+	"func":        FUNCTION,
+	"var":         VAR,
+	"const":       CONST,
+	"true":        TRUE,
+	"false":       FALSE,
+	"if":          IF,
+	"else":        ELSE,
+	"return":      RETURN,
+	"string":      DTYPE,
+	"int":         DTYPE,
+	"float64":     DTYPE,
+	"byte":        DTYPE,
+	"rune":        DTYPE,
+	"bool":        DTYPE,
+	"any":         DTYPE,
+	"comparable":  DTYPE,
+	"error":       DTYPE,
+	"for":         FOR,
+	"chan":        CHAN,
+	"break":       BREAK,
+	"map":         MAP,
+	"type":        TYPE,
+	"struct":      STRUCT,
+	"interface":   INTERFACE,
+	"switch":      SWITCH,
+	"case":        CASE,
+	"default":     DEFAULT,
+	"nil":         NIL,
+	"range":       RANGE,
+	"continue":    CONTINUE,
+	"fallthrough": FALLTHROUGH,
+	"select":      SELECT,
+	"defer":       DEFER,
+	"import":      IMPORT,
+	"package":     PACKAGE,
 }
 
 func SetupBashCalls() error {